@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ===================================================================================
+// --- 访问日志回放 (Access-log Replay) ---
+// 解析nginx/Apache的common/combined格式访问日志，把录制的生产流量
+// (路径、方法、相对时间间隔) 重放到指定目标主机；
+// 场景结构和回放主循环复用HAR回放的实现 (har.go)
+// ===================================================================================
+
+var (
+	// 访问日志路径，非空启用回放模式
+	AccessLogFile = ""
+	// 回放的目标主机基础URL，如 https://staging.example.com
+	AccessLogTargetHost = ""
+	// 回放倍速
+	AccessLogSpeed = 1.0
+)
+
+// common/combined格式: IP - 用户 [时间] "方法 路径 协议" 状态 字节数 ...
+var accessLogPattern = regexp.MustCompile(`^(\S+) \S+ \S+ \[([^\]]+)\] "(\S+) (\S+)(?: [^"]*)?" \d+`)
+
+const accessLogTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// 解析访问日志为回放场景 (写入harEntries由共用的回放循环消费)
+func loadAccessLogScenario() error {
+	if AccessLogTargetHost == "" {
+		return fmt.Errorf("访问日志回放需要设置目标主机 (access_log_target_host)")
+	}
+	base := strings.TrimSuffix(AccessLogTargetHost, "/")
+	if !strings.HasPrefix(base, "http://") && !strings.HasPrefix(base, "https://") {
+		return fmt.Errorf("无效的目标主机 (需为 http(s)://...): %s", AccessLogTargetHost)
+	}
+
+	file, err := os.Open(AccessLogFile)
+	if err != nil {
+		return fmt.Errorf("无法打开访问日志: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	skipped := 0
+	seen := make(map[string]bool)
+	var prevStart time.Time
+	for scanner.Scan() {
+		m := accessLogPattern.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			skipped++
+			continue
+		}
+		method, path := m[3], m[4]
+		if !isHTTPMethod(method) || !strings.HasPrefix(path, "/") {
+			skipped++
+			continue
+		}
+
+		entry := harEntry{method: method, url: base + path}
+		if start, err := time.Parse(accessLogTimeLayout, m[2]); err == nil {
+			if !prevStart.IsZero() && start.After(prevStart) {
+				entry.gap = start.Sub(prevStart)
+				if entry.gap > harMaxGap {
+					entry.gap = harMaxGap
+				}
+			}
+			prevStart = start
+		}
+
+		harEntries = append(harEntries, entry)
+		if !seen[entry.url] {
+			seen[entry.url] = true
+			TargetURLs = append(TargetURLs, entry.url)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("读取访问日志失败: %v", err)
+	}
+	if len(harEntries) == 0 {
+		return fmt.Errorf("访问日志中没有解析出有效请求")
+	}
+
+	// 回放循环以HARSpeed为倍速，访问日志模式下由自己的配置接管
+	HARSpeed = AccessLogSpeed
+
+	if skipped > 0 {
+		log.Printf("警告: 跳过 %d 行无法解析的日志", skipped)
+	}
+	log.Printf("成功从 %s 加载 %d 条请求 (%d 个目标URL)", AccessLogFile, len(harEntries), len(TargetURLs))
+	return nil
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// ===================================================================================
+// --- 运行中告警规则 (In-Run Alerting) ---
+// 无人值守的长跑不能等到收尾的阈值评估才发现问题；
+// 按固定间隔评估错误率/P99/RPS下限规则，命中后执行动作:
+// log记一条告警、webhook推送JSON通知、abort优雅终止并产出部分结果报告
+// ===================================================================================
+
+// 评估间隔
+var AlertInterval = 5 * time.Second
+
+// 单条告警规则 (above/below至少一个；for为持续时长，0为命中即触发)
+type AlertRule struct {
+	Name       string
+	Metric     string // error_rate(%) / p99_ms / rps
+	Above      float64
+	HasAbove   bool
+	Below      float64
+	HasBelow   bool
+	For        time.Duration
+	Action     string // log / webhook / abort
+	WebhookURL string
+
+	breachedSince time.Time // 当前连续越界的起点，零值表示未越界
+	firing        bool      // 本轮越界是否已触发过动作 (恢复后重置)
+	fired         int64     // 累计触发次数
+}
+
+var AlertRules []*AlertRule
+
+var alertWebhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// 规则校验
+func validateAlertRules() error {
+	for i, r := range AlertRules {
+		switch r.Metric {
+		case "error_rate", "p99_ms", "rps":
+		default:
+			return fmt.Errorf("告警规则 %d (%s) 的metric无效: %s (可选 error_rate/p99_ms/rps)", i, r.Name, r.Metric)
+		}
+		if !r.HasAbove && !r.HasBelow {
+			return fmt.Errorf("告警规则 %d (%s) 缺少above或below阈值", i, r.Name)
+		}
+		switch r.Action {
+		case "log", "abort":
+		case "webhook":
+			if r.WebhookURL == "" {
+				return fmt.Errorf("告警规则 %d (%s) 的action为webhook但缺少webhook_url", i, r.Name)
+			}
+		default:
+			return fmt.Errorf("告警规则 %d (%s) 的action无效: %s (可选 log/webhook/abort)", i, r.Name, r.Action)
+		}
+	}
+	return nil
+}
+
+// 后台评估循环: 每个间隔用最近窗口的指标逐条核对规则
+func startAlertEvaluator(ctx context.Context, stats *Stats, abort context.CancelFunc) {
+	if len(AlertRules) == 0 {
+		return
+	}
+	fmt.Printf("告警规则已启用: %d 条，每 %v 评估一次\n", len(AlertRules), AlertInterval)
+
+	go func() {
+		ticker := time.NewTicker(AlertInterval)
+		defer ticker.Stop()
+
+		var prevReq, prevFailed int64
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			req := atomic.LoadInt64(&stats.TotalRequests)
+			failed := atomic.LoadInt64(&stats.FailedRequests)
+			deltaReq := req - prevReq
+			deltaFailed := failed - prevFailed
+			prevReq, prevFailed = req, failed
+
+			rps := float64(deltaReq) / AlertInterval.Seconds()
+			errorRate := 0.0
+			if deltaReq > 0 {
+				errorRate = float64(deltaFailed) / float64(deltaReq) * 100
+			}
+			_, _, p99, hasLatency := rollingLatency.Percentiles(RollingWindowSec)
+
+			for _, r := range AlertRules {
+				var value float64
+				switch r.Metric {
+				case "error_rate":
+					value = errorRate
+				case "rps":
+					value = rps
+				case "p99_ms":
+					if !hasLatency {
+						continue // 窗口内还没有样本，不评估
+					}
+					value = p99
+				}
+				evaluateAlertRule(r, value, abort)
+			}
+		}
+	}()
+}
+
+// 核对单条规则: 越界计时、满足持续时长后触发动作，恢复后重置
+func evaluateAlertRule(r *AlertRule, value float64, abort context.CancelFunc) {
+	breached := (r.HasAbove && value > r.Above) || (r.HasBelow && value < r.Below)
+	if !breached {
+		if r.firing {
+			log.Printf("告警恢复 [%s]: %s=%.2f 已回到阈值内", r.Name, r.Metric, value)
+		}
+		r.breachedSince = time.Time{}
+		r.firing = false
+		return
+	}
+
+	if r.breachedSince.IsZero() {
+		r.breachedSince = time.Now()
+	}
+	if time.Since(r.breachedSince) < r.For || r.firing {
+		return
+	}
+
+	r.firing = true
+	atomic.AddInt64(&r.fired, 1)
+	log.Printf("告警触发 [%s]: %s=%.2f 越界 (above=%v below=%v 持续%v)，动作=%s",
+		r.Name, r.Metric, value, r.Above, r.Below, r.For, r.Action)
+
+	switch r.Action {
+	case "webhook":
+		go postAlertWebhook(r, value)
+	case "abort":
+		log.Printf("告警动作abort: 正在停止测试并生成部分结果报告")
+		abort()
+	}
+}
+
+// 推送告警通知
+func postAlertWebhook(r *AlertRule, value float64) {
+	payload, _ := json.Marshal(map[string]interface{}{
+		"rule":   r.Name,
+		"metric": r.Metric,
+		"value":  value,
+		"above":  r.Above,
+		"below":  r.Below,
+		"time":   time.Now().Format(time.RFC3339),
+	})
+	resp, err := alertWebhookClient.Post(r.WebhookURL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		log.Printf("警告: 告警webhook推送失败 [%s]: %v", r.Name, err)
+		return
+	}
+	resp.Body.Close()
+}
+
+// 供JSON报告使用
+func alertAnalysis() []map[string]interface{} {
+	if len(AlertRules) == 0 {
+		return nil
+	}
+	var rows []map[string]interface{}
+	for _, r := range AlertRules {
+		rows = append(rows, map[string]interface{}{
+			"name":   r.Name,
+			"metric": r.Metric,
+			"action": r.Action,
+			"fired":  atomic.LoadInt64(&r.fired),
+		})
+	}
+	return rows
+}
+
+// 打印告警报告
+func printAlertReport() {
+	if len(AlertRules) == 0 {
+		return
+	}
+	fmt.Printf("\n=== 告警规则 ===\n")
+	for _, r := range AlertRules {
+		fmt.Printf("%s (%s, %s): 触发 %d 次\n", r.Name, r.Metric, r.Action, atomic.LoadInt64(&r.fired))
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// ===================================================================================
+// --- 目标允许名单强制检查 (Target Allowlist) ---
+// 高并发模式发错目标的代价极大 (1万协程打向错误域名)；
+// 除非目标主机在允许名单里或用户显式确认 --i-own-these-targets，否则拒绝启动
+// ===================================================================================
+
+var (
+	// 允许名单文件: 每行一个域名，支持 .example.com 形式匹配子域
+	AllowlistFile = "allowlist.txt"
+	// 用户显式确认对所有目标有压测授权，跳过名单检查
+	AckOwnTargets = false
+)
+
+// 超过该并发数视为高并发模式，强制执行名单检查
+const highConcurrencyThreshold = 1000
+
+// 无需授权即可压测的本地主机
+func isLocalHost(host string) bool {
+	return host == "localhost" || host == "127.0.0.1" || host == "::1" ||
+		strings.HasPrefix(host, "127.") || strings.HasSuffix(host, ".local")
+}
+
+// 读取允许名单文件，文件不存在时返回空名单
+func loadAllowlist() map[string]bool {
+	allowed := make(map[string]bool)
+	file, err := os.Open(AllowlistFile)
+	if err != nil {
+		return allowed
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		allowed[strings.ToLower(line)] = true
+	}
+	return allowed
+}
+
+// 主机是否命中名单 (精确匹配，或名单项以.开头时匹配子域)
+func hostAllowed(host string, allowed map[string]bool) bool {
+	host = strings.ToLower(host)
+	if allowed[host] {
+		return true
+	}
+	for entry := range allowed {
+		if strings.HasPrefix(entry, ".") && strings.HasSuffix(host, entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// 高并发模式下强制检查全部目标主机，未授权的直接拒绝启动
+func enforceTargetAllowlist() {
+	if AckOwnTargets {
+		log.Printf("已通过 --i-own-these-targets 确认目标授权，跳过允许名单检查")
+		return
+	}
+
+	highConcurrency := NumConcurrentWorkers > highConcurrencyThreshold ||
+		ArrivalRate > highConcurrencyThreshold || EnableAutoTune
+	if !highConcurrency {
+		return
+	}
+
+	allowed := loadAllowlist()
+	var blocked []string
+	for _, target := range TargetURLs {
+		parsed, err := url.Parse(target)
+		if err != nil {
+			continue
+		}
+		host := parsed.Hostname()
+		if isLocalHost(host) || hostAllowed(host, allowed) {
+			continue
+		}
+		blocked = append(blocked, host)
+	}
+
+	if len(blocked) > 0 {
+		fmt.Printf("\n高并发模式 (并发 %d / 到达率 %d) 要求目标主机在允许名单中:\n",
+			NumConcurrentWorkers, ArrivalRate)
+		for _, host := range blocked {
+			fmt.Printf("  未授权目标: %s\n", host)
+		}
+		fmt.Printf("请把上述域名加入 %s (每行一个，.example.com 可匹配子域)，\n", AllowlistFile)
+		fmt.Printf("或在确认拥有压测授权后使用 --i-own-these-targets 启动\n")
+		log.Fatalf("拒绝启动: %d 个目标主机未授权", len(blocked))
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// ===================================================================================
+// --- 运行后自动分析 (Post-Run Automatic Analysis) ---
+// 通用优化建议只看最终得分，看不出"什么时候、在哪里出的问题"；
+// 这里基于时间序列和分布数据识别典型模式 (错误随RPS升高、超时集中在单个目标、
+// 吞吐平台期伴随延迟上升)，以结构化发现列表的形式进入终端输出和JSON报告
+// ===================================================================================
+
+// 单条分析发现
+type Finding struct {
+	Severity string `json:"severity"` // info / warning / critical
+	Title    string `json:"title"`
+	Detail   string `json:"detail"`
+}
+
+// 本次运行的分析结果，由main在快照生成后填充
+var runFindings []Finding
+
+// 对最终快照和时间序列做模式识别
+func analyzeRun(snap *StatsSnapshot) []Finding {
+	var findings []Finding
+	rows := timeSeriesRowsCopy()
+
+	if f := detectErrorRPSCorrelation(rows); f != nil {
+		findings = append(findings, *f)
+	}
+	if f := detectTimeoutClustering(snap); f != nil {
+		findings = append(findings, *f)
+	}
+	if f := detectThroughputPlateau(rows); f != nil {
+		findings = append(findings, *f)
+	}
+	if f := detectDominantError(snap); f != nil {
+		findings = append(findings, *f)
+	}
+	return findings
+}
+
+// 模式1: 每秒错误数与每秒请求数的相关性。
+// 强正相关说明错误是压力诱发的 (服务端过载/限流)，而不是恒定的功能性故障
+func detectErrorRPSCorrelation(rows []timeSeriesRow) *Finding {
+	if len(rows) < 30 {
+		return nil
+	}
+
+	var totalErrors int64
+	reqs := make([]float64, len(rows))
+	errs := make([]float64, len(rows))
+	for i, r := range rows {
+		reqs[i] = float64(r.requests)
+		errs[i] = float64(r.errors)
+		totalErrors += r.errors
+	}
+	if totalErrors < 50 {
+		return nil
+	}
+
+	corr := pearsonCorrelation(reqs, errs)
+	if corr < 0.6 {
+		return nil
+	}
+	return &Finding{
+		Severity: "warning",
+		Title:    "错误随RPS升高",
+		Detail: fmt.Sprintf("每秒错误数与每秒请求数强正相关 (r=%.2f, 共%d个错误)，"+
+			"错误大概率由压力诱发 (过载/限流)，建议降低并发或到达率后复测确认拐点", corr, totalErrors),
+	}
+}
+
+// 模式2: 超时是否集中在单个目标主机。
+// 多目标测试中单个主机贡献绝大多数超时，说明是该目标慢而非本端或网络问题
+func detectTimeoutClustering(snap *StatsSnapshot) *Finding {
+	var total, max int64
+	var maxHost string
+	for host, count := range snap.TimeoutsByHost {
+		total += count
+		if count > max {
+			max, maxHost = count, host
+		}
+	}
+	if total < 20 || len(snap.TimeoutsByHost) < 2 {
+		return nil
+	}
+
+	share := float64(max) / float64(total) * 100
+	if share < 70 {
+		return nil
+	}
+	return &Finding{
+		Severity: "warning",
+		Title:    "超时集中在单个目标",
+		Detail: fmt.Sprintf("%s 贡献了 %.0f%% 的超时 (%d/%d)，"+
+			"问题大概率在该目标自身而非本端并发或网络，建议单独压测确认", maxHost, share, max, total),
+	}
+}
+
+// 模式3: 吞吐平台期伴随延迟上升。
+// 前后半程RPS基本持平但P99明显恶化，是服务端排队加深、已达饱和的典型特征
+func detectThroughputPlateau(rows []timeSeriesRow) *Finding {
+	if len(rows) < 60 {
+		return nil
+	}
+
+	half := len(rows) / 2
+	firstRPS, firstP99 := avgRowStats(rows[:half])
+	secondRPS, secondP99 := avgRowStats(rows[half:])
+	if firstRPS <= 0 || firstP99 <= 0 {
+		return nil
+	}
+
+	rpsDelta := math.Abs(secondRPS-firstRPS) / firstRPS
+	if rpsDelta > 0.1 || secondP99 < firstP99*1.5 {
+		return nil
+	}
+	return &Finding{
+		Severity: "warning",
+		Title:    "吞吐平台期伴随延迟上升",
+		Detail: fmt.Sprintf("前后半程RPS基本持平 (%.0f → %.0f) 但P99从 %.0fms 升至 %.0fms，"+
+			"服务端排队在加深、系统已接近饱和，继续加压只会恶化延迟", firstRPS, secondRPS, firstP99, secondP99),
+	}
+}
+
+// 模式4: 单类错误占比过高，提示存在单一主因而非随机故障
+func detectDominantError(snap *StatsSnapshot) *Finding {
+	var total, max int64
+	var maxType string
+	for errType, count := range snap.ErrorTypes {
+		total += count
+		if count > max {
+			max, maxType = count, errType
+		}
+	}
+	if total < 100 || len(snap.ErrorTypes) < 2 {
+		return nil
+	}
+
+	share := float64(max) / float64(total) * 100
+	if share < 90 {
+		return nil
+	}
+	return &Finding{
+		Severity: "info",
+		Title:    "错误高度集中于单一类型",
+		Detail: fmt.Sprintf("\"%s\" 占全部错误的 %.0f%% (%d/%d)，"+
+			"存在单一主因，优先排查该类错误即可覆盖绝大部分失败", maxType, share, max, total),
+	}
+}
+
+// 皮尔逊相关系数，方差为0时返回0
+func pearsonCorrelation(xs, ys []float64) float64 {
+	n := float64(len(xs))
+	var sumX, sumY float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+	}
+	meanX, meanY := sumX/n, sumY/n
+
+	var cov, varX, varY float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(varX*varY)
+}
+
+// 一段时间序列的平均RPS和平均P99
+func avgRowStats(rows []timeSeriesRow) (rps, p99 float64) {
+	if len(rows) == 0 {
+		return 0, 0
+	}
+	for _, r := range rows {
+		rps += float64(r.requests)
+		p99 += r.p99
+	}
+	n := float64(len(rows))
+	return rps / n, p99 / n
+}
+
+// 打印分析发现列表
+func printAnalysisFindings(findings []Finding) {
+	if len(findings) == 0 {
+		return
+	}
+	fmt.Printf("\n🔎 自动分析发现:\n")
+	for _, f := range findings {
+		fmt.Printf("  [%s] %s: %s\n", f.Severity, f.Title, f.Detail)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ===================================================================================
+// --- 控制端点令牌鉴权 (API Token Auth) ---
+// 可远程操控的负载发生器开着不设防很危险: 仪表盘/守护进程/控制器的HTTP端点
+// 支持令牌鉴权，分只读和操作员两种角色；两个令牌都未配置时保持原有的开放行为
+// ===================================================================================
+
+// 只读令牌: 可查看状态/统计/报告
+var APIReadToken = ""
+
+// 操作员令牌: 额外可提交/取消任务、暂停/恢复负载 (同时拥有只读权限)
+var APIOperatorToken = ""
+
+const (
+	roleRead     = "read"
+	roleOperator = "operator"
+)
+
+var apiTokenEnvOnce sync.Once
+
+// 守护进程/控制器子命令不走配置解析，令牌也可经环境变量提供；
+// 配置文件显式设置的值优先
+func loadAPITokensFromEnv() {
+	apiTokenEnvOnce.Do(func() {
+		if APIReadToken == "" {
+			APIReadToken = os.Getenv("LOADTESTER_READ_TOKEN")
+		}
+		if APIOperatorToken == "" {
+			APIOperatorToken = os.Getenv("LOADTESTER_OPERATOR_TOKEN")
+		}
+	})
+}
+
+// 令牌鉴权是否启用
+func apiAuthEnabled() bool {
+	loadAPITokensFromEnv()
+	return APIReadToken != "" || APIOperatorToken != ""
+}
+
+// 从请求取令牌: Authorization: Bearer <令牌> 或 X-API-Token 头
+func requestToken(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+	return r.Header.Get("X-API-Token")
+}
+
+// 请求是否具备所需角色 (操作员令牌涵盖只读权限)
+func tokenAllows(r *http.Request, role string) bool {
+	if !apiAuthEnabled() {
+		return true
+	}
+	token := requestToken(r)
+	if token == "" {
+		return false
+	}
+	if APIOperatorToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(APIOperatorToken)) == 1 {
+		return true
+	}
+	if role == roleRead && APIReadToken != "" &&
+		subtle.ConstantTimeCompare([]byte(token), []byte(APIReadToken)) == 1 {
+		return true
+	}
+	return false
+}
+
+// 包装处理函数，要求指定角色；未通过时返回401/403
+func requireRole(role string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !tokenAllows(r, role) {
+			if requestToken(r) == "" {
+				http.Error(w, "需要API令牌 (Authorization: Bearer 或 X-API-Token)", http.StatusUnauthorized)
+			} else {
+				http.Error(w, "令牌无效或权限不足", http.StatusForbidden)
+			}
+			return
+		}
+		h(w, r)
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ===================================================================================
+// --- 响应断言框架 (Response Assertions) ---
+// 按目标配置期望状态码、响应体子串/正则、JSON路径取值和最大延迟，
+// 断言失败作为独立统计类别计入报告并影响评分
+// ===================================================================================
+
+// 单条断言规则: URLContains为空时对所有目标生效
+type Assertion struct {
+	Name             string
+	URLContains      string
+	ExpectedStatuses []int
+	BodyContains     string
+	BodyRegex        *regexp.Regexp
+	JSONPath         string // 点分路径，如 data.user.id
+	JSONEquals       string // JSON路径取值的期望字符串表示
+	MaxLatency       time.Duration
+}
+
+var Assertions []*Assertion
+
+// 断言检查响应体的最大截取量，避免大响应全量驻留内存
+const assertionBodyCaptureLimit = 64 * 1024
+
+// 限量截取响应体前缀的Writer，始终上报写入成功以不中断下游复制
+type limitedCaptureWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *limitedCaptureWriter) Write(p []byte) (int, error) {
+	if remain := assertionBodyCaptureLimit - w.buf.Len(); remain > 0 {
+		if len(p) > remain {
+			w.buf.Write(p[:remain])
+		} else {
+			w.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+// 是否有断言需要读取该URL的响应体
+func assertionsNeedBody(url string) bool {
+	for _, a := range Assertions {
+		if a.URLContains != "" && !strings.Contains(url, a.URLContains) {
+			continue
+		}
+		if a.BodyContains != "" || a.BodyRegex != nil || a.JSONPath != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// 对一次响应执行全部匹配的断言，失败计入统计
+func checkAssertions(stats *Stats, url string, statusCode int, latency time.Duration, body []byte) {
+	for _, a := range Assertions {
+		if a.URLContains != "" && !strings.Contains(url, a.URLContains) {
+			continue
+		}
+		if reason := a.check(statusCode, latency, body); reason != "" {
+			atomic.AddInt64(&stats.AssertionFailures, 1)
+			key := fmt.Sprintf("%s: %s", a.Name, reason)
+			stats.mu.Lock()
+			if stats.AssertionResults == nil {
+				stats.AssertionResults = make(map[string]int64)
+			}
+			stats.AssertionResults[key]++
+			stats.mu.Unlock()
+		}
+	}
+}
+
+// 执行单条断言，返回失败原因 (通过时返回空串)
+func (a *Assertion) check(statusCode int, latency time.Duration, body []byte) string {
+	if len(a.ExpectedStatuses) > 0 {
+		ok := false
+		for _, s := range a.ExpectedStatuses {
+			if statusCode == s {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return fmt.Sprintf("状态码 %d 不在期望范围", statusCode)
+		}
+	}
+
+	if a.MaxLatency > 0 && latency > a.MaxLatency {
+		return fmt.Sprintf("延迟 %v 超过上限 %v", latency.Round(time.Millisecond), a.MaxLatency)
+	}
+
+	if a.BodyContains != "" && !bytes.Contains(body, []byte(a.BodyContains)) {
+		return fmt.Sprintf("响应体不包含 %q", a.BodyContains)
+	}
+
+	if a.BodyRegex != nil && !a.BodyRegex.Match(body) {
+		return fmt.Sprintf("响应体不匹配正则 %q", a.BodyRegex.String())
+	}
+
+	if a.JSONPath != "" {
+		actual, err := extractJSONPath(body, a.JSONPath)
+		if err != nil {
+			return fmt.Sprintf("JSON路径 %s 取值失败: %v", a.JSONPath, err)
+		}
+		if actual != a.JSONEquals {
+			return fmt.Sprintf("JSON路径 %s 取值 %q != 期望 %q", a.JSONPath, actual, a.JSONEquals)
+		}
+	}
+
+	return ""
+}
+
+// 按点分路径从JSON中取值，返回值的字符串表示
+func extractJSONPath(body []byte, path string) (string, error) {
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return "", fmt.Errorf("响应体不是合法JSON: %v", err)
+	}
+
+	current := doc
+	for _, part := range strings.Split(path, ".") {
+		obj, ok := current.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("路径段 %q 处不是对象", part)
+		}
+		current, ok = obj[part]
+		if !ok {
+			return "", fmt.Errorf("缺少字段 %q", part)
+		}
+	}
+
+	switch v := current.(type) {
+	case string:
+		return v, nil
+	case float64:
+		// 整数值不带小数点，和配置里写的字面量保持一致
+		if v == float64(int64(v)) {
+			return fmt.Sprintf("%d", int64(v)), nil
+		}
+		return fmt.Sprintf("%g", v), nil
+	case bool:
+		return fmt.Sprintf("%t", v), nil
+	case nil:
+		return "null", nil
+	default:
+		raw, _ := json.Marshal(v)
+		return string(raw), nil
+	}
+}
+
+// 打印断言结果汇总
+func printAssertionReport(stats *StatsSnapshot) {
+	if len(Assertions) == 0 {
+		return
+	}
+
+	fmt.Printf("\n=== 响应断言结果 ===\n")
+	fmt.Printf("配置断言: %d 条 | 失败总数: %d\n", len(Assertions), stats.AssertionFailures)
+	for key, count := range stats.AssertionResults {
+		fmt.Printf("  %s: %d 次\n", key, count)
+	}
+}
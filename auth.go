@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ===================================================================================
+// --- 共享凭证管理 (Shared Credential Manager) ---
+// OAuth令牌、WS票据这类鉴权凭证全局只取一份并在过期前提前刷新，
+// 上万协程共享缓存，避免真实目标是API时把鉴权服务器也一起压测了
+// ===================================================================================
+
+var (
+	// OAuth2客户端凭证模式的令牌端点，为空时不启用鉴权
+	AuthTokenURL     = ""
+	AuthClientID     = ""
+	AuthClientSecret = ""
+	// 距过期还剩多久就提前刷新
+	AuthRefreshAhead = 60 * time.Second
+)
+
+// 实际发生的令牌请求数，证明鉴权服务器没有被连带压测
+var authTokenFetches int64
+
+type credentialManager struct {
+	mu         sync.RWMutex
+	token      string
+	expiry     time.Time
+	refreshing bool
+}
+
+var authManager = &credentialManager{}
+
+var authFetchClient = &http.Client{Timeout: 15 * time.Second}
+
+// 取当前有效令牌; 接近过期时触发一次后台刷新，调用方始终立即返回缓存值
+func (m *credentialManager) get() string {
+	m.mu.RLock()
+	token := m.token
+	needsRefresh := time.Now().After(m.expiry.Add(-AuthRefreshAhead)) && !m.refreshing
+	m.mu.RUnlock()
+
+	if needsRefresh {
+		m.mu.Lock()
+		// 双检: 只允许一个协程发起刷新
+		if !m.refreshing && time.Now().After(m.expiry.Add(-AuthRefreshAhead)) {
+			m.refreshing = true
+			go m.refresh()
+		}
+		m.mu.Unlock()
+	}
+	return token
+}
+
+// 后台刷新令牌
+func (m *credentialManager) refresh() {
+	token, expiresIn, err := fetchOAuthToken()
+
+	m.mu.Lock()
+	m.refreshing = false
+	if err == nil {
+		m.token = token
+		m.expiry = time.Now().Add(expiresIn)
+	}
+	m.mu.Unlock()
+
+	if err != nil {
+		fmt.Printf("警告: 刷新OAuth令牌失败: %v\n", err)
+	}
+}
+
+// OAuth2客户端凭证模式取令牌
+func fetchOAuthToken() (string, time.Duration, error) {
+	atomic.AddInt64(&authTokenFetches, 1)
+
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("client_id", AuthClientID)
+	form.Set("client_secret", AuthClientSecret)
+
+	resp, err := authFetchClient.Post(AuthTokenURL,
+		"application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, fmt.Errorf("令牌请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return "", 0, fmt.Errorf("令牌端点返回 %s", resp.Status)
+	}
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", 0, fmt.Errorf("令牌响应解析失败: %v", err)
+	}
+	if body.AccessToken == "" {
+		return "", 0, fmt.Errorf("令牌响应缺少access_token")
+	}
+
+	expiresIn := time.Duration(body.ExpiresIn) * time.Second
+	if expiresIn <= 0 {
+		expiresIn = time.Hour
+	}
+	return body.AccessToken, expiresIn, nil
+}
+
+// 启动时同步取首个令牌，之后全部走缓存+提前刷新
+func initAuthManager() error {
+	if AuthTokenURL == "" {
+		return nil
+	}
+
+	token, expiresIn, err := fetchOAuthToken()
+	if err != nil {
+		return fmt.Errorf("初始OAuth令牌获取失败: %v", err)
+	}
+	authManager.mu.Lock()
+	authManager.token = token
+	authManager.expiry = time.Now().Add(expiresIn)
+	authManager.mu.Unlock()
+
+	fmt.Printf("OAuth令牌已获取，有效期 %v (提前 %v 刷新)\n", expiresIn, AuthRefreshAhead)
+	return nil
+}
+
+// 鉴权是否启用
+func authEnabled() bool {
+	return AuthTokenURL != ""
+}
+
+// ===================================================================================
+// --- 静态鉴权配置 (Static Auth) ---
+// 目标只需要固定凭证时无需走OAuth流程: 直接配置Basic用户名密码或静态Bearer令牌，
+// 也可按URL子串给不同目标附加任意鉴权头 (如各自的X-API-Key)
+// ===================================================================================
+
+var (
+	// Basic认证的用户名/密码，用户名非空启用
+	AuthBasicUser = ""
+	AuthBasicPass = ""
+	// 静态Bearer令牌，非空启用
+	AuthBearerToken = ""
+)
+
+// 按URL子串匹配的目标级鉴权头规则
+type TargetAuthRule struct {
+	URLContains string            // 命中条件: URL包含该子串，为空匹配所有目标
+	Headers     map[string]string // 命中后附加的头
+}
+
+var TargetAuthRules []*TargetAuthRule
+
+// 当前Authorization头取值: OAuth动态令牌优先，其次静态Bearer，再次Basic；均未配置返回空
+func authorizationValue() string {
+	if authEnabled() {
+		if token := authManager.get(); token != "" {
+			return "Bearer " + token
+		}
+	}
+	if AuthBearerToken != "" {
+		return "Bearer " + AuthBearerToken
+	}
+	if AuthBasicUser != "" {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(AuthBasicUser+":"+AuthBasicPass))
+	}
+	return ""
+}
+
+// 按目标URL附加命中规则的鉴权头
+func applyTargetAuthHeaders(urlStr string, set func(key, value string)) {
+	for _, rule := range TargetAuthRules {
+		if rule.URLContains == "" || strings.Contains(urlStr, rule.URLContains) {
+			for key, value := range rule.Headers {
+				set(key, value)
+			}
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ===================================================================================
+// --- 自适应并发探测 (Adaptive Concurrency / AIMD) ---
+// 错误率和P99健康时加性增加并发，越界时乘性回退，自动找出最大可持续吞吐的并发数
+// ===================================================================================
+
+var (
+	EnableAutoTune       = false
+	AutoTuneMaxErrorRate = 1.0                    // 允许的区间错误率上限 (%)
+	AutoTuneMaxP99Ms     = 1000.0                 // 允许的滚动P99上限 (毫秒)
+	AutoTuneInterval     = 5 * time.Second        // 调整周期
+	AutoTuneStep         = 100                    // 加性增加的协程数
+	AutoTuneStartWorkers = 200                    // 起始并发
+)
+
+// 探测到的最优并发数 (健康状态下达到过的最大并发)，供评分和报告使用
+var autoTuneOptimalWorkers int64
+
+// 自适应并发执行入口: 基于workerPool动态伸缩，直到截止时间或被取消
+func runAutoTuneLoad(ctx context.Context, cache *RequestCache, stats *Stats, httpClient, http3Client *http.Client, wg *sync.WaitGroup) {
+	pool := &workerPool{
+		ctx:         ctx,
+		cache:       cache,
+		stats:       stats,
+		httpClient:  httpClient,
+		http3Client: http3Client,
+		wg:          wg,
+	}
+
+	current := AutoTuneStartWorkers
+	if current < 1 {
+		current = 1
+	}
+	pool.scaleTo(current)
+	log.Printf("自适应并发探测: 起始 %d 协程，阈值 错误率<%.1f%% P99<%.0fms",
+		current, AutoTuneMaxErrorRate, AutoTuneMaxP99Ms)
+
+	ticker := time.NewTicker(AutoTuneInterval)
+	defer ticker.Stop()
+
+	lastTotal := atomic.LoadInt64(&stats.TotalRequests)
+	lastFailed := atomic.LoadInt64(&stats.FailedRequests)
+
+	for {
+		select {
+		case <-ctx.Done():
+			pool.scaleTo(0)
+			return
+		case <-ticker.C:
+		}
+
+		if !testDeadline.IsZero() && time.Now().After(testDeadline) {
+			pool.scaleTo(0)
+			return
+		}
+
+		total := atomic.LoadInt64(&stats.TotalRequests)
+		failed := atomic.LoadInt64(&stats.FailedRequests)
+		deltaTotal := total - lastTotal
+		deltaFailed := failed - lastFailed
+		lastTotal, lastFailed = total, failed
+
+		errorRate := 0.0
+		if deltaTotal > 0 {
+			errorRate = float64(deltaFailed) / float64(deltaTotal) * 100
+		}
+		_, _, p99, hasLatency := rollingLatency.Percentiles(int(AutoTuneInterval.Seconds()))
+
+		healthy := errorRate <= AutoTuneMaxErrorRate && (!hasLatency || p99 <= AutoTuneMaxP99Ms)
+		if healthy && deltaTotal > 0 {
+			// 健康: 记录当前并发为可持续水平，并加性增加
+			if int64(current) > atomic.LoadInt64(&autoTuneOptimalWorkers) {
+				atomic.StoreInt64(&autoTuneOptimalWorkers, int64(current))
+			}
+			current += AutoTuneStep
+		} else if !healthy {
+			// 越界: 乘性回退
+			current /= 2
+			if current < 1 {
+				current = 1
+			}
+			log.Printf("自适应回退: 错误率 %.2f%% P99 %.0fms，并发降至 %d", errorRate, p99, current)
+		}
+
+		pool.scaleTo(current)
+	}
+}
+
+// 打印自适应探测结论
+func printAutoTuneSummary() {
+	if !EnableAutoTune {
+		return
+	}
+	optimal := atomic.LoadInt64(&autoTuneOptimalWorkers)
+	fmt.Printf("\n=== 自适应并发探测结论 ===\n")
+	if optimal > 0 {
+		fmt.Printf("探测到的最大可持续并发: %d 协程\n", optimal)
+	} else {
+		fmt.Printf("未能找到满足阈值的稳定并发水平\n")
+	}
+}
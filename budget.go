@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ===================================================================================
+// --- 压迫型模式预算管理 (Stressor Budget) ---
+// 挂起/慢速接收这类长时间占用连接的模式若不设上限，
+// 会把混合测试里正常流量的协程全部吃光；预算管理器限制同时占用数和累计占用时长
+// ===================================================================================
+
+var (
+	// 同时处于占用状态的连接数上限
+	MaxHeldConnections = 500
+	// 整个运行期间允许的累计占用时长上限
+	MaxTotalHoldTime = 30 * time.Minute
+)
+
+type stressorBudgetManager struct {
+	mu        sync.Mutex
+	held      int
+	committed time.Duration // 已承诺的累计占用时长
+}
+
+var holdBudget = &stressorBudgetManager{}
+
+// 申请一次连接占用，projected为预计占用时长; 超预算时拒绝
+func (b *stressorBudgetManager) acquire(projected time.Duration) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.held >= MaxHeldConnections {
+		return false
+	}
+	if b.committed+projected > MaxTotalHoldTime {
+		return false
+	}
+	b.held++
+	b.committed += projected
+	return true
+}
+
+// 释放一次占用 (累计时长在acquire时已计入，不回退)
+func (b *stressorBudgetManager) release() {
+	b.mu.Lock()
+	b.held--
+	b.mu.Unlock()
+}
+
+// 当前占用状况，供报告使用
+func (b *stressorBudgetManager) usage() (held int, committed time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.held, b.committed
+}
+
+// 打印预算使用情况
+func printBudgetReport(stats *StatsSnapshot) {
+	held, committed := holdBudget.usage()
+	if committed == 0 && stats.BudgetDeniedHolds == 0 {
+		return
+	}
+
+	fmt.Printf("\n=== 压迫模式预算 ===\n")
+	fmt.Printf("累计占用时长: %v / %v | 结束时仍占用: %d | 超预算拒绝: %d\n",
+		committed.Round(time.Second), MaxTotalHoldTime, held, stats.BudgetDeniedHolds)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// ===================================================================================
+// --- 并发校准 (Worker Calibration) ---
+// 正式压测前先跑一段短校准: 测量单请求的CPU成本和平均延迟，
+// 按目标RPS和可用核数推算NumConcurrentWorkers，替代拍脑袋定并发
+// ===================================================================================
+
+var (
+	// 启用校准
+	EnableCalibration = false
+	// 校准推算的目标RPS，0表示按CPU预算推算可达上限
+	CalibrationTargetRPS = 0
+	// 校准结束后直接应用建议的并发数
+	CalibrationApply = false
+)
+
+// 校准时长与并发
+const (
+	calibrationDuration = 10 * time.Second
+	// CPU预算: 推算上限时给生成器留三成余量，打满机器的测量本身就不准
+	calibrationCPUBudget = 0.7
+)
+
+// 执行校准并打印建议，CalibrationApply时直接改写NumConcurrentWorkers
+func runCalibration(ctx context.Context, cache *RequestCache, httpClient, http3Client *http.Client) {
+	workers := runtime.NumCPU() * 4
+	if workers > NumConcurrentWorkers {
+		workers = NumConcurrentWorkers
+	}
+	fmt.Printf("\n=== 并发校准 (%d 协程 x %v) ===\n", workers, calibrationDuration)
+
+	// 独立的统计块，不污染正式压测的计数
+	// (全局延迟草图会混入校准样本，相对正式跑量可忽略)
+	calStats := &Stats{
+		StartTime:     time.Now(),
+		ErrorTypes:    make(map[string]int64),
+		ResponseTimes: make([]time.Duration, 0, 10000),
+	}
+
+	// 借用时长模式让协程按截止时间退出，结束后恢复原配置
+	prevDuration := TestDuration
+	TestDuration = calibrationDuration
+	testDeadline = time.Now().Add(calibrationDuration)
+
+	cpuBefore := osResources.processCPUSeconds()
+
+	var wg sync.WaitGroup
+	pool := &workerPool{
+		ctx:         ctx,
+		cache:       cache,
+		stats:       calStats,
+		httpClient:  httpClient,
+		http3Client: http3Client,
+		wg:          &wg,
+	}
+	pool.scaleTo(workers)
+	select {
+	case <-time.After(calibrationDuration):
+	case <-ctx.Done():
+	}
+	pool.scaleTo(0)
+	wg.Wait()
+
+	cpuAfter := osResources.processCPUSeconds()
+	TestDuration = prevDuration
+	testDeadline = time.Time{}
+
+	snap := calStats.Snapshot()
+	if snap.TotalRequests < 100 {
+		log.Printf("警告: 校准样本不足 (%d 次)，跳过并发建议", snap.TotalRequests)
+		return
+	}
+
+	cpuSeconds := cpuAfter - cpuBefore
+	cpuPerReq := cpuSeconds / float64(snap.TotalRequests)
+	var avgLatency time.Duration
+	if len(snap.ResponseTimes) > 0 {
+		var total time.Duration
+		for _, rt := range snap.ResponseTimes {
+			total += rt
+		}
+		avgLatency = total / time.Duration(len(snap.ResponseTimes))
+	}
+	if cpuPerReq <= 0 || avgLatency <= 0 {
+		log.Printf("警告: 校准测量无效，跳过并发建议")
+		return
+	}
+
+	cores := float64(runtime.NumCPU())
+	maxRPS := cores * calibrationCPUBudget / cpuPerReq
+	targetRPS := float64(CalibrationTargetRPS)
+	capped := false
+	if targetRPS <= 0 || targetRPS > maxRPS {
+		if targetRPS > maxRPS {
+			capped = true
+		}
+		targetRPS = maxRPS
+	}
+
+	// Little定律: 并发 = RPS x 平均延迟，再留两成余量吸收延迟抖动
+	suggested := int(math.Ceil(targetRPS * avgLatency.Seconds() * 1.2))
+	if suggested < 1 {
+		suggested = 1
+	}
+
+	fmt.Printf("单请求CPU成本: %.3fms | 平均延迟: %v | 实测RPS: %.0f\n",
+		cpuPerReq*1000, avgLatency.Round(time.Microsecond), float64(snap.TotalRequests)/calibrationDuration.Seconds())
+	fmt.Printf("CPU预算内的RPS上限: %.0f (%d 核 x %.0f%%)\n", maxRPS, runtime.NumCPU(), calibrationCPUBudget*100)
+	if capped {
+		fmt.Printf("目标RPS %d 超出本机CPU上限，按上限推算\n", CalibrationTargetRPS)
+	}
+	fmt.Printf("建议并发数: %d (当前配置: %d)\n", suggested, NumConcurrentWorkers)
+
+	if CalibrationApply {
+		NumConcurrentWorkers = suggested
+		fmt.Printf("已应用建议并发数\n")
+	}
+}
+
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	mathrand "math/rand"
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+// ===================================================================================
+// --- 客户端混沌注入 (Client-Side Chaos) ---
+// 模拟劣化的客户端网络: 按比例给写入加人工延迟、随机重置连接，
+// 用同一次测试观察服务端/重试逻辑在弱网客户端下的表现
+// ===================================================================================
+
+var (
+	EnableChaos     = false
+	ChaosDelayRatio = 0.1                    // 注入写延迟的连接比例
+	ChaosMaxDelay   = 500 * time.Millisecond // 单次写入的最大附加延迟
+	ChaosResetRatio = 0.01                   // 每次写入触发随机连接重置的概率
+)
+
+// 注入的事件计数，写入报告
+var (
+	chaosDelaysInjected int64
+	chaosResetsInjected int64
+)
+
+// 包装net.Conn: 写路径上按概率注入延迟和连接重置
+type chaosConn struct {
+	net.Conn
+	delayed bool // 本连接是否被选中注入写延迟
+}
+
+func (c *chaosConn) Write(p []byte) (int, error) {
+	if mathrand.Float64() < ChaosResetRatio {
+		atomic.AddInt64(&chaosResetsInjected, 1)
+		c.Conn.Close()
+		return 0, &net.OpError{Op: "write", Net: "tcp", Err: context.Canceled}
+	}
+	if c.delayed {
+		atomic.AddInt64(&chaosDelaysInjected, 1)
+		time.Sleep(time.Duration(mathrand.Int63n(int64(ChaosMaxDelay))))
+	}
+	return c.Conn.Write(p)
+}
+
+// 包装拨号函数，混沌开启时为新连接套上chaosConn
+func chaosDialContext(dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil || !EnableChaos {
+			return conn, err
+		}
+		return &chaosConn{
+			Conn:    conn,
+			delayed: mathrand.Float64() < ChaosDelayRatio,
+		}, nil
+	}
+}
+
+// 混沌注入的JSON报告片段
+func chaosAnalysis() map[string]interface{} {
+	if !EnableChaos {
+		return nil
+	}
+	return map[string]interface{}{
+		"delays_injected": atomic.LoadInt64(&chaosDelaysInjected),
+		"resets_injected": atomic.LoadInt64(&chaosResetsInjected),
+		"delay_ratio":     ChaosDelayRatio,
+		"reset_ratio":     ChaosResetRatio,
+	}
+}
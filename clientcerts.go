@@ -0,0 +1,91 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// ===================================================================================
+// --- 客户端证书池轮换 (Client Certificate Pool) ---
+// mTLS服务按单一客户端证书压测时，服务端看到的永远是同一个身份，
+// 证书校验缓存/按身份限流都测不出来；这里加载一个证书目录并在握手间轮换，
+// 模拟大量不同mTLS身份访问同一服务
+// ===================================================================================
+
+// 非空启用: 目录中的 .pem (证书+私钥合并) 或 .crt/.key 同名配对均可
+var ClientCertDir = ""
+
+var (
+	clientCertPool []tls.Certificate
+	clientCertSeq  int64
+	clientCertUses int64
+)
+
+// 扫描目录加载全部证书对
+func loadClientCertPool() error {
+	if ClientCertDir == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(ClientCertDir)
+	if err != nil {
+		return fmt.Errorf("无法读取客户端证书目录: %v", err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		path := filepath.Join(ClientCertDir, name)
+		switch {
+		case strings.HasSuffix(name, ".pem"):
+			// 证书与私钥合并在同一文件
+			cert, err := tls.LoadX509KeyPair(path, path)
+			if err != nil {
+				return fmt.Errorf("加载证书 %s 失败: %v", name, err)
+			}
+			clientCertPool = append(clientCertPool, cert)
+		case strings.HasSuffix(name, ".crt"):
+			// 同名 .key 配对
+			keyPath := strings.TrimSuffix(path, ".crt") + ".key"
+			if _, err := os.Stat(keyPath); err != nil {
+				return fmt.Errorf("证书 %s 缺少同名.key私钥文件", name)
+			}
+			cert, err := tls.LoadX509KeyPair(path, keyPath)
+			if err != nil {
+				return fmt.Errorf("加载证书对 %s 失败: %v", name, err)
+			}
+			clientCertPool = append(clientCertPool, cert)
+		}
+	}
+
+	if len(clientCertPool) == 0 {
+		return fmt.Errorf("证书目录 %s 中没有可用的.pem或.crt/.key证书", ClientCertDir)
+	}
+	fmt.Printf("客户端证书池已加载: %d 个mTLS身份 (握手间轮换)\n", len(clientCertPool))
+	return nil
+}
+
+// 轮换取下一个证书 (服务端请求客户端证书时按握手调用)
+func nextClientCert(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	atomic.AddInt64(&clientCertUses, 1)
+	idx := (atomic.AddInt64(&clientCertSeq, 1) - 1) % int64(len(clientCertPool))
+	return &clientCertPool[idx], nil
+}
+
+// 把轮换钩子装到TLS配置上 (池为空不改动)
+func attachClientCertPool(cfg *tls.Config) {
+	if len(clientCertPool) > 0 {
+		cfg.GetClientCertificate = nextClientCert
+	}
+}
@@ -0,0 +1,969 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ===================================================================================
+// --- 外部配置加载 (External Configuration) ---
+// 运行参数支持 JSON 配置文件 + 命令行参数，优先级: 命令行 > 配置文件 > 内置默认值
+// 用法: load-tester -config run.json -workers 5000
+// ===================================================================================
+
+// JSON配置文件结构，全部使用指针以区分"未设置"和"显式设为零值"
+// 时间类字段使用Go时长字符串，如 "45s"、"2m"
+type FileConfig struct {
+	TotalDownloads       *int    `json:"total_downloads"`
+	TestDuration         *string `json:"test_duration"` // 如 "10m"，设置后按时长运行
+	PacingInterval       *string `json:"pacing_interval"` // 如 "50ms"，每协程目标请求间隔
+	ArrivalRate          *int    `json:"arrival_rate"` // 每秒到达请求数，大于0启用开放模型
+	RandomSeed           *int64  `json:"random_seed"` // 0为时间种子，相同种子可复现请求序列
+	TLSKeyLogFile        *string `json:"tls_key_log_file"` // SSLKEYLOGFILE格式的TLS密钥日志
+	QlogDir              *string `json:"qlog_dir"` // QUIC qlog输出目录，为空不导出
+	QlogSamplePct        *int    `json:"qlog_sample_pct"` // qlog连接采样百分比
+
+	QUICInitialStreamWindow     *int64  `json:"quic_initial_stream_window"` // 流级初始流控窗口 (字节)
+	QUICInitialConnectionWindow *int64  `json:"quic_initial_connection_window"` // 连接级初始流控窗口 (字节)
+	QUICMaxStreamWindow         *int64  `json:"quic_max_stream_window"` // 流级流控窗口上限 (字节)
+	QUICMaxConnectionWindow     *int64  `json:"quic_max_connection_window"` // 连接级流控窗口上限 (字节)
+	QUICMaxIdleTimeout          *string `json:"quic_max_idle_timeout"` // 连接最大空闲时长
+	QUICKeepAlivePeriod         *string `json:"quic_keepalive_period"` // 保活间隔，0不保活
+
+	HARFile              *string  `json:"har_file"` // HAR文件路径，非空启用回放模式
+	HARSpeed             *float64 `json:"har_speed"` // 回放倍速
+
+	AccessLogFile        *string  `json:"access_log_file"` // 访问日志路径，非空启用回放模式
+	AccessLogTargetHost  *string  `json:"access_log_target_host"` // 回放的目标主机基础URL
+	AccessLogSpeed       *float64 `json:"access_log_speed"` // 回放倍速
+
+	ScriptFile *string `json:"script_file"` // 请求构建脚本路径 (需scripting构建标签)
+
+	PayloadTemplate     *string `json:"payload_template"` // 负载模板，支持 {{uuid}} {{email}} 等函数
+	PayloadTemplateFile *string `json:"payload_template_file"` // 从文件读取负载模板
+
+	IdleProbeRate *float64 `json:"idle_probe_rate"` // 空闲阶段每秒保活探测次数，0不探测
+
+	EnableUserSessions *bool `json:"user_sessions"` // 每协程独立虚拟用户会话 (专属cookiejar)
+
+	// 多步场景，非空启用场景模式
+	ScenarioSteps []FileScenarioStep `json:"scenario_steps"`
+
+	// 响应schema校验: URL前缀 -> schema文件路径
+	ResponseSchemas map[string]string `json:"response_schemas"`
+	SchemaSamplePct *int              `json:"schema_sample_pct"` // 响应采样百分比
+
+	RYWCreateURL         *string `json:"ryw_create_url"` // 写后读探测的创建端点，非空启用
+	RYWCreateBody        *string `json:"ryw_create_body"` // 创建请求的JSON体
+	RYWReadURLTemplate   *string `json:"ryw_read_url_template"` // 读取端点模板，{id}替换为提取值
+	RYWIDPath            *string `json:"ryw_id_path"` // 创建响应中ID的点分JSON路径
+	RYWProbeInterval     *string `json:"ryw_probe_interval"` // 探测间隔
+	RYWMaxLag            *string `json:"ryw_max_lag"` // 判定失败前的最长等待
+
+	EnableCalibration    *bool   `json:"enable_calibration"` // 压测前跑校准并建议并发数
+	CalibrationTargetRPS *int    `json:"calibration_target_rps"` // 推算的目标RPS，0按CPU上限
+	CalibrationApply     *bool   `json:"calibration_apply"` // 直接应用建议的并发数
+
+	ProcessNice          *int    `json:"process_nice"` // 进程nice值 (-20..19)，0不调整
+	WorkerCPUSet         *string `json:"cpu_set"` // CPU亲和集，如 "0-3,8"，为空不绑定
+
+	OpenAPIFile          *string `json:"openapi_file"` // OpenAPI文档路径，非空启用导入模式
+	OpenAPIBaseURL       *string `json:"openapi_base_url"` // 覆盖文档内声明的服务器地址
+
+	TCPNoDelay           *bool   `json:"tcp_nodelay"` // false恢复Nagle合并小包
+	TCPSendBuffer        *int    `json:"tcp_send_buffer"` // SO_SNDBUF字节数，0沿用内核默认
+	TCPRecvBuffer        *int    `json:"tcp_recv_buffer"` // SO_RCVBUF字节数，0沿用内核默认
+	TCPUserTimeout       *string `json:"tcp_user_timeout"` // TCP_USER_TIMEOUT时长，0沿用内核默认
+
+	TargetListURL        *string `json:"target_list_url"` // 远程目标列表地址，为空读本地dependency.txt
+	TargetListAuthHeader *string `json:"target_list_auth_header"` // 拉取列表的认证头 "名称: 值"
+	TargetListRefresh    *string `json:"target_list_refresh"` // 刷新间隔，如 "5m"，0为不刷新
+
+	SpikeMultiplier      *int    `json:"spike_multiplier"` // >=2启用尖峰模式
+	SpikeInterval        *string `json:"spike_interval"`
+	SpikeDuration        *string `json:"spike_duration"`
+	StepLoadIncrement    *int    `json:"step_load_increment"` // >0启用阶梯加压
+	StepDuration         *string `json:"step_duration"`
+
+	EnableAutoTune       *bool    `json:"enable_autotune"`
+	AutoTuneMaxErrorRate *float64 `json:"autotune_max_error_rate"`
+	AutoTuneMaxP99Ms     *float64 `json:"autotune_max_p99_ms"`
+	AutoTuneStep         *int     `json:"autotune_step"`
+	AutoTuneStartWorkers *int     `json:"autotune_start_workers"`
+	Profile              *string `json:"profile"` // 资源档位预设 (small/medium/large/raspberry-pi)
+	ConcurrentWorkers    *int    `json:"concurrent_workers"`
+	CacheSize            *int    `json:"cache_size"`
+	TestMode             *int    `json:"test_mode"` // 0=正常 1=挂起 2=单字节 3=慢速接收
+	RequestTimeout       *string `json:"request_timeout"`
+	IdleConnTimeout      *string `json:"idle_conn_timeout"`
+	KeepAliveTimeout     *string `json:"keepalive_timeout"`
+	TLSHandshakeTimeout  *string `json:"tls_handshake_timeout"`
+	ResponseHeaderTimeout *string `json:"response_header_timeout"`
+	StatsUpdateInterval  *string `json:"stats_update_interval"`
+	MaxIdleConns         *int    `json:"max_idle_conns"`
+	MaxIdleConnsPerHost  *int    `json:"max_idle_conns_per_host"`
+	RateLimitSpeed       *int    `json:"rate_limit_speed_kb"`
+	RateLimitDuration    *string `json:"rate_limit_duration"`
+	HTTPVersions         *string `json:"http_versions"`
+
+	EnableWebSocket         *bool `json:"enable_websocket"`
+	EnableGRPC              *bool `json:"enable_grpc"`
+	EnableHTTP3             *bool `json:"enable_http3"`
+	EnableRandomPath        *bool `json:"enable_random_path"`
+	EnableRandomQueryParams *bool `json:"enable_random_query_params"`
+	UseRandomMethod         *bool `json:"use_random_method"`
+	EnableMultipartFormData *bool `json:"enable_multipart_form_data"`
+	EnableChunkedTransfer   *bool `json:"enable_chunked_transfer"`
+	EnableFixedHeaders      *bool `json:"enable_fixed_headers"`
+	EnableRateLimit         *bool `json:"enable_rate_limit"`
+	EnableConnectionReuse   *bool `json:"enable_connection_reuse"`
+	EnableCompression       *bool `json:"enable_compression"`
+	EnableKeepAlive         *bool `json:"enable_keep_alive"`
+	IgnoreSSLErrors         *bool `json:"ignore_ssl_errors"`
+	EnableScoring           *bool `json:"enable_scoring"`
+	EnableProgressBar       *bool `json:"enable_progress_bar"`
+	EnableTUI               *bool `json:"enable_tui"`
+	EnableVerboseLogging    *bool `json:"enable_verbose_logging"`
+
+	EnableWorkerAffinity  *bool    `json:"enable_worker_affinity"`
+	WorkerAffinityTargets *int     `json:"worker_affinity_targets"`
+	PathVocabularyFile    *string  `json:"path_vocabulary_file"`
+	ParamVocabularyFile   *string  `json:"param_vocabulary_file"`
+	EnableFuzzing         *bool    `json:"enable_fuzzing"`
+	FuzzRatio             *float64 `json:"fuzz_ratio"`
+	EnableHeaderStress    *bool    `json:"enable_header_stress"`
+	HeaderStressRatio     *float64 `json:"header_stress_ratio"`
+	HeaderStressMaxKB     *int     `json:"header_stress_max_kb"`
+	HeaderStressCount     *int     `json:"header_stress_count"`
+	EnableURLStress       *bool    `json:"enable_url_stress"`
+	URLStressRatio        *float64 `json:"url_stress_ratio"`
+	URLStressMaxLength    *int     `json:"url_stress_max_length"`
+	EnableContentVerification *bool `json:"enable_content_verification"`
+
+	URLAllowPatterns       []string `json:"url_allow_patterns"`
+	URLDenyPatterns        []string `json:"url_deny_patterns"`
+	EnableRobotsCompliance *bool    `json:"enable_robots_compliance"`
+
+	EnableTargetDiscovery *bool `json:"enable_target_discovery"`
+	DiscoveryMaxDepth     *int  `json:"discovery_max_depth"`
+	DiscoveryMaxTargets   *int  `json:"discovery_max_targets"`
+
+	DashboardAddr *string `json:"dashboard_addr"`
+	DashboardListeners *int `json:"dashboard_listeners"` // >1时用SO_REUSEPORT开多个accept循环
+
+	ConnectRateLimit *int `json:"connect_rate_limit"`
+
+	MaxHeldConnections *int    `json:"max_held_connections"`
+	MaxTotalHoldTime   *string `json:"max_total_hold_time"`
+
+	AuthTokenURL     *string `json:"auth_token_url"`
+	AuthClientID     *string `json:"auth_client_id"`
+	AuthClientSecret *string `json:"auth_client_secret"`
+	AuthRefreshAhead *string `json:"auth_refresh_ahead"`
+	AuthBasicUser    *string `json:"auth_basic_user"`    // Basic认证用户名，非空启用
+	AuthBasicPass    *string `json:"auth_basic_pass"`
+	AuthBearerToken  *string `json:"auth_bearer_token"` // 静态Bearer令牌，非空启用
+	ClientCertDir    *string `json:"client_cert_dir"`   // mTLS客户端证书目录，握手间轮换身份
+
+	// 按URL子串给不同目标附加任意鉴权头
+	TargetAuthHeaders []FileTargetAuth `json:"target_auth_headers"`
+
+	// 运行中告警规则
+	AlertRules    []FileAlertRule `json:"alert_rules"`
+	AlertInterval *string         `json:"alert_interval"` // 评估间隔
+
+	APIReadToken     *string `json:"api_read_token"` // 控制端点只读令牌
+	APIOperatorToken *string `json:"api_operator_token"` // 控制端点操作员令牌
+
+	// 每虚拟用户一次性登录流程
+	LoginURL             *string `json:"login_url"` // 登录端点，非空启用
+	LoginMethod          *string `json:"login_method"`
+	LoginBody            *string `json:"login_body"` // ${user}/${pass}替换为本用户凭证
+	LoginCredentialsFile *string `json:"login_credentials_file"` // 每行 user:pass
+	LoginTokenPath       *string `json:"login_token_path"` // 响应中令牌的JSON路径
+	LoginTokenHeader     *string `json:"login_token_header"`
+	LoginTokenPrefix     *string `json:"login_token_prefix"`
+
+	EnableChaos     *bool    `json:"enable_chaos"`
+	ChaosDelayRatio *float64 `json:"chaos_delay_ratio"`
+	ChaosMaxDelay   *string  `json:"chaos_max_delay"`
+	ChaosResetRatio *float64 `json:"chaos_reset_ratio"`
+
+	MarkerHeaderName  *string `json:"marker_header_name"`
+	MarkerHeaderValue *string `json:"marker_header_value"`
+
+	PushgatewayURL      *string `json:"pushgateway_url"`
+	PushgatewayJob      *string `json:"pushgateway_job"`
+	PushgatewayInstance *string `json:"pushgateway_instance"`
+
+	// 阶段化负载曲线: 每个阶段在duration内线性过渡到workers个协程
+	LoadStages []FileLoadStage `json:"load_stages"`
+
+	// 响应断言规则
+	Assertions []FileAssertion `json:"assertions"`
+
+	// SLA阈值表达式，如 "p95 < 500ms"、"error_rate < 1%"、"score >= 80"
+	SLAThresholds []string `json:"sla_thresholds"`
+
+	// 额外导出的兼容格式: "wrk" / "vegeta" / "k6"
+	OutputFormats []string `json:"output_formats"`
+
+	// 通过注头中间件附加到每个请求的静态头
+	ExtraRequestHeaders map[string]string `json:"extra_request_headers"`
+
+	// 分执行器并发上限 (执行器名 -> 上限)，如 {"WebSocket": 500}
+	ExecutorLimits map[string]int `json:"executor_limits"`
+
+	// 原始TCP二进制协议目标，非空启用该模式
+	RawTCPTargets []FileRawTCPTarget `json:"raw_tcp_targets"`
+
+	// 原始测量采样导出，sample_export_file非空启用
+	SampleExportFile *string `json:"sample_export_file"` // gzip压缩CSV路径
+	SampleExportRate *int    `json:"sample_export_rate"` // 每N条记1条
+
+	// 应用层流量采样捕获，flow_capture_file非空启用
+	FlowCaptureFile      *string `json:"flow_capture_file"`
+	FlowCaptureSamplePct *int    `json:"flow_capture_sample_pct"` // 采样的连接百分比
+	FlowCaptureMaxMB     *int    `json:"flow_capture_max_mb"`     // 总捕获上限 (MB)
+
+	// HTML表单发现与提交，form_page_url非空启用该模式
+	FormPageURL *string `json:"form_page_url"`
+
+	// 重定向策略: follow/deny/same-host，以及单链跳数上限
+	RedirectPolicy *string `json:"redirect_policy"`
+	MaxRedirects   *int    `json:"max_redirects"`
+
+	// 页面加载模式: 对HTML响应解析并抓取内嵌资源，报告复合页面耗时
+	EnablePageAssets     *bool `json:"page_assets"`
+	PageAssetParallelism *int  `json:"page_asset_parallelism"` // 每页资源抓取并行度
+	PageAssetMax         *int  `json:"page_asset_max"`         // 每页最多抓取的资源数
+
+	// 大文件下载基准，large_file_url非空启用该模式
+	LargeFileURL            *string `json:"large_file_url"`
+	LargeFileRangeParts     *int    `json:"large_file_range_parts"`     // 每文件并行Range分段数，1为整体下载
+	LargeFileStallThreshold *string `json:"large_file_stall_threshold"` // 单次Read超过此时长计一次停顿
+}
+
+// 配置文件中的场景步骤
+type FileScenarioStep struct {
+	Name      string                         `json:"name"`
+	Method    string                         `json:"method"`
+	URL       string                         `json:"url"`
+	Body      string                         `json:"body"`
+	Headers   map[string]string              `json:"headers"`
+	ThinkTime string                         `json:"think_time"`
+	Extract   map[string]FileScenarioExtract `json:"extract"`
+}
+
+// 配置文件中的原始TCP目标 (payload_hex与payload_base64二选一)
+type FileRawTCPTarget struct {
+	Name           string `json:"name"`
+	Address        string `json:"address"` // host:port
+	TLS            bool   `json:"tls"`
+	PayloadHex     string `json:"payload_hex"`    // 支持 {{seq:N}} / {{rand:N}} 占位符
+	PayloadBase64  string `json:"payload_base64"` // 纯字面字节
+	RespPrefixHex  string `json:"response_prefix_hex"`
+	ResponseLength int    `json:"response_length"`
+	Timeout        string `json:"timeout"`
+}
+
+// 配置文件中的变量提取规则
+type FileScenarioExtract struct {
+	From    string `json:"from"`
+	Path    string `json:"path"`
+	Pattern string `json:"pattern"`
+	Header  string `json:"header"`
+}
+
+// 配置文件中的负载阶段 (可选字段缺省时沿用全局配置)
+type FileLoadStage struct {
+	Name     string `json:"name"`
+	Duration string `json:"duration"`
+	Workers  int    `json:"workers"`
+	Rate     int    `json:"rate"`      // >0时本阶段按恒定到达率派发
+	TestMode *int   `json:"test_mode"` // 0=正常 1=挂起 2=单字节 3=慢速接收
+	HTTP3Pct *int   `json:"http3_pct"`
+	WSPct    *int   `json:"websocket_pct"`
+	GRPCPct  *int   `json:"grpc_pct"`
+}
+
+// 配置文件中的响应断言
+type FileAssertion struct {
+	Name             string `json:"name"`
+	URLContains      string `json:"url_contains"`
+	ExpectedStatuses []int  `json:"expected_statuses"`
+	BodyContains     string `json:"body_contains"`
+	BodyRegex        string `json:"body_regex"`
+	JSONPath         string `json:"json_path"`
+	JSONEquals       string `json:"json_equals"`
+	MaxLatency       string `json:"max_latency"` // 如 "500ms"
+}
+
+// 配置文件中的告警规则 (above/below至少一个)
+type FileAlertRule struct {
+	Name       string   `json:"name"`
+	Metric     string   `json:"metric"` // error_rate(%) / p99_ms / rps
+	Above      *float64 `json:"above"`
+	Below      *float64 `json:"below"`
+	For        string   `json:"for"`    // 持续多久才触发，如 "30s"，为空命中即触发
+	Action     string   `json:"action"` // log(默认) / webhook / abort
+	WebhookURL string   `json:"webhook_url"`
+}
+
+// 配置文件中的目标级鉴权头规则
+type FileTargetAuth struct {
+	URLContains string            `json:"url_contains"` // 为空匹配所有目标
+	Headers     map[string]string `json:"headers"`
+}
+
+// 解析配置: 先应用配置文件，再用显式设置的命令行参数覆盖
+func parseConfig(args []string) error {
+	fs := flag.NewFlagSet("load-tester", flag.ExitOnError)
+
+	configPath := fs.String("config", "", "JSON配置文件路径")
+	total := fs.Int("total", TotalDownloads, "总请求数")
+	workers := fs.Int("workers", NumConcurrentWorkers, "并发协程数")
+	cacheSize := fs.Int("cache-size", CacheSize, "预生成请求缓存大小")
+	mode := fs.Int("mode", int(SelectedTestMode), "测试模式 (0=正常 1=挂起 2=单字节 3=慢速接收)")
+	timeout := fs.Duration("timeout", RequestTimeout, "单请求超时")
+	duration := fs.Duration("duration", TestDuration, "测试运行时长 (如 10m，设置后按时长运行)")
+	pacing := fs.Duration("pacing", PacingInterval, "每协程目标请求间隔 (启用配速与协调遗漏补偿)")
+	rate := fs.Int("rate", ArrivalRate, "每秒到达请求数 (大于0启用开放模型恒定到达率)")
+	httpVersions := fs.String("http-versions", HTTPVersions, "HTTP版本 (h1/h2)")
+	enableWS := fs.Bool("websocket", EnableWebSocket, "启用WebSocket测试")
+	enableGRPC := fs.Bool("grpc", EnableGRPC, "启用gRPC测试")
+	enableHTTP3 := fs.Bool("http3", EnableHTTP3, "启用HTTP/3测试")
+	pushgateway := fs.String("pushgateway", PushgatewayURL, "Prometheus Pushgateway地址 (为空不推送)")
+	ackTargets := fs.Bool("i-own-these-targets", AckOwnTargets, "确认对所有目标拥有压测授权，跳过允许名单检查")
+	dashboard := fs.String("dashboard", DashboardAddr, "实时仪表盘监听地址 (如 :8089，为空不启动)")
+	tui := fs.Bool("tui", EnableTUI, "启用全屏终端TUI (非终端环境自动退回进度条)")
+	seed := fs.Int64("seed", RandomSeed, "随机种子 (0为时间种子；相同种子可复现请求序列)")
+	dryRun := fs.Bool("dry-run", EnableDryRun, "演练模式: 打印请求样本和协议占比后退出，不发送任何流量")
+	tlsKeyLog := fs.String("tls-keylog", TLSKeyLogFile, "TLS密钥日志文件 (SSLKEYLOGFILE格式，供Wireshark解密抓包)")
+	targetsURL := fs.String("targets-url", TargetListURL, "远程目标列表地址 (为空读本地dependency.txt)")
+	harFile := fs.String("har", HARFile, "HAR文件路径 (非空按录制场景回放)")
+	openapiFile := fs.String("openapi", OpenAPIFile, "OpenAPI文档路径 (非空按文档操作生成请求)")
+	nice := fs.Int("nice", ProcessNice, "进程nice值 (-20..19，0不调整)")
+	calibrate := fs.Bool("calibrate", EnableCalibration, "压测前跑10秒校准并建议并发数")
+	cpuSet := fs.String("cpus", WorkerCPUSet, "CPU亲和集 (如 0-3,8，为空不绑定)")
+	harSpeed := fs.Float64("har-speed", HARSpeed, "HAR回放倍速 (2.0为双倍速)")
+	script := fs.String("script", ScriptFile, "请求构建脚本路径 (需scripting构建标签)")
+	profile := fs.String("profile", LoadProfile, "资源档位预设 (small/medium/large/raspberry-pi，按机器规格缩放默认值)")
+	userSessions := fs.Bool("user-sessions", EnableUserSessions, "每协程独立虚拟用户会话 (专属cookiejar，互不串号)")
+	pageAssets := fs.Bool("page-assets", EnablePageAssets, "浏览器式页面加载: 解析HTML内嵌资源并并行抓取，报告复合页面耗时")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// 1. 资源档位预设 (只替换默认值，配置文件和显式命令行参数仍可逐项覆盖)
+	if *profile != "" {
+		if err := applyResourceProfile(*profile); err != nil {
+			return err
+		}
+	}
+
+	// 2. 配置文件 (覆盖内置默认值/档位)
+	if *configPath != "" {
+		if err := applyConfigFile(*configPath); err != nil {
+			return err
+		}
+		log.Printf("已加载配置文件: %s", *configPath)
+	}
+
+	// 3. 显式设置的命令行参数 (覆盖配置文件)
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "total":
+			TotalDownloads = *total
+		case "workers":
+			NumConcurrentWorkers = *workers
+		case "cache-size":
+			CacheSize = *cacheSize
+		case "mode":
+			SelectedTestMode = TestMode(*mode)
+		case "timeout":
+			RequestTimeout = *timeout
+		case "duration":
+			TestDuration = *duration
+		case "pacing":
+			PacingInterval = *pacing
+		case "rate":
+			ArrivalRate = *rate
+		case "http-versions":
+			HTTPVersions = *httpVersions
+		case "websocket":
+			EnableWebSocket = *enableWS
+		case "grpc":
+			EnableGRPC = *enableGRPC
+		case "http3":
+			EnableHTTP3 = *enableHTTP3
+		case "pushgateway":
+			PushgatewayURL = *pushgateway
+		case "i-own-these-targets":
+			AckOwnTargets = *ackTargets
+		case "dashboard":
+			DashboardAddr = *dashboard
+		case "tui":
+			EnableTUI = *tui
+		case "seed":
+			RandomSeed = *seed
+		case "dry-run":
+			EnableDryRun = *dryRun
+		case "tls-keylog":
+			TLSKeyLogFile = *tlsKeyLog
+		case "targets-url":
+			TargetListURL = *targetsURL
+		case "har":
+			HARFile = *harFile
+		case "openapi":
+			OpenAPIFile = *openapiFile
+		case "script":
+			ScriptFile = *script
+		case "user-sessions":
+			EnableUserSessions = *userSessions
+		case "page-assets":
+			EnablePageAssets = *pageAssets
+		case "nice":
+			ProcessNice = *nice
+		case "calibrate":
+			EnableCalibration = *calibrate
+		case "cpus":
+			WorkerCPUSet = *cpuSet
+		case "har-speed":
+			HARSpeed = *harSpeed
+		}
+	})
+
+	return validateConfiguration()
+}
+
+// 读取并应用JSON配置文件中显式出现的字段
+func applyConfigFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("无法读取配置文件: %v", err)
+	}
+
+	var cfg FileConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return fmt.Errorf("配置文件格式错误: %v", err)
+	}
+
+	// 档位先于其他字段应用: 档位只重置默认值，文件中的显式字段仍可逐项覆盖
+	if cfg.Profile != nil && *cfg.Profile != "" {
+		if err := applyResourceProfile(*cfg.Profile); err != nil {
+			return err
+		}
+	}
+
+	applyInt(cfg.TotalDownloads, &TotalDownloads)
+	applyInt(cfg.ConcurrentWorkers, &NumConcurrentWorkers)
+	applyInt(cfg.CacheSize, &CacheSize)
+	applyInt(cfg.MaxIdleConns, &MaxIdleConns)
+	applyInt(cfg.MaxIdleConnsPerHost, &MaxIdleConnsPerHost)
+	applyInt(cfg.RateLimitSpeed, &RateLimitSpeed)
+	applyInt(cfg.ArrivalRate, &ArrivalRate)
+	if cfg.RandomSeed != nil {
+		RandomSeed = *cfg.RandomSeed
+	}
+	applyString(cfg.TLSKeyLogFile, &TLSKeyLogFile)
+	applyString(cfg.QlogDir, &QlogDir)
+	applyInt(cfg.QlogSamplePct, &QlogSamplePct)
+	applyInt64(cfg.QUICInitialStreamWindow, &QUICInitialStreamWindow)
+	applyInt64(cfg.QUICInitialConnectionWindow, &QUICInitialConnectionWindow)
+	applyInt64(cfg.QUICMaxStreamWindow, &QUICMaxStreamWindow)
+	applyInt64(cfg.QUICMaxConnectionWindow, &QUICMaxConnectionWindow)
+	if err := applyDuration(cfg.QUICMaxIdleTimeout, &QUICMaxIdleTimeout, "quic_max_idle_timeout"); err != nil {
+		return err
+	}
+	if err := applyDuration(cfg.QUICKeepAlivePeriod, &QUICKeepAlivePeriod, "quic_keepalive_period"); err != nil {
+		return err
+	}
+	applyString(cfg.HARFile, &HARFile)
+	applyFloat(cfg.HARSpeed, &HARSpeed)
+	applyString(cfg.AccessLogFile, &AccessLogFile)
+	applyString(cfg.AccessLogTargetHost, &AccessLogTargetHost)
+	applyFloat(cfg.AccessLogSpeed, &AccessLogSpeed)
+	applyString(cfg.ScriptFile, &ScriptFile)
+	applyString(cfg.SampleExportFile, &SampleExportFile)
+	applyInt(cfg.SampleExportRate, &SampleExportRate)
+	if SampleExportRate < 1 {
+		return fmt.Errorf("sample_export_rate必须大于0")
+	}
+	applyString(cfg.FlowCaptureFile, &FlowCaptureFile)
+	applyInt(cfg.FlowCaptureSamplePct, &FlowCaptureSamplePct)
+	if cfg.FlowCaptureMaxMB != nil {
+		FlowCaptureMaxBytes = int64(*cfg.FlowCaptureMaxMB) * 1024 * 1024
+	}
+	if FlowCaptureSamplePct < 0 || FlowCaptureSamplePct > 100 {
+		return fmt.Errorf("flow_capture_sample_pct必须在0-100之间")
+	}
+	applyString(cfg.RedirectPolicy, &RedirectPolicy)
+	applyInt(cfg.MaxRedirects, &MaxRedirects)
+	if err := validateRedirectPolicy(); err != nil {
+		return err
+	}
+	if MaxRedirects < 0 {
+		return fmt.Errorf("max_redirects不能为负数")
+	}
+	applyString(cfg.FormPageURL, &FormPageURL)
+	applyBool(cfg.EnablePageAssets, &EnablePageAssets)
+	applyInt(cfg.PageAssetParallelism, &PageAssetParallelism)
+	applyInt(cfg.PageAssetMax, &PageAssetMax)
+	if PageAssetParallelism < 1 || PageAssetParallelism > 64 {
+		return fmt.Errorf("page_asset_parallelism必须在1-64之间")
+	}
+	if PageAssetMax < 1 {
+		return fmt.Errorf("page_asset_max必须大于0")
+	}
+	applyString(cfg.LargeFileURL, &LargeFileURL)
+	applyInt(cfg.LargeFileRangeParts, &LargeFileRangeParts)
+	if err := applyDuration(cfg.LargeFileStallThreshold, &LargeFileStallThreshold, "large_file_stall_threshold"); err != nil {
+		return err
+	}
+	if LargeFileRangeParts < 1 || LargeFileRangeParts > 64 {
+		return fmt.Errorf("large_file_range_parts必须在1-64之间")
+	}
+	applyFloat(cfg.IdleProbeRate, &IdleProbeRate)
+	applyBool(cfg.EnableUserSessions, &EnableUserSessions)
+	applyString(cfg.PayloadTemplate, &PayloadTemplate)
+	if cfg.PayloadTemplateFile != nil && *cfg.PayloadTemplateFile != "" {
+		data, err := os.ReadFile(*cfg.PayloadTemplateFile)
+		if err != nil {
+			return fmt.Errorf("无法读取负载模板文件 %s: %v", *cfg.PayloadTemplateFile, err)
+		}
+		PayloadTemplate = string(data)
+	}
+	if len(cfg.ResponseSchemas) > 0 {
+		if err := initResponseSchemas(cfg.ResponseSchemas); err != nil {
+			return err
+		}
+	}
+	applyInt(cfg.SchemaSamplePct, &SchemaSamplePct)
+	if SchemaSamplePct < 0 || SchemaSamplePct > 100 {
+		return fmt.Errorf("schema_sample_pct 需在0-100之间: %d", SchemaSamplePct)
+	}
+	applyString(cfg.RYWCreateURL, &RYWCreateURL)
+	applyString(cfg.RYWCreateBody, &RYWCreateBody)
+	applyString(cfg.RYWReadURLTemplate, &RYWReadURLTemplate)
+	applyString(cfg.RYWIDPath, &RYWIDPath)
+	if err := applyDuration(cfg.RYWProbeInterval, &RYWProbeInterval, "ryw_probe_interval"); err != nil {
+		return err
+	}
+	if err := applyDuration(cfg.RYWMaxLag, &RYWMaxLag, "ryw_max_lag"); err != nil {
+		return err
+	}
+	applyBool(cfg.EnableCalibration, &EnableCalibration)
+	applyInt(cfg.CalibrationTargetRPS, &CalibrationTargetRPS)
+	applyBool(cfg.CalibrationApply, &CalibrationApply)
+	applyInt(cfg.ProcessNice, &ProcessNice)
+	applyString(cfg.WorkerCPUSet, &WorkerCPUSet)
+	applyString(cfg.OpenAPIFile, &OpenAPIFile)
+	applyString(cfg.OpenAPIBaseURL, &OpenAPIBaseURL)
+	applyBool(cfg.TCPNoDelay, &TCPNoDelay)
+	applyInt(cfg.TCPSendBuffer, &TCPSendBuffer)
+	applyInt(cfg.TCPRecvBuffer, &TCPRecvBuffer)
+	if err := applyDuration(cfg.TCPUserTimeout, &TCPUserTimeout, "tcp_user_timeout"); err != nil {
+		return err
+	}
+	applyString(cfg.TargetListURL, &TargetListURL)
+	applyString(cfg.TargetListAuthHeader, &TargetListAuthHeader)
+	if err := applyDuration(cfg.TargetListRefresh, &TargetListRefresh, "target_list_refresh"); err != nil {
+		return err
+	}
+	if QlogSamplePct < 0 || QlogSamplePct > 100 {
+		return fmt.Errorf("qlog_sample_pct 需在0-100之间: %d", QlogSamplePct)
+	}
+	applyInt(cfg.SpikeMultiplier, &SpikeMultiplier)
+	applyInt(cfg.StepLoadIncrement, &StepLoadIncrement)
+	applyBool(cfg.EnableAutoTune, &EnableAutoTune)
+	applyFloat(cfg.AutoTuneMaxErrorRate, &AutoTuneMaxErrorRate)
+	applyFloat(cfg.AutoTuneMaxP99Ms, &AutoTuneMaxP99Ms)
+	applyInt(cfg.AutoTuneStep, &AutoTuneStep)
+	applyInt(cfg.AutoTuneStartWorkers, &AutoTuneStartWorkers)
+	if cfg.TestMode != nil {
+		SelectedTestMode = TestMode(*cfg.TestMode)
+	}
+
+	if err := applyDuration(cfg.TestDuration, &TestDuration, "test_duration"); err != nil {
+		return err
+	}
+	if err := applyDuration(cfg.SpikeInterval, &SpikeInterval, "spike_interval"); err != nil {
+		return err
+	}
+	if err := applyDuration(cfg.SpikeDuration, &SpikeDuration, "spike_duration"); err != nil {
+		return err
+	}
+	if err := applyDuration(cfg.StepDuration, &StepDuration, "step_duration"); err != nil {
+		return err
+	}
+	if err := applyDuration(cfg.PacingInterval, &PacingInterval, "pacing_interval"); err != nil {
+		return err
+	}
+	if err := applyDuration(cfg.RequestTimeout, &RequestTimeout, "request_timeout"); err != nil {
+		return err
+	}
+	if err := applyDuration(cfg.IdleConnTimeout, &IdleConnTimeout, "idle_conn_timeout"); err != nil {
+		return err
+	}
+	if err := applyDuration(cfg.KeepAliveTimeout, &KeepAliveTimeout, "keepalive_timeout"); err != nil {
+		return err
+	}
+	if err := applyDuration(cfg.TLSHandshakeTimeout, &TLSHandshakeTimeout, "tls_handshake_timeout"); err != nil {
+		return err
+	}
+	if err := applyDuration(cfg.ResponseHeaderTimeout, &ResponseHeaderTimeout, "response_header_timeout"); err != nil {
+		return err
+	}
+	if err := applyDuration(cfg.StatsUpdateInterval, &StatsUpdateInterval, "stats_update_interval"); err != nil {
+		return err
+	}
+	if err := applyDuration(cfg.RateLimitDuration, &RateLimitDuration, "rate_limit_duration"); err != nil {
+		return err
+	}
+
+	applyString(cfg.HTTPVersions, &HTTPVersions)
+	applyBool(cfg.EnableWebSocket, &EnableWebSocket)
+	applyBool(cfg.EnableGRPC, &EnableGRPC)
+	applyBool(cfg.EnableHTTP3, &EnableHTTP3)
+	applyBool(cfg.EnableRandomPath, &EnableRandomPath)
+	applyBool(cfg.EnableRandomQueryParams, &EnableRandomQueryParams)
+	applyBool(cfg.UseRandomMethod, &UseRandomMethod)
+	applyBool(cfg.EnableMultipartFormData, &EnableMultipartFormData)
+	applyBool(cfg.EnableChunkedTransfer, &EnableChunkedTransfer)
+	applyBool(cfg.EnableFixedHeaders, &EnableFixedHeaders)
+	applyBool(cfg.EnableRateLimit, &EnableRateLimit)
+	applyBool(cfg.EnableConnectionReuse, &EnableConnectionReuse)
+	applyBool(cfg.EnableCompression, &EnableCompression)
+	applyBool(cfg.EnableKeepAlive, &EnableKeepAlive)
+	applyBool(cfg.IgnoreSSLErrors, &IgnoreSSLErrors)
+	applyBool(cfg.EnableScoring, &EnableScoring)
+	applyBool(cfg.EnableProgressBar, &EnableProgressBar)
+	applyBool(cfg.EnableTUI, &EnableTUI)
+	applyBool(cfg.EnableVerboseLogging, &EnableVerboseLogging)
+
+	applyBool(cfg.EnableWorkerAffinity, &EnableWorkerAffinity)
+	applyInt(cfg.WorkerAffinityTargets, &WorkerAffinityTargets)
+	applyString(cfg.PathVocabularyFile, &PathVocabularyFile)
+	applyString(cfg.ParamVocabularyFile, &ParamVocabularyFile)
+	applyBool(cfg.EnableFuzzing, &EnableFuzzing)
+	applyFloat(cfg.FuzzRatio, &FuzzRatio)
+	applyBool(cfg.EnableHeaderStress, &EnableHeaderStress)
+	applyFloat(cfg.HeaderStressRatio, &HeaderStressRatio)
+	applyInt(cfg.HeaderStressMaxKB, &HeaderStressMaxKB)
+	applyInt(cfg.HeaderStressCount, &HeaderStressCount)
+	applyBool(cfg.EnableURLStress, &EnableURLStress)
+	applyFloat(cfg.URLStressRatio, &URLStressRatio)
+	applyInt(cfg.URLStressMaxLength, &URLStressMaxLength)
+	applyBool(cfg.EnableContentVerification, &EnableContentVerification)
+
+	applyBool(cfg.EnableRobotsCompliance, &EnableRobotsCompliance)
+	if err := compileURLFilters(cfg.URLAllowPatterns, cfg.URLDenyPatterns); err != nil {
+		return err
+	}
+
+	applyBool(cfg.EnableTargetDiscovery, &EnableTargetDiscovery)
+	applyInt(cfg.DiscoveryMaxDepth, &DiscoveryMaxDepth)
+	applyInt(cfg.DiscoveryMaxTargets, &DiscoveryMaxTargets)
+
+	applyString(cfg.DashboardAddr, &DashboardAddr)
+	applyInt(cfg.DashboardListeners, &DashboardListeners)
+	applyInt(cfg.ConnectRateLimit, &ConnectRateLimit)
+	applyInt(cfg.MaxHeldConnections, &MaxHeldConnections)
+	if err := applyDuration(cfg.MaxTotalHoldTime, &MaxTotalHoldTime, "max_total_hold_time"); err != nil {
+		return err
+	}
+	applyString(cfg.AuthTokenURL, &AuthTokenURL)
+	applyString(cfg.AuthClientID, &AuthClientID)
+	applyString(cfg.AuthClientSecret, &AuthClientSecret)
+	applyString(cfg.AuthBasicUser, &AuthBasicUser)
+	applyString(cfg.AuthBasicPass, &AuthBasicPass)
+	applyString(cfg.AuthBearerToken, &AuthBearerToken)
+	applyString(cfg.ClientCertDir, &ClientCertDir)
+	for _, fa := range cfg.TargetAuthHeaders {
+		if len(fa.Headers) == 0 {
+			return fmt.Errorf("target_auth_headers规则 (%s) 缺少headers", fa.URLContains)
+		}
+		TargetAuthRules = append(TargetAuthRules, &TargetAuthRule{
+			URLContains: fa.URLContains,
+			Headers:     fa.Headers,
+		})
+	}
+	if err := applyDuration(cfg.AlertInterval, &AlertInterval, "alert_interval"); err != nil {
+		return err
+	}
+	for _, fr := range cfg.AlertRules {
+		r := &AlertRule{Name: fr.Name, Metric: fr.Metric, Action: fr.Action, WebhookURL: fr.WebhookURL}
+		if r.Name == "" {
+			r.Name = fr.Metric
+		}
+		if r.Action == "" {
+			r.Action = "log"
+		}
+		if fr.Above != nil {
+			r.Above, r.HasAbove = *fr.Above, true
+		}
+		if fr.Below != nil {
+			r.Below, r.HasBelow = *fr.Below, true
+		}
+		if fr.For != "" {
+			d, err := time.ParseDuration(fr.For)
+			if err != nil {
+				return fmt.Errorf("告警规则 %s 的for无效: %v", r.Name, err)
+			}
+			r.For = d
+		}
+		AlertRules = append(AlertRules, r)
+	}
+	if err := validateAlertRules(); err != nil {
+		return err
+	}
+	applyString(cfg.APIReadToken, &APIReadToken)
+	applyString(cfg.APIOperatorToken, &APIOperatorToken)
+	applyString(cfg.LoginURL, &LoginURL)
+	applyString(cfg.LoginMethod, &LoginMethod)
+	applyString(cfg.LoginBody, &LoginBody)
+	applyString(cfg.LoginCredentialsFile, &LoginCredentialsFile)
+	applyString(cfg.LoginTokenPath, &LoginTokenPath)
+	applyString(cfg.LoginTokenHeader, &LoginTokenHeader)
+	applyString(cfg.LoginTokenPrefix, &LoginTokenPrefix)
+	if err := applyDuration(cfg.AuthRefreshAhead, &AuthRefreshAhead, "auth_refresh_ahead"); err != nil {
+		return err
+	}
+	applyBool(cfg.EnableChaos, &EnableChaos)
+	applyFloat(cfg.ChaosDelayRatio, &ChaosDelayRatio)
+	applyFloat(cfg.ChaosResetRatio, &ChaosResetRatio)
+	if err := applyDuration(cfg.ChaosMaxDelay, &ChaosMaxDelay, "chaos_max_delay"); err != nil {
+		return err
+	}
+	applyString(cfg.MarkerHeaderName, &MarkerHeaderName)
+	applyString(cfg.MarkerHeaderValue, &MarkerHeaderValue)
+
+	applyString(cfg.PushgatewayURL, &PushgatewayURL)
+	applyString(cfg.PushgatewayJob, &PushgatewayJob)
+	applyString(cfg.PushgatewayInstance, &PushgatewayInstance)
+
+	if len(cfg.LoadStages) > 0 {
+		LoadStages = LoadStages[:0]
+		for i, fs := range cfg.LoadStages {
+			d, err := time.ParseDuration(fs.Duration)
+			if err != nil {
+				return fmt.Errorf("阶段 %d (%s) 的时长格式错误: %v", i, fs.Name, err)
+			}
+			stage := LoadStage{
+				Name:     fs.Name,
+				Duration: d,
+				Workers:  fs.Workers,
+				Rate:     fs.Rate,
+				HTTP3Pct: fs.HTTP3Pct,
+				WSPct:    fs.WSPct,
+				GRPCPct:  fs.GRPCPct,
+			}
+			if fs.TestMode != nil {
+				if *fs.TestMode < 0 || *fs.TestMode > 3 {
+					return fmt.Errorf("阶段 %d (%s) 的test_mode需在0-3之间", i, fs.Name)
+				}
+				mode := TestMode(*fs.TestMode)
+				stage.Mode = &mode
+			}
+			LoadStages = append(LoadStages, stage)
+		}
+		if err := validateStages(); err != nil {
+			return err
+		}
+	}
+
+	if len(cfg.ScenarioSteps) > 0 {
+		ScenarioSteps = ScenarioSteps[:0]
+		for i, fs := range cfg.ScenarioSteps {
+			step := &ScenarioStep{
+				Name:    fs.Name,
+				Method:  fs.Method,
+				URL:     fs.URL,
+				Body:    fs.Body,
+				Headers: fs.Headers,
+			}
+			if fs.ThinkTime != "" {
+				d, err := time.ParseDuration(fs.ThinkTime)
+				if err != nil {
+					return fmt.Errorf("场景步骤 %d (%s) 的think_time格式错误: %v", i, fs.Name, err)
+				}
+				step.ThinkTime = d
+			}
+			for name, ex := range fs.Extract {
+				if step.Extracts == nil {
+					step.Extracts = make(map[string]*ScenarioExtract)
+				}
+				step.Extracts[name] = &ScenarioExtract{From: ex.From, Path: ex.Path, Pattern: ex.Pattern, Header: ex.Header}
+			}
+			ScenarioSteps = append(ScenarioSteps, step)
+		}
+		if err := validateScenario(); err != nil {
+			return err
+		}
+	}
+
+	if len(cfg.RawTCPTargets) > 0 {
+		RawTCPTargets = RawTCPTargets[:0]
+		for i, ft := range cfg.RawTCPTargets {
+			if ft.Address == "" {
+				return fmt.Errorf("原始TCP目标 %d (%s) 缺少address", i, ft.Name)
+			}
+			target := &RawTCPTarget{
+				Name:       ft.Name,
+				Address:    ft.Address,
+				UseTLS:     ft.TLS,
+				RespLength: ft.ResponseLength,
+			}
+			switch {
+			case ft.PayloadHex != "":
+				segments, err := parseHexTemplate(ft.PayloadHex)
+				if err != nil {
+					return fmt.Errorf("原始TCP目标 %d (%s): %v", i, ft.Name, err)
+				}
+				target.segments = segments
+			case ft.PayloadBase64 != "":
+				segments, err := parseBase64Payload(ft.PayloadBase64)
+				if err != nil {
+					return fmt.Errorf("原始TCP目标 %d (%s): %v", i, ft.Name, err)
+				}
+				target.segments = segments
+			default:
+				return fmt.Errorf("原始TCP目标 %d (%s) 需提供payload_hex或payload_base64", i, ft.Name)
+			}
+			if ft.RespPrefixHex != "" {
+				prefix, err := hex.DecodeString(ft.RespPrefixHex)
+				if err != nil {
+					return fmt.Errorf("原始TCP目标 %d (%s) 的response_prefix_hex无效: %v", i, ft.Name, err)
+				}
+				target.RespPrefix = prefix
+			}
+			if ft.Timeout != "" {
+				d, err := time.ParseDuration(ft.Timeout)
+				if err != nil {
+					return fmt.Errorf("原始TCP目标 %d (%s) 的timeout无效: %v", i, ft.Name, err)
+				}
+				target.Timeout = d
+			}
+			RawTCPTargets = append(RawTCPTargets, target)
+		}
+	}
+
+	if len(cfg.SLAThresholds) > 0 {
+		// 启动时就校验表达式，避免跑完才发现门禁写错
+		for _, expr := range cfg.SLAThresholds {
+			if _, err := parseSLARule(expr); err != nil {
+				return err
+			}
+		}
+		SLAThresholds = cfg.SLAThresholds
+		log.Printf("已加载 %d 条SLA阈值", len(SLAThresholds))
+	}
+
+	if len(cfg.OutputFormats) > 0 {
+		for _, format := range cfg.OutputFormats {
+			switch strings.ToLower(format) {
+			case "wrk", "vegeta", "k6":
+			default:
+				return fmt.Errorf("未知的输出格式: %s (支持 wrk/vegeta/k6)", format)
+			}
+		}
+		OutputFormats = cfg.OutputFormats
+	}
+
+	if len(cfg.ExtraRequestHeaders) > 0 {
+		ExtraRequestHeaders = cfg.ExtraRequestHeaders
+	}
+
+	if len(cfg.ExecutorLimits) > 0 {
+		ExecutorLimits = cfg.ExecutorLimits
+		if err := applyExecutorLimits(); err != nil {
+			return err
+		}
+	}
+
+	if len(cfg.Assertions) > 0 {
+		Assertions = Assertions[:0]
+		for i, fa := range cfg.Assertions {
+			a := &Assertion{
+				Name:             fa.Name,
+				URLContains:      fa.URLContains,
+				ExpectedStatuses: fa.ExpectedStatuses,
+				BodyContains:     fa.BodyContains,
+				JSONPath:         fa.JSONPath,
+				JSONEquals:       fa.JSONEquals,
+			}
+			if a.Name == "" {
+				a.Name = fmt.Sprintf("assertion_%d", i)
+			}
+			if fa.BodyRegex != "" {
+				re, err := regexp.Compile(fa.BodyRegex)
+				if err != nil {
+					return fmt.Errorf("断言 %s 的正则格式错误: %v", a.Name, err)
+				}
+				a.BodyRegex = re
+			}
+			if fa.MaxLatency != "" {
+				d, err := time.ParseDuration(fa.MaxLatency)
+				if err != nil {
+					return fmt.Errorf("断言 %s 的max_latency格式错误: %v", a.Name, err)
+				}
+				a.MaxLatency = d
+			}
+			Assertions = append(Assertions, a)
+		}
+		log.Printf("已加载 %d 条响应断言", len(Assertions))
+	}
+
+	return nil
+}
+
+func applyInt(src *int, dst *int) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+func applyBool(src *bool, dst *bool) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+func applyString(src *string, dst *string) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+func applyInt64(src *int64, dst *int64) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+func applyFloat(src *float64, dst *float64) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+func applyDuration(src *string, dst *time.Duration, field string) error {
+	if src == nil {
+		return nil
+	}
+	d, err := time.ParseDuration(*src)
+	if err != nil {
+		return fmt.Errorf("配置项 %s 的时长格式错误: %v", field, err)
+	}
+	*dst = d
+	return nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ===================================================================================
+// --- 新建连接速率限制 (Connect-Rate Limiting) ---
+// 与请求速率相互独立的全局建连速率上限 (连接/秒):
+// 调低可保护公司NAT等中间设备不被连接风暴打垮，调高配合禁用连接复用可复现connect storm
+// ===================================================================================
+
+// 每秒允许的新建连接数，0表示不限制
+var ConnectRateLimit = 0
+
+// 被限速延迟过的建连次数
+var connectsRateLimited int64
+
+// 匀速放行的建连限速器 (排队等待而不是丢弃)
+type connectLimiter struct {
+	mu   sync.Mutex
+	next time.Time
+}
+
+var connLimiter = &connectLimiter{}
+
+// 等待到允许建连的时刻; ctx取消时提前返回错误
+func (l *connectLimiter) wait(ctx context.Context) error {
+	if ConnectRateLimit <= 0 {
+		return nil
+	}
+	interval := time.Second / time.Duration(ConnectRateLimit)
+
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(interval)
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	atomic.AddInt64(&connectsRateLimited, 1)
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// 包装拨号函数，建连前先过全局限速器
+func connectLimitedDial(dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if err := connLimiter.wait(ctx); err != nil {
+			return nil, err
+		}
+		return dial(ctx, network, addr)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptrace"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ===================================================================================
+// --- 连接生命周期与复用统计 (Connection Lifetime / Reuse) ---
+// 通过GotConn跟踪每条连接服务了多少请求、存活了多久，
+// 暴露服务端keep-alive上限或空闲超时导致的连接频繁轮换问题
+// ===================================================================================
+
+var EnableConnTracking = true
+
+// 单条连接的观测记录
+type connRecord struct {
+	remoteAddr string
+	firstSeen  time.Time
+	lastSeen   time.Time
+	requests   int
+}
+
+type connTracker struct {
+	mu    sync.Mutex
+	conns map[string]*connRecord // key: 连接指针地址
+}
+
+var connTrack = &connTracker{conns: make(map[string]*connRecord)}
+
+const maxTrackedConns = 100000
+
+// 为一次请求注册连接跟踪hook (httptrace会与已有trace自动组合)
+func withConnTrackTrace(ctx context.Context) context.Context {
+	if !EnableConnTracking {
+		return ctx
+	}
+
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			key := fmt.Sprintf("%p", info.Conn)
+			now := time.Now()
+
+			connTrack.mu.Lock()
+			rec, ok := connTrack.conns[key]
+			if !ok {
+				if len(connTrack.conns) >= maxTrackedConns {
+					connTrack.mu.Unlock()
+					return
+				}
+				rec = &connRecord{firstSeen: now}
+				if info.Conn != nil {
+					rec.remoteAddr = info.Conn.RemoteAddr().String()
+				}
+				connTrack.conns[key] = rec
+			}
+			rec.lastSeen = now
+			rec.requests++
+			connTrack.mu.Unlock()
+		},
+	}
+	return httptrace.WithClientTrace(ctx, trace)
+}
+
+// 复用次数分布桶
+var connReuseBuckets = []struct {
+	label string
+	max   int
+}{
+	{"1次 (无复用)", 1},
+	{"2-5次", 5},
+	{"6-20次", 20},
+	{"21-100次", 100},
+	{">100次", 1 << 30},
+}
+
+// 汇总所有连接记录，返回复用分布与生命周期样本
+func (t *connTracker) summarize() (total int, reuseDist []int, lifetimes []time.Duration, maxRequests int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	reuseDist = make([]int, len(connReuseBuckets))
+	for _, rec := range t.conns {
+		total++
+		for i, b := range connReuseBuckets {
+			if rec.requests <= b.max {
+				reuseDist[i]++
+				break
+			}
+		}
+		if rec.requests > maxRequests {
+			maxRequests = rec.requests
+		}
+		lifetimes = append(lifetimes, rec.lastSeen.Sub(rec.firstSeen))
+	}
+	return
+}
+
+// 打印连接生命周期与复用分析
+func printConnStats() {
+	if !EnableConnTracking {
+		return
+	}
+	total, reuseDist, lifetimes, maxRequests := connTrack.summarize()
+	if total == 0 {
+		return
+	}
+
+	fmt.Printf("\n=== 连接复用分析 ===\n")
+	fmt.Printf("观测到的连接数: %d | 单连接最多服务: %d 请求\n", total, maxRequests)
+	for i, b := range connReuseBuckets {
+		if reuseDist[i] > 0 {
+			fmt.Printf("  %s: %d 连接 (%.1f%%)\n", b.label, reuseDist[i],
+				float64(reuseDist[i])/float64(total)*100)
+		}
+	}
+
+	sort.Slice(lifetimes, func(i, j int) bool { return lifetimes[i] < lifetimes[j] })
+	avg, p50, p95, _ := durationPercentiles(lifetimes)
+	fmt.Printf("连接生命周期: 平均 %.0fms | P50 %.0fms | P95 %.0fms\n", avg, p50, p95)
+
+	// 大量连接只服务1次请求说明keep-alive未生效或被服务端快速关闭
+	if float64(reuseDist[0])/float64(total) > 0.5 && total > 100 {
+		fmt.Printf("提示: 超过半数连接未被复用，服务端可能限制了keep-alive或空闲超时过短\n")
+	}
+}
+
+// 连接分析的JSON报告片段
+func connAnalysis() map[string]interface{} {
+	total, reuseDist, lifetimes, maxRequests := connTrack.summarize()
+	if total == 0 {
+		return nil
+	}
+
+	dist := make(map[string]int)
+	for i, b := range connReuseBuckets {
+		if reuseDist[i] > 0 {
+			dist[b.label] = reuseDist[i]
+		}
+	}
+	avg, p50, p95, p99 := durationPercentiles(lifetimes)
+	return map[string]interface{}{
+		"total_connections":    total,
+		"max_requests_per_conn": maxRequests,
+		"reuse_distribution":   dist,
+		"lifetime_avg_ms":      avg,
+		"lifetime_p50_ms":      p50,
+		"lifetime_p95_ms":      p95,
+		"lifetime_p99_ms":      p99,
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ===================================================================================
+// --- 写后读一致性探测 (Read-Your-Writes Probes) ---
+// 压测最终一致性系统时的常见需求: 周期性地创建资源并立即读取，
+// 单独跟踪"写入后暂不可见"的一致性滞后，与普通错误分开统计
+// ===================================================================================
+
+var (
+	// 创建资源的端点 (POST)，非空启用探测
+	RYWCreateURL = ""
+	// 创建请求的JSON体
+	RYWCreateBody = "{}"
+	// 读取端点模板，{id} 会替换为创建响应中提取的值
+	RYWReadURLTemplate = ""
+	// 创建响应中资源ID的点分JSON路径，如 "data.id"
+	RYWIDPath = "id"
+	// 探测间隔
+	RYWProbeInterval = 5 * time.Second
+	// 判定一致性失败前等待资源可见的最长时间
+	RYWMaxLag = 10 * time.Second
+)
+
+// 可见性轮询间隔
+const rywPollInterval = 200 * time.Millisecond
+
+// 探测结果统计
+var (
+	rywMu          sync.Mutex
+	rywProbes      int64
+	rywCreateFails int64
+	rywLagFailures int64 // 等到RYWMaxLag仍不可见
+	rywLagged      int64 // 首读不可见但最终可见
+	rywLags        []time.Duration
+)
+
+// 后台探测循环
+func startRYWProber(ctx context.Context, client *http.Client) {
+	if RYWCreateURL == "" || RYWReadURLTemplate == "" {
+		return
+	}
+	log.Printf("写后读一致性探测已启用: 每 %v 创建并回读一次", RYWProbeInterval)
+	go func() {
+		ticker := time.NewTicker(RYWProbeInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if isPaused() {
+				continue
+			}
+			runRYWProbe(ctx, client)
+		}
+	}()
+}
+
+// 单次探测: 创建 → 提取ID → 轮询读取直到可见或超时
+func runRYWProbe(ctx context.Context, client *http.Client) {
+	rywMu.Lock()
+	rywProbes++
+	rywMu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", RYWCreateURL, strings.NewReader(RYWCreateBody))
+	if err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		rywCountCreateFail()
+		return
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		rywCountCreateFail()
+		return
+	}
+
+	id := jsonPathString(body, RYWIDPath)
+	if id == "" {
+		rywCountCreateFail()
+		return
+	}
+	readURL := strings.ReplaceAll(RYWReadURLTemplate, "{id}", id)
+
+	// 立即读，不可见则按轮询间隔重试到上限
+	start := time.Now()
+	firstVisible := true
+	for {
+		visible := rywResourceVisible(ctx, client, readURL)
+		if visible {
+			rywMu.Lock()
+			if !firstVisible {
+				rywLagged++
+				lag := time.Since(start)
+				if len(rywLags) < 10000 {
+					rywLags = append(rywLags, lag)
+				}
+			}
+			rywMu.Unlock()
+			return
+		}
+		firstVisible = false
+		if time.Since(start) >= RYWMaxLag {
+			rywMu.Lock()
+			rywLagFailures++
+			rywMu.Unlock()
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(rywPollInterval):
+		}
+	}
+}
+
+func rywResourceVisible(ctx context.Context, client *http.Client, url string) bool {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+	resp.Body.Close()
+	return resp.StatusCode >= 200 && resp.StatusCode < 300
+}
+
+func rywCountCreateFail() {
+	rywMu.Lock()
+	rywCreateFails++
+	rywMu.Unlock()
+}
+
+// 从JSON响应里按点分路径提取字符串/数字值
+func jsonPathString(data []byte, path string) string {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return ""
+	}
+	cur := doc
+	for _, part := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		cur = m[part]
+	}
+	switch v := cur.(type) {
+	case string:
+		return v
+	case float64:
+		// JSON数字统一解析为float64，整数值去掉小数部分
+		if v == float64(int64(v)) {
+			return fmt.Sprintf("%d", int64(v))
+		}
+		return fmt.Sprintf("%g", v)
+	}
+	return ""
+}
+
+// 打印一致性探测报告
+func printRYWReport() {
+	rywMu.Lock()
+	defer rywMu.Unlock()
+	if rywProbes == 0 {
+		return
+	}
+
+	fmt.Printf("\n=== 写后读一致性 ===\n")
+	fmt.Printf("探测: %d 次 | 创建失败: %d | 首读即可见: %d | 滞后可见: %d | 超时不可见: %d\n",
+		rywProbes, rywCreateFails, rywProbes-rywCreateFails-rywLagged-rywLagFailures, rywLagged, rywLagFailures)
+	if len(rywLags) > 0 {
+		_, p50, p95, p99 := durationPercentiles(rywLags)
+		fmt.Printf("可见性滞后 P50/P95/P99: %.0f/%.0f/%.0fms\n", p50, p95, p99)
+	}
+}
+
+// 供JSON报告使用的探测数据
+func rywAnalysis() map[string]interface{} {
+	rywMu.Lock()
+	defer rywMu.Unlock()
+	if rywProbes == 0 {
+		return nil
+	}
+	result := map[string]interface{}{
+		"probes":         rywProbes,
+		"create_fails":   rywCreateFails,
+		"lagged_visible": rywLagged,
+		"lag_failures":   rywLagFailures,
+	}
+	if len(rywLags) > 0 {
+		_, p50, p95, p99 := durationPercentiles(rywLags)
+		result["lag_p50_ms"] = p50
+		result["lag_p95_ms"] = p95
+		result["lag_p99_ms"] = p99
+	}
+	return result
+}
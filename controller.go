@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ===================================================================================
+// --- 控制器/代理模式 (Controller/Agent Mode) ---
+// 代理携带能力信息注册并定期心跳；控制器在代理失联时把剩余工作量重新分配给存活代理
+// 用法: load-tester controller <监听地址>
+//       load-tester agent <控制器URL>
+// ===================================================================================
+
+const (
+	HeartbeatInterval = 5 * time.Second
+	AgentDeadTimeout  = 15 * time.Second
+)
+
+// 代理注册时上报的能力信息
+type AgentCapabilities struct {
+	ID         string   `json:"id"`
+	CPUs       int      `json:"cpus"`
+	MaxWorkers int      `json:"max_workers"`
+	Protocols  []string `json:"protocols"`
+}
+
+// 心跳请求体: 上报已完成的请求数
+type HeartbeatRequest struct {
+	ID        string `json:"id"`
+	Completed int64  `json:"completed"`
+}
+
+// 心跳/注册响应: 控制器下发当前分配的请求预算
+type AssignmentResponse struct {
+	ID       string `json:"id"`
+	Assigned int64  `json:"assigned"`
+}
+
+// 控制器侧的代理记录
+type ControlledAgent struct {
+	AgentCapabilities
+	LastHeartbeat time.Time
+	Completed     int64
+	Assigned      int64
+	Dead          bool
+}
+
+// 控制器状态
+type Controller struct {
+	mu          sync.Mutex
+	agents      map[string]*ControlledAgent
+	totalBudget int64
+	nextID      int
+}
+
+// 代理侧的动态预算: -1 表示未启用代理模式
+var agentAssignedBudget int64 = -1
+
+// 代理侧已发出的请求计数 (用于预算判断)
+var agentIssuedRequests int64
+
+// 判断代理模式下是否还允许发出新请求
+func agentBudgetAllows() bool {
+	budget := atomic.LoadInt64(&agentAssignedBudget)
+	if budget < 0 {
+		return true
+	}
+	return atomic.AddInt64(&agentIssuedRequests, 1) <= budget
+}
+
+// 控制器入口
+func runController(listenAddr string) error {
+	ctrl := &Controller{
+		agents:      make(map[string]*ControlledAgent),
+		totalBudget: int64(TotalDownloads),
+	}
+
+	// 注册/心跳可下发工作量，按操作员角色保护；代理侧用同一令牌请求
+	mux := http.NewServeMux()
+	mux.HandleFunc("/register", requireRole(roleOperator, ctrl.handleRegister))
+	mux.HandleFunc("/heartbeat", requireRole(roleOperator, ctrl.handleHeartbeat))
+
+	go ctrl.monitorAgents()
+
+	fmt.Printf("=== 控制器模式 ===\n")
+	fmt.Printf("监听地址: %s | 总预算: %d 请求\n", listenAddr, ctrl.totalBudget)
+	return http.ListenAndServe(listenAddr, mux)
+}
+
+// 处理代理注册: 记录能力并立即重新分配
+func (c *Controller) handleRegister(w http.ResponseWriter, r *http.Request) {
+	var caps AgentCapabilities
+	if err := json.NewDecoder(r.Body).Decode(&caps); err != nil {
+		http.Error(w, "注册信息格式错误", http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	c.nextID++
+	caps.ID = fmt.Sprintf("agent-%d", c.nextID)
+	c.agents[caps.ID] = &ControlledAgent{
+		AgentCapabilities: caps,
+		LastHeartbeat:     time.Now(),
+	}
+	c.rebalanceLocked()
+	assigned := c.agents[caps.ID].Assigned
+	c.mu.Unlock()
+
+	log.Printf("代理已注册: %s (CPU: %d, 最大并发: %d, 协议: %v)",
+		caps.ID, caps.CPUs, caps.MaxWorkers, caps.Protocols)
+	json.NewEncoder(w).Encode(AssignmentResponse{ID: caps.ID, Assigned: assigned})
+}
+
+// 处理心跳: 刷新存活时间并下发最新分配
+func (c *Controller) handleHeartbeat(w http.ResponseWriter, r *http.Request) {
+	var hb HeartbeatRequest
+	if err := json.NewDecoder(r.Body).Decode(&hb); err != nil {
+		http.Error(w, "心跳格式错误", http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	agent, ok := c.agents[hb.ID]
+	if !ok || agent.Dead {
+		c.mu.Unlock()
+		http.Error(w, "代理未注册或已被标记失联", http.StatusGone)
+		return
+	}
+	agent.LastHeartbeat = time.Now()
+	agent.Completed = hb.Completed
+	assigned := agent.Assigned
+	c.mu.Unlock()
+
+	json.NewEncoder(w).Encode(AssignmentResponse{ID: hb.ID, Assigned: assigned})
+}
+
+// 周期检查代理存活状态，失联时触发重新分配
+func (c *Controller) monitorAgents() {
+	ticker := time.NewTicker(HeartbeatInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		c.mu.Lock()
+		changed := false
+		for id, agent := range c.agents {
+			if !agent.Dead && time.Since(agent.LastHeartbeat) > AgentDeadTimeout {
+				agent.Dead = true
+				changed = true
+				log.Printf("代理失联: %s (已完成 %d/%d)，剩余工作量将重新分配",
+					id, agent.Completed, agent.Assigned)
+			}
+		}
+		if changed {
+			c.rebalanceLocked()
+		}
+		c.mu.Unlock()
+	}
+}
+
+// 把尚未完成的预算按CPU能力比例分配给存活代理 (调用方需持锁)
+func (c *Controller) rebalanceLocked() {
+	var completed int64
+	var totalCPUs int
+	var live []*ControlledAgent
+
+	for _, agent := range c.agents {
+		completed += agent.Completed
+		if !agent.Dead {
+			live = append(live, agent)
+			totalCPUs += agent.CPUs
+		}
+	}
+
+	remaining := c.totalBudget - completed
+	if remaining < 0 || len(live) == 0 || totalCPUs == 0 {
+		return
+	}
+
+	for _, agent := range live {
+		share := remaining * int64(agent.CPUs) / int64(totalCPUs)
+		agent.Assigned = agent.Completed + share
+	}
+	log.Printf("工作量已重新分配: 剩余 %d 请求分给 %d 个存活代理", remaining, len(live))
+}
+
+// 代理入口: 注册、启动心跳，然后返回让主流程继续正常执行测试
+func startAgentMode(controllerURL string, stats *Stats) error {
+	caps := AgentCapabilities{
+		CPUs:       runtime.NumCPU(),
+		MaxWorkers: NumConcurrentWorkers,
+		Protocols:  enabledProtocols(),
+	}
+
+	resp, err := postJSON(controllerURL+"/register", caps)
+	if err != nil {
+		return fmt.Errorf("注册到控制器失败: %v", err)
+	}
+	atomic.StoreInt64(&agentAssignedBudget, resp.Assigned)
+	log.Printf("已注册为 %s，初始分配 %d 请求", resp.ID, resp.Assigned)
+
+	go func() {
+		ticker := time.NewTicker(HeartbeatInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			hb := HeartbeatRequest{ID: resp.ID, Completed: atomic.LoadInt64(&stats.TotalRequests)}
+			assignment, err := postJSON(controllerURL+"/heartbeat", hb)
+			if err != nil {
+				log.Printf("心跳失败: %v", err)
+				continue
+			}
+			atomic.StoreInt64(&agentAssignedBudget, assignment.Assigned)
+		}
+	}()
+
+	return nil
+}
+
+// 当前启用的协议列表
+func enabledProtocols() []string {
+	protocols := []string{"http"}
+	if EnableWebSocket {
+		protocols = append(protocols, "websocket")
+	}
+	if EnableGRPC {
+		protocols = append(protocols, "grpc")
+	}
+	if EnableHTTP3 {
+		protocols = append(protocols, "http3")
+	}
+	return protocols
+}
+
+// 发送JSON请求并解析分配响应
+func postJSON(url string, body interface{}) (*AssignmentResponse, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// 控制器启用了令牌鉴权时代理携带操作员令牌
+	loadAPITokensFromEnv()
+	if APIOperatorToken != "" {
+		req.Header.Set("X-API-Token", APIOperatorToken)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("控制器返回 HTTP %d", resp.StatusCode)
+	}
+
+	var assignment AssignmentResponse
+	if err := json.NewDecoder(resp.Body).Decode(&assignment); err != nil {
+		return nil, err
+	}
+	return &assignment, nil
+}
@@ -0,0 +1,75 @@
+//go:build linux
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// ===================================================================================
+// --- 进程优先级与CPU绑定 (Nice / CPU Pinning) ---
+// 设置进程nice值和CPU亲和集，压测机与其他负载共存时让出优先级，
+// 或独占指定核把单机输出打到上限；结束时报告各核的利用率。
+// 依赖Linux系统调用，其他平台见cputune_other.go的降级实现
+// ===================================================================================
+
+// 应用nice与CPU绑定，在负载启动前调用
+func applyProcessTuning() {
+	if ProcessNice != 0 {
+		if err := unix.Setpriority(unix.PRIO_PROCESS, 0, ProcessNice); err != nil {
+			log.Printf("警告: 设置nice值失败 (负值需要root): %v", err)
+		} else {
+			fmt.Printf("进程nice值: %d\n", ProcessNice)
+		}
+	}
+	if WorkerCPUSet != "" {
+		cpus, err := parseCPUSet(WorkerCPUSet)
+		if err != nil {
+			log.Printf("警告: 无效的CPU亲和集 %q: %v", WorkerCPUSet, err)
+			return
+		}
+		var set unix.CPUSet
+		for _, cpu := range cpus {
+			set.Set(cpu)
+		}
+		// 绑定整个进程 (全部OS线程)，Go调度器会把协程分摊到这些核上
+		if err := unix.SchedSetaffinity(0, &set); err != nil {
+			log.Printf("警告: 设置CPU亲和失败: %v", err)
+			return
+		}
+		fmt.Printf("CPU亲和集: %s (%d 个核)\n", WorkerCPUSet, len(cpus))
+	}
+}
+
+// 解析 "0-3,8,10-11" 形式的CPU列表
+func parseCPUSet(s string) ([]int, error) {
+	var cpus []int
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if lo, hi, ok := strings.Cut(part, "-"); ok {
+			start, err1 := strconv.Atoi(lo)
+			end, err2 := strconv.Atoi(hi)
+			if err1 != nil || err2 != nil || start < 0 || end < start {
+				return nil, fmt.Errorf("无效区间 %q", part)
+			}
+			for cpu := start; cpu <= end; cpu++ {
+				cpus = append(cpus, cpu)
+			}
+		} else {
+			cpu, err := strconv.Atoi(part)
+			if err != nil || cpu < 0 {
+				return nil, fmt.Errorf("无效核编号 %q", part)
+			}
+			cpus = append(cpus, cpu)
+		}
+	}
+	if len(cpus) == 0 {
+		return nil, fmt.Errorf("列表为空")
+	}
+	return cpus, nil
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+
+package main
+
+import "log"
+
+// nice与CPU亲和绑定依赖Linux系统调用，其他平台忽略配置并提示
+func applyProcessTuning() {
+	if ProcessNice != 0 || WorkerCPUSet != "" {
+		log.Printf("警告: 本平台不支持nice/CPU亲和设置，已忽略")
+	}
+}
@@ -0,0 +1,387 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ===================================================================================
+// --- 守护进程模式 (Daemon Mode) ---
+// 以常驻服务的形式暴露REST任务接口: 提交配置即启动一次测试，
+// 可查询状态/实时统计、获取报告、取消运行，供外部工具编程驱动。
+// 每个任务以子进程方式运行本二进制 (-config)，工作目录隔离，互不污染全局状态
+// 用法: load-tester daemon <监听地址>
+// ===================================================================================
+
+// 任务文件的根目录
+const daemonJobRoot = "daemon_jobs"
+
+// 任务状态持久化文件 (重启后恢复队列)
+const daemonStateFile = "daemon_jobs/state.json"
+
+// 同时运行的任务数上限，超出的任务排队等待
+var DaemonMaxConcurrentJobs = 1
+
+// 一个已提交的测试任务
+type DaemonJob struct {
+	ID          string     `json:"id"`
+	Status      string     `json:"status"` // pending/running/done/failed/canceled
+	SubmittedAt time.Time  `json:"submitted_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	Dir         string     `json:"dir"`
+
+	cmd *exec.Cmd
+}
+
+// 守护进程状态
+type Daemon struct {
+	mu     sync.Mutex
+	jobs   map[string]*DaemonJob
+	order  []string // 提交顺序
+	nextID int
+}
+
+// 守护进程入口
+func runDaemon(listenAddr string) error {
+	if err := os.MkdirAll(daemonJobRoot, 0755); err != nil {
+		return fmt.Errorf("无法创建任务目录 %s: %v", daemonJobRoot, err)
+	}
+
+	d := &Daemon{jobs: make(map[string]*DaemonJob)}
+	d.loadState()
+
+	// 查询走只读角色，提交/取消需要操作员令牌
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs", func(w http.ResponseWriter, r *http.Request) {
+		role := roleRead
+		if r.Method != http.MethodGet {
+			role = roleOperator
+		}
+		requireRole(role, d.handleJobs)(w, r)
+	})
+	mux.HandleFunc("/jobs/", func(w http.ResponseWriter, r *http.Request) {
+		role := roleRead
+		if strings.HasSuffix(r.URL.Path, "/cancel") {
+			role = roleOperator
+		}
+		requireRole(role, d.handleJob)(w, r)
+	})
+
+	// 重启后恢复的排队任务立即参与调度
+	d.schedule()
+
+	fmt.Printf("=== 守护进程模式 ===\n")
+	fmt.Printf("监听地址: %s | 任务目录: %s | 并发任务上限: %d\n", listenAddr, daemonJobRoot, DaemonMaxConcurrentJobs)
+	fmt.Printf("POST /jobs 提交配置 | GET /jobs/{id} 状态 | GET /jobs/{id}/stats 实时统计\n")
+	fmt.Printf("GET /jobs/{id}/report 报告 | POST /jobs/{id}/cancel 取消\n")
+	return http.ListenAndServe(listenAddr, mux)
+}
+
+// POST /jobs 提交新任务 (体为JSON配置)；GET /jobs 列出全部任务
+func (d *Daemon) handleJobs(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		d.submitJob(w, r)
+	case http.MethodGet:
+		d.mu.Lock()
+		list := make([]*DaemonJob, 0, len(d.order))
+		for _, id := range d.order {
+			list = append(list, d.jobs[id])
+		}
+		d.mu.Unlock()
+		json.NewEncoder(w).Encode(list)
+	default:
+		http.Error(w, "仅支持 GET/POST", http.StatusMethodNotAllowed)
+	}
+}
+
+// 提交任务: 校验配置JSON，写入任务目录并启动子进程
+func (d *Daemon) submitJob(w http.ResponseWriter, r *http.Request) {
+	var cfg json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+		http.Error(w, "配置不是合法JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	d.mu.Lock()
+	d.nextID++
+	job := &DaemonJob{
+		ID:          fmt.Sprintf("job-%d", d.nextID),
+		Status:      "pending",
+		SubmittedAt: time.Now(),
+		Dir:         filepath.Join(daemonJobRoot, fmt.Sprintf("job-%d", d.nextID)),
+	}
+	d.jobs[job.ID] = job
+	d.order = append(d.order, job.ID)
+	d.mu.Unlock()
+
+	if err := os.MkdirAll(job.Dir, 0755); err != nil {
+		d.finishJob(job, "failed")
+		http.Error(w, "无法创建任务目录: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := os.WriteFile(filepath.Join(job.Dir, "config.json"), cfg, 0644); err != nil {
+		d.finishJob(job, "failed")
+		http.Error(w, "无法写入配置: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	d.mu.Lock()
+	d.persistLocked()
+	d.mu.Unlock()
+	d.schedule()
+
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+// 调度器: 按提交顺序启动排队任务，保持运行中任务数不超过上限
+func (d *Daemon) schedule() {
+	for {
+		d.mu.Lock()
+		running := 0
+		var next *DaemonJob
+		for _, id := range d.order {
+			job := d.jobs[id]
+			switch job.Status {
+			case "running":
+				running++
+			case "pending":
+				if next == nil {
+					next = job
+				}
+			}
+		}
+		d.mu.Unlock()
+
+		if next == nil || running >= DaemonMaxConcurrentJobs {
+			return
+		}
+		if err := d.startJob(next); err != nil {
+			log.Printf("任务启动失败: %s: %v", next.ID, err)
+			d.finishJob(next, "failed")
+			continue
+		}
+	}
+}
+
+// 启动任务子进程: 以任务目录为工作目录运行本二进制，报告/快照就地落盘
+func (d *Daemon) startJob(job *DaemonJob) error {
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	logFile, err := os.Create(filepath.Join(job.Dir, "job.log"))
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(self, "-config", "config.json")
+	cmd.Dir = job.Dir
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	if err := cmd.Start(); err != nil {
+		logFile.Close()
+		return err
+	}
+
+	now := time.Now()
+	d.mu.Lock()
+	job.cmd = cmd
+	job.Status = "running"
+	job.StartedAt = &now
+	d.persistLocked()
+	d.mu.Unlock()
+	log.Printf("任务已启动: %s (PID %d)", job.ID, cmd.Process.Pid)
+
+	go func() {
+		err := cmd.Wait()
+		logFile.Close()
+		status := "done"
+		if err != nil {
+			status = "failed"
+		}
+		d.mu.Lock()
+		// 取消的任务以SIGINT结束，不覆盖canceled状态
+		if job.Status == "canceled" {
+			status = "canceled"
+		}
+		d.mu.Unlock()
+		d.finishJob(job, status)
+		log.Printf("任务已结束: %s (%s)", job.ID, status)
+		// 让出的并发名额给排队任务
+		d.schedule()
+	}()
+	return nil
+}
+
+func (d *Daemon) finishJob(job *DaemonJob, status string) {
+	now := time.Now()
+	d.mu.Lock()
+	job.Status = status
+	job.FinishedAt = &now
+	job.cmd = nil
+	d.persistLocked()
+	d.mu.Unlock()
+}
+
+// 持久化任务状态 (调用方需持锁)；守护进程重启后队列不丢失
+func (d *Daemon) persistLocked() {
+	state := struct {
+		NextID int                   `json:"next_id"`
+		Order  []string              `json:"order"`
+		Jobs   map[string]*DaemonJob `json:"jobs"`
+	}{d.nextID, d.order, d.jobs}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		log.Printf("无法序列化任务状态: %v", err)
+		return
+	}
+	// 先写临时文件再改名，避免崩溃留下半截状态文件
+	tmp := daemonStateFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		log.Printf("无法写入任务状态: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, daemonStateFile); err != nil {
+		log.Printf("无法落盘任务状态: %v", err)
+	}
+}
+
+// 启动时恢复任务状态: 排队任务重新参与调度，上次运行中的任务进程已不在，标记为中断
+func (d *Daemon) loadState() {
+	data, err := os.ReadFile(daemonStateFile)
+	if err != nil {
+		return
+	}
+	var state struct {
+		NextID int                   `json:"next_id"`
+		Order  []string              `json:"order"`
+		Jobs   map[string]*DaemonJob `json:"jobs"`
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		log.Printf("任务状态文件损坏，忽略: %v", err)
+		return
+	}
+
+	d.nextID = state.NextID
+	d.order = state.Order
+	d.jobs = state.Jobs
+	interrupted := 0
+	for _, job := range d.jobs {
+		if job.Status == "running" {
+			job.Status = "interrupted"
+			interrupted++
+		}
+	}
+	log.Printf("已恢复 %d 个任务 (中断 %d 个)", len(d.jobs), interrupted)
+}
+
+// /jobs/{id}[/stats|/report|/cancel] 的分发
+func (d *Daemon) handleJob(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/jobs/")
+	id, action, _ := strings.Cut(rest, "/")
+
+	d.mu.Lock()
+	job, ok := d.jobs[id]
+	d.mu.Unlock()
+	if !ok {
+		http.Error(w, "任务不存在", http.StatusNotFound)
+		return
+	}
+
+	switch action {
+	case "":
+		json.NewEncoder(w).Encode(job)
+	case "stats":
+		d.serveJobStats(w, job)
+	case "report":
+		d.serveJobReport(w, job)
+	case "cancel":
+		if r.Method != http.MethodPost {
+			http.Error(w, "取消需用POST", http.StatusMethodNotAllowed)
+			return
+		}
+		d.cancelJob(w, job)
+	default:
+		http.Error(w, "未知操作", http.StatusNotFound)
+	}
+}
+
+// 实时统计: 给子进程发SIGUSR1触发中间快照落盘，然后返回最新快照
+func (d *Daemon) serveJobStats(w http.ResponseWriter, job *DaemonJob) {
+	d.mu.Lock()
+	cmd := job.cmd
+	d.mu.Unlock()
+
+	if cmd != nil && cmd.Process != nil {
+		signalStatsDump(cmd.Process)
+		time.Sleep(500 * time.Millisecond)
+	}
+	serveNewestFile(w, job.Dir, "snapshot_", "尚无统计快照")
+}
+
+// 报告: 返回任务目录中最新的详细报告
+func (d *Daemon) serveJobReport(w http.ResponseWriter, job *DaemonJob) {
+	serveNewestFile(w, job.Dir, "detailed_report_", "报告尚未生成")
+}
+
+// 取消: 发SIGINT让子进程优雅停止并输出部分结果报告
+func (d *Daemon) cancelJob(w http.ResponseWriter, job *DaemonJob) {
+	d.mu.Lock()
+	cmd := job.cmd
+	// 排队中的任务直接出队，运行中的发SIGINT等子进程退出
+	if cmd == nil && job.Status != "pending" {
+		d.mu.Unlock()
+		http.Error(w, "任务未在运行或排队", http.StatusConflict)
+		return
+	}
+	job.Status = "canceled"
+	d.persistLocked()
+	d.mu.Unlock()
+
+	if cmd != nil {
+		cmd.Process.Signal(os.Interrupt)
+	}
+	log.Printf("任务取消中: %s", job.ID)
+	json.NewEncoder(w).Encode(job)
+}
+
+// 返回目录中指定前缀的最新JSON文件
+func serveNewestFile(w http.ResponseWriter, dir, prefix, missingMsg string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		http.Error(w, "无法读取任务目录", http.StatusInternalServerError)
+		return
+	}
+	var names []string
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), prefix) && strings.HasSuffix(entry.Name(), ".json") {
+			names = append(names, entry.Name())
+		}
+	}
+	if len(names) == 0 {
+		http.Error(w, missingMsg, http.StatusNotFound)
+		return
+	}
+	sort.Strings(names) // 文件名带时间戳，字典序即时间序
+	data, err := os.ReadFile(filepath.Join(dir, names[len(names)-1]))
+	if err != nil {
+		http.Error(w, "无法读取文件", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ===================================================================================
+// --- 内嵌实时仪表盘 (Live Web Dashboard) ---
+// 工具自带一个HTTP服务，页面通过WebSocket每秒接收实时统计
+// (RPS、成功率、延迟百分位、错误分布)，长时间运行时比单行进度条直观得多
+// ===================================================================================
+
+// 仪表盘监听地址 (如 ":8089")，为空不启动
+var DashboardAddr = ""
+
+// 仪表盘监听器数量: >1时用SO_REUSEPORT开多个accept循环，
+// 机器被万级协程打满时观测端点依然保持响应
+var DashboardListeners = 1
+
+var dashboardUpgrader = websocket.Upgrader{
+	// 仪表盘只在本机/内网使用，不做Origin校验
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// 推送给页面的每秒指标
+type dashboardFrame struct {
+	ElapsedS      int              `json:"elapsed_s"`
+	TotalRequests int64            `json:"total_requests"`
+	RPS           int64            `json:"rps"`
+	SuccessRate   float64          `json:"success_rate"`
+	FailedTotal   int64            `json:"failed_total"`
+	P50Ms         float64          `json:"p50_ms"`
+	P95Ms         float64          `json:"p95_ms"`
+	P99Ms         float64          `json:"p99_ms"`
+	ErrorTypes    map[string]int64 `json:"error_types"`
+}
+
+// 启动仪表盘HTTP服务
+func startDashboard(stats *Stats) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", requireRole(roleRead, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(dashboardHTML))
+	}))
+	mux.HandleFunc("/ws", requireRole(roleRead, func(w http.ResponseWriter, r *http.Request) {
+		serveDashboardWS(w, r, stats)
+	}))
+	// 控制接口: 暂停/恢复负载 (需要操作员令牌)
+	mux.HandleFunc("/pause", requireRole(roleOperator, func(w http.ResponseWriter, r *http.Request) {
+		pauseLoad()
+		w.Write([]byte("paused\n"))
+	}))
+	mux.HandleFunc("/resume", requireRole(roleOperator, func(w http.ResponseWriter, r *http.Request) {
+		resumeLoad()
+		w.Write([]byte("resumed\n"))
+	}))
+
+	log.Printf("实时仪表盘: http://%s/", displayAddr(DashboardAddr))
+	if DashboardListeners > 1 {
+		for i := 0; i < DashboardListeners; i++ {
+			ln, err := reuseportListen(DashboardAddr)
+			if err != nil {
+				log.Printf("仪表盘监听器 %d 启动失败: %v", i, err)
+				return
+			}
+			go func(ln net.Listener) {
+				if err := http.Serve(ln, mux); err != nil {
+					log.Printf("仪表盘服务退出: %v", err)
+				}
+			}(ln)
+		}
+		log.Printf("仪表盘以 %d 个SO_REUSEPORT监听器运行", DashboardListeners)
+		return
+	}
+	go func() {
+		if err := http.ListenAndServe(DashboardAddr, mux); err != nil {
+			log.Printf("仪表盘服务退出: %v", err)
+		}
+	}()
+}
+
+// 把 ":8089" 这类监听地址转成可点击的显示形式
+func displayAddr(addr string) string {
+	if len(addr) > 0 && addr[0] == ':' {
+		return "localhost" + addr
+	}
+	return addr
+}
+
+// 每秒向页面推送一帧实时指标
+func serveDashboardWS(w http.ResponseWriter, r *http.Request, stats *Stats) {
+	conn, err := dashboardUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	start := time.Now()
+	lastTotal := atomic.LoadInt64(&stats.TotalRequests)
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		total := atomic.LoadInt64(&stats.TotalRequests)
+		responses := atomic.LoadInt64(&stats.TotalResponses)
+		failed := atomic.LoadInt64(&stats.FailedRequests)
+		p50, p95, p99, _ := rollingLatency.Percentiles(RollingWindowSec)
+
+		frame := dashboardFrame{
+			ElapsedS:      int(time.Since(start).Seconds()),
+			TotalRequests: total,
+			RPS:           total - lastTotal,
+			FailedTotal:   failed,
+			P50Ms:         p50,
+			P95Ms:         p95,
+			P99Ms:         p99,
+			ErrorTypes:    make(map[string]int64),
+		}
+		if total > 0 {
+			frame.SuccessRate = float64(responses) / float64(total) * 100
+		}
+		stats.mu.RLock()
+		for k, v := range stats.ErrorTypes {
+			frame.ErrorTypes[k] = v
+		}
+		stats.mu.RUnlock()
+		lastTotal = total
+
+		conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+		if err := conn.WriteJSON(frame); err != nil {
+			return
+		}
+	}
+}
+
+const dashboardHTML = `<!DOCTYPE html>
+<html lang="zh">
+<head>
+<meta charset="utf-8">
+<title>负载测试实时仪表盘</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", sans-serif; background: #1e1e2e; color: #cdd6f4; margin: 2em; }
+h1 { font-size: 1.3em; }
+.cards { display: flex; gap: 1em; flex-wrap: wrap; }
+.card { background: #313244; border-radius: 8px; padding: 1em 1.5em; min-width: 10em; }
+.card .label { font-size: .8em; color: #a6adc8; }
+.card .value { font-size: 1.8em; margin-top: .2em; }
+table { margin-top: 1.5em; border-collapse: collapse; }
+td, th { padding: .3em 1em; border-bottom: 1px solid #45475a; text-align: left; }
+#status { color: #f38ba8; }
+</style>
+</head>
+<body>
+<h1>负载测试实时仪表盘 <span id="status">连接中...</span></h1>
+<div class="cards">
+<div class="card"><div class="label">RPS</div><div class="value" id="rps">-</div></div>
+<div class="card"><div class="label">总请求</div><div class="value" id="total">-</div></div>
+<div class="card"><div class="label">成功率</div><div class="value" id="success">-</div></div>
+<div class="card"><div class="label">P50 / P95 / P99 (ms)</div><div class="value" id="latency">-</div></div>
+<div class="card"><div class="label">运行时间</div><div class="value" id="elapsed">-</div></div>
+</div>
+<table><thead><tr><th>错误类型</th><th>次数</th></tr></thead><tbody id="errors"></tbody></table>
+<script>
+const ws = new WebSocket((location.protocol === "https:" ? "wss://" : "ws://") + location.host + "/ws");
+ws.onopen = () => { document.getElementById("status").textContent = ""; };
+ws.onclose = () => { document.getElementById("status").textContent = "连接已断开"; };
+ws.onmessage = (ev) => {
+  const f = JSON.parse(ev.data);
+  document.getElementById("rps").textContent = f.rps;
+  document.getElementById("total").textContent = f.total_requests;
+  document.getElementById("success").textContent = f.success_rate.toFixed(2) + "%";
+  document.getElementById("latency").textContent =
+    f.p50_ms.toFixed(0) + " / " + f.p95_ms.toFixed(0) + " / " + f.p99_ms.toFixed(0);
+  document.getElementById("elapsed").textContent = f.elapsed_s + "s";
+  const rows = Object.entries(f.error_types).sort((a, b) => b[1] - a[1])
+    .map(([k, v]) => "<tr><td>" + k + "</td><td>" + v + "</td></tr>").join("");
+  document.getElementById("errors").innerHTML = rows;
+};
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	mathrand "math/rand"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ===================================================================================
+// --- 响应驱动的动态目标发现 (Dynamic Target Discovery) ---
+// 从响应的Link头、JSON HAL _links和HTML锚点中提取同源URL并加入目标池 (有深度上限)，
+// 把单个种子URL变成"爬取+施压"的混合探索模式
+// ===================================================================================
+
+var (
+	EnableTargetDiscovery = false
+	DiscoveryMaxDepth     = 2   // 距种子URL的最大跳数
+	DiscoveryMaxTargets   = 500 // 发现目标总数上限
+)
+
+// 已发现目标: URL -> 发现深度
+type targetDiscoverer struct {
+	mu      sync.RWMutex
+	depths  map[string]int
+	targets []string
+}
+
+var discovered = &targetDiscoverer{depths: make(map[string]int)}
+
+var htmlAnchorRe = regexp.MustCompile(`(?i)<a[^>]+href\s*=\s*["']([^"'#]+)["']`)
+var linkHeaderRe = regexp.MustCompile(`<([^>]+)>`)
+
+// 从一次响应中提取可发现的URL并入池
+func discoverTargets(sourceURL string, resp *http.Response, body []byte) {
+	if !EnableTargetDiscovery {
+		return
+	}
+
+	source, err := url.Parse(sourceURL)
+	if err != nil {
+		return
+	}
+
+	depth := discovered.depthOf(sourceURL)
+	if depth >= DiscoveryMaxDepth {
+		return
+	}
+
+	var candidates []string
+
+	// 1. Link响应头: <url>; rel="next" 形式
+	for _, link := range resp.Header.Values("Link") {
+		for _, m := range linkHeaderRe.FindAllStringSubmatch(link, -1) {
+			candidates = append(candidates, m[1])
+		}
+	}
+
+	// 2. JSON HAL风格的_links
+	contentType := resp.Header.Get("Content-Type")
+	if strings.Contains(contentType, "json") && len(body) > 0 {
+		candidates = append(candidates, extractHALLinks(body)...)
+	}
+
+	// 3. HTML锚点
+	if strings.Contains(contentType, "html") && len(body) > 0 {
+		for _, m := range htmlAnchorRe.FindAllSubmatch(body, -1) {
+			candidates = append(candidates, string(m[1]))
+		}
+	}
+
+	for _, candidate := range candidates {
+		ref, err := url.Parse(strings.TrimSpace(candidate))
+		if err != nil {
+			continue
+		}
+		resolved := source.ResolveReference(ref)
+
+		// 只接受同源的http(s)目标，避免爬出测试范围
+		if resolved.Host != source.Host {
+			continue
+		}
+		if resolved.Scheme != "http" && resolved.Scheme != "https" {
+			continue
+		}
+		resolved.Fragment = ""
+
+		// 发现的目标同样要过允许/拒绝与robots过滤
+		if ok, _ := urlAllowed(resolved.String()); !ok {
+			continue
+		}
+		discovered.add(resolved.String(), depth+1)
+	}
+}
+
+// 从HAL风格JSON中提取 _links.*.href
+func extractHALLinks(body []byte) []string {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil
+	}
+	links, ok := doc["_links"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	var hrefs []string
+	for _, v := range links {
+		if obj, ok := v.(map[string]interface{}); ok {
+			if href, ok := obj["href"].(string); ok {
+				hrefs = append(hrefs, href)
+			}
+		}
+	}
+	return hrefs
+}
+
+func (d *targetDiscoverer) depthOf(target string) int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.depths[target] // 种子URL不在map中，深度为0
+}
+
+func (d *targetDiscoverer) add(target string, depth int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, exists := d.depths[target]; exists {
+		return
+	}
+	if len(d.targets) >= DiscoveryMaxTargets {
+		return
+	}
+	d.depths[target] = depth
+	d.targets = append(d.targets, target)
+}
+
+// 随机取一个已发现的目标，没有时返回空串
+func (d *targetDiscoverer) pick() string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if len(d.targets) == 0 {
+		return ""
+	}
+	return d.targets[mathrand.Intn(len(d.targets))]
+}
+
+// 已发现目标数
+func (d *targetDiscoverer) count() int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return len(d.targets)
+}
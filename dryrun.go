@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ===================================================================================
+// --- 演练模式 (Dry Run) ---
+// 发起3000万请求之前先确认配置没写错: -dry-run 走完配置加载、目标解析和
+// 请求缓存生成的完整路径，打印请求样本和协议占比后直接退出，不发出任何流量
+// ===================================================================================
+
+var EnableDryRun = false
+
+// 演练模式下打印的请求样本数
+const dryRunSampleCount = 10
+
+// 打印演练摘要: 请求样本、协议占比和关键运行参数
+func printDryRun(cache *RequestCache) {
+	fmt.Printf("\n=== 演练模式 (不发送任何请求) ===\n")
+	fmt.Printf("目标URL: %d 个 | 并发: %d | ", len(TargetURLs), NumConcurrentWorkers)
+	if TestDuration > 0 {
+		fmt.Printf("运行时长: %v\n", TestDuration)
+	} else {
+		fmt.Printf("总请求数: %d\n", TotalDownloads)
+	}
+
+	fmt.Printf("\n--- 请求样本 (%d 条) ---\n", dryRunSampleCount)
+	for i := 0; i < dryRunSampleCount; i++ {
+		url, payload, headers, method := getFromCache(cache)
+		fmt.Printf("%2d. %s %s\n", i+1, method, url)
+		if len(payload) > 0 {
+			fmt.Printf("    载荷: %d 字节\n", len(payload))
+		}
+		if len(headers) > 0 {
+			keys := make([]string, 0, len(headers))
+			for key := range headers {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				fmt.Printf("    %s: %s\n", key, headers[key])
+			}
+		}
+	}
+
+	// 按执行器注册表推演100个选择点，得到实际协议占比
+	counts := map[string]int{}
+	names := []string{httpExecutor.Name}
+	for _, ex := range protocolRegistry {
+		names = append(names, ex.Name)
+	}
+	for choice := 0; choice < 100; choice++ {
+		counts[selectProtocolExecutor(choice).Name]++
+	}
+	fmt.Printf("\n--- 协议占比 ---\n")
+	for _, name := range names {
+		if counts[name] > 0 {
+			fmt.Printf("%s: %d%%\n", name, counts[name])
+		}
+	}
+
+	if EnableFuzzing && FuzzRatio > 0 {
+		fmt.Printf("\n畸形请求占比: %.1f%%\n", FuzzRatio*100)
+	}
+	if urlFilteringActive() {
+		fmt.Printf("URL过滤: 已启用\n")
+	}
+	if len(LoadStages) > 0 {
+		fmt.Printf("负载阶段: %d 个，总时长 %v\n", len(LoadStages), totalStageDuration())
+	}
+	if len(SLAThresholds) > 0 {
+		fmt.Printf("SLA阈值: %d 条\n", len(SLAThresholds))
+	}
+	fmt.Printf("\n配置检查通过，移除 -dry-run 后正式运行\n")
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+)
+
+// ===================================================================================
+// --- 协议执行器注册表 (Protocol Executor Registry) ---
+// 把dispatchRequest里的协议if/else阶梯改成带权重的执行器注册表，
+// 新协议 (MQTT/SSE/gRPC-Web等) 只需注册执行器即可接入，无需改动派发和worker逻辑
+// ===================================================================================
+
+// 一次派发所需的全部上下文
+type protocolRequest struct {
+	ctx         context.Context
+	httpClient  *http.Client
+	http3Client *http.Client
+	method      string
+	url         string
+	payload     []byte
+	headers     map[string]string
+	stats       *Stats
+	mode        TestMode
+}
+
+// 单个协议的执行器: Weight为默认选中权重 (百分比)，未被任何执行器选中的部分走HTTP
+type ProtocolExecutor struct {
+	Name    string
+	Proto   ProtocolType
+	Weight  int
+	Enabled func() bool
+	Execute func(r *protocolRequest)
+
+	// 并发上限信号量 (setConcurrencyLimit初始化，nil为不限)
+	sem chan struct{}
+	// 利用率统计
+	inUse  int64
+	peak   int64
+	capped int64
+}
+
+// 设置该执行器的并发上限
+func (ex *ProtocolExecutor) setConcurrencyLimit(n int) {
+	if n > 0 {
+		ex.sem = make(chan struct{}, n)
+	} else {
+		ex.sem = nil
+	}
+}
+
+// 经并发限制执行: 达到上限时回落到HTTP兜底执行器并计数；
+// HTTP自身达到上限时没有别的去处，阻塞等待空位
+func (ex *ProtocolExecutor) run(r *protocolRequest) {
+	if ex.sem != nil {
+		select {
+		case ex.sem <- struct{}{}:
+		default:
+			atomic.AddInt64(&ex.capped, 1)
+			if ex != httpExecutor {
+				httpExecutor.run(r)
+				return
+			}
+			ex.sem <- struct{}{}
+		}
+		n := atomic.AddInt64(&ex.inUse, 1)
+		for {
+			peak := atomic.LoadInt64(&ex.peak)
+			if n <= peak || atomic.CompareAndSwapInt64(&ex.peak, peak, n) {
+				break
+			}
+		}
+		defer func() {
+			atomic.AddInt64(&ex.inUse, -1)
+			<-ex.sem
+		}()
+	}
+	ex.Execute(r)
+}
+
+var protocolRegistry []*ProtocolExecutor
+
+// 注册一个协议执行器，按注册顺序参与权重选择
+func RegisterProtocolExecutor(ex *ProtocolExecutor) {
+	protocolRegistry = append(protocolRegistry, ex)
+}
+
+// HTTP作为兜底执行器，承接未被其他协议选中的全部流量
+var httpExecutor = &ProtocolExecutor{
+	Name:  "HTTP",
+	Proto: ProtocolHTTP,
+	Execute: func(r *protocolRequest) {
+		makeHTTPRequest(r.ctx, r.httpClient, r.method, r.url, r.payload, r.headers, r.stats, r.mode)
+	},
+}
+
+func init() {
+	RegisterProtocolExecutor(&ProtocolExecutor{
+		Name:    "HTTP3",
+		Proto:   ProtocolHTTP3,
+		Weight:  5,
+		Enabled: func() bool { return EnableHTTP3 },
+		Execute: func(r *protocolRequest) {
+			if r.http3Client == nil {
+				httpExecutor.Execute(r)
+				return
+			}
+			makeHTTP3Request(r.ctx, r.http3Client, r.method, r.url, r.payload, r.headers, r.stats, r.mode)
+		},
+	})
+	RegisterProtocolExecutor(&ProtocolExecutor{
+		Name:    "WebSocket",
+		Proto:   ProtocolWebSocket,
+		Weight:  10,
+		Enabled: func() bool { return EnableWebSocket },
+		Execute: func(r *protocolRequest) {
+			makeWebSocketRequest(r.url, r.headers, r.stats, r.mode)
+		},
+	})
+	RegisterProtocolExecutor(&ProtocolExecutor{
+		Name:    "gRPC",
+		Proto:   ProtocolGRPC,
+		Weight:  5,
+		Enabled: func() bool { return EnableGRPC },
+		Execute: func(r *protocolRequest) {
+			makeGRPCRequest(r.url, r.stats)
+		},
+	})
+}
+
+// 执行器的当前生效权重 (阶段计划的协议占比覆盖注册默认值)
+func executorWeight(ex *ProtocolExecutor) int {
+	if o, _ := activeStageOverrides.Load().(*stageOverrides); o != nil && o.mixSet {
+		switch ex.Proto {
+		case ProtocolHTTP3:
+			return o.http3Pct
+		case ProtocolWebSocket:
+			return o.wsPct
+		case ProtocolGRPC:
+			return o.grpcPct
+		}
+	}
+	return ex.Weight
+}
+
+// 按权重选择执行器: choice取[0,100)，依注册顺序累加已启用执行器的权重，
+// 未命中任何区间时返回HTTP兜底
+func selectProtocolExecutor(choice int) *ProtocolExecutor {
+	cum := 0
+	for _, ex := range protocolRegistry {
+		if ex.Enabled != nil && !ex.Enabled() {
+			continue
+		}
+		cum += executorWeight(ex)
+		if choice < cum {
+			return ex
+		}
+	}
+	return httpExecutor
+}
+
+// 配置的分执行器并发上限 (执行器名 -> 上限)，0或缺省为不限
+var ExecutorLimits map[string]int
+
+// 把配置的并发上限装载到对应执行器，未知执行器名报错
+func applyExecutorLimits() error {
+	for name, limit := range ExecutorLimits {
+		var target *ProtocolExecutor
+		if name == httpExecutor.Name {
+			target = httpExecutor
+		} else {
+			for _, ex := range protocolRegistry {
+				if ex.Name == name {
+					target = ex
+					break
+				}
+			}
+		}
+		if target == nil {
+			return fmt.Errorf("并发上限配置了未知执行器 %q", name)
+		}
+		if limit < 0 {
+			return fmt.Errorf("执行器 %q 的并发上限不能为负", name)
+		}
+		target.setConcurrencyLimit(limit)
+	}
+	return nil
+}
+
+// 全部设有并发上限的执行器 (含HTTP兜底)
+func limitedExecutors() []*ProtocolExecutor {
+	var limited []*ProtocolExecutor
+	if httpExecutor.sem != nil {
+		limited = append(limited, httpExecutor)
+	}
+	for _, ex := range protocolRegistry {
+		if ex.sem != nil {
+			limited = append(limited, ex)
+		}
+	}
+	return limited
+}
+
+// 打印各受限执行器的并发利用率: 峰值占用、上限和因满载回落/等待的次数
+func printExecutorReport() {
+	limited := limitedExecutors()
+	if len(limited) == 0 {
+		return
+	}
+
+	fmt.Printf("\n=== 执行器并发利用率 ===\n")
+	for _, ex := range limited {
+		limit := cap(ex.sem)
+		peak := atomic.LoadInt64(&ex.peak)
+		capped := atomic.LoadInt64(&ex.capped)
+		util := 0.0
+		if limit > 0 {
+			util = float64(peak) / float64(limit) * 100
+		}
+		fmt.Printf("%s: 峰值 %d / 上限 %d (%.1f%%)", ex.Name, peak, limit, util)
+		if capped > 0 {
+			if ex == httpExecutor {
+				fmt.Printf(" | 满载等待 %d 次", capped)
+			} else {
+				fmt.Printf(" | 满载回落HTTP %d 次", capped)
+			}
+		}
+		fmt.Printf("\n")
+	}
+}
+
+// 供JSON报告使用的执行器利用率数据
+func executorUtilization() []map[string]interface{} {
+	var rows []map[string]interface{}
+	for _, ex := range limitedExecutors() {
+		limit := cap(ex.sem)
+		peak := atomic.LoadInt64(&ex.peak)
+		util := 0.0
+		if limit > 0 {
+			util = float64(peak) / float64(limit) * 100
+		}
+		rows = append(rows, map[string]interface{}{
+			"executor":        ex.Name,
+			"limit":           limit,
+			"peak_in_use":     peak,
+			"utilization_pct": util,
+			"capped":          atomic.LoadInt64(&ex.capped),
+		})
+	}
+	return rows
+}
+
+// 按协议类型查找执行器 (目标行内proto=指令使用)
+func executorForProtocol(proto ProtocolType) *ProtocolExecutor {
+	if proto == ProtocolHTTP {
+		return httpExecutor
+	}
+	for _, ex := range protocolRegistry {
+		if ex.Proto == proto {
+			return ex
+		}
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	cryptorand "crypto/rand"
+	"encoding/binary"
+	"fmt"
+	mathrand "math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ===================================================================================
+// --- 伪造数据生成器 (Faker) ---
+// 生成能通过服务端校验的真实感数据，避免随机垃圾负载被直接拒绝
+// ===================================================================================
+
+var fakeFirstNames = []string{
+	"James", "Mary", "Robert", "Patricia", "John", "Jennifer", "Michael", "Linda",
+	"David", "Elizabeth", "William", "Barbara", "Richard", "Susan", "Joseph", "Jessica",
+	"Wei", "Fang", "Min", "Jing", "Lei", "Yan", "Tao", "Juan",
+}
+
+var fakeLastNames = []string{
+	"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis",
+	"Wang", "Li", "Zhang", "Liu", "Chen", "Yang", "Huang", "Zhao",
+}
+
+var fakeEmailDomains = []string{
+	"example.com", "example.org", "example.net", "test.example.com", "mail.example.org",
+}
+
+var fakeStreetSuffixes = []string{"St", "Ave", "Blvd", "Rd", "Ln", "Way", "Dr"}
+
+var fakeCities = []string{
+	"Springfield", "Riverside", "Franklin", "Greenville", "Bristol",
+	"Clinton", "Fairview", "Salem", "Madison", "Georgetown",
+}
+
+// 生成随机姓名
+func fakeName() string {
+	return fakeFirstNames[mathrand.Intn(len(fakeFirstNames))] + " " +
+		fakeLastNames[mathrand.Intn(len(fakeLastNames))]
+}
+
+// 生成随机邮箱地址
+func fakeEmail() string {
+	first := strings.ToLower(fakeFirstNames[mathrand.Intn(len(fakeFirstNames))])
+	last := strings.ToLower(fakeLastNames[mathrand.Intn(len(fakeLastNames))])
+	return fmt.Sprintf("%s.%s%d@%s", first, last, mathrand.Intn(1000),
+		fakeEmailDomains[mathrand.Intn(len(fakeEmailDomains))])
+}
+
+// 生成随机街道地址
+func fakeAddress() string {
+	return fmt.Sprintf("%d %s %s, %s",
+		mathrand.Intn(9900)+100,
+		fakeLastNames[mathrand.Intn(len(fakeLastNames))],
+		fakeStreetSuffixes[mathrand.Intn(len(fakeStreetSuffixes))],
+		fakeCities[mathrand.Intn(len(fakeCities))])
+}
+
+// 生成随机电话号码
+func fakePhone() string {
+	return fmt.Sprintf("+1-%d-%03d-%04d", mathrand.Intn(800)+200, mathrand.Intn(1000), mathrand.Intn(10000))
+}
+
+// 生成通过Luhn校验的卡号形状数字 (使用测试BIN前缀，并非真实卡号)
+func fakeCreditCardNumber() string {
+	digits := make([]int, 16)
+	// 4111 是标准测试前缀
+	prefix := []int{4, 1, 1, 1}
+	copy(digits, prefix)
+	for i := len(prefix); i < 15; i++ {
+		digits[i] = mathrand.Intn(10)
+	}
+
+	// 计算Luhn校验位
+	sum := 0
+	for i := 0; i < 15; i++ {
+		d := digits[14-i]
+		if i%2 == 0 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	digits[15] = (10 - sum%10) % 10
+
+	var sb strings.Builder
+	for _, d := range digits {
+		sb.WriteByte(byte('0' + d))
+	}
+	return sb.String()
+}
+
+// 生成UUIDv7 (时间有序，常用于新式数据库主键)
+func fakeUUIDv7() string {
+	var b [16]byte
+	ms := uint64(time.Now().UnixMilli())
+	binary.BigEndian.PutUint64(b[:8], ms<<16)
+	if _, err := cryptorand.Read(b[6:]); err != nil {
+		mathrand.Read(b[6:])
+	}
+	b[6] = (b[6] & 0x0f) | 0x70 // 版本7
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 变体
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// 负载模板: 配置后POST负载不再随机生成，而是按模板展开 {{函数}} 占位符，
+// 请求体可以精确贴合真实API的schema。目标URL中的占位符同样会被展开
+var PayloadTemplate string
+
+var templateFuncRe = regexp.MustCompile(`\{\{\s*(\w+)(?:\s+([^}]*?))?\s*\}\}`)
+
+var templateSeq int64
+
+// 展开模板中的 {{函数}} 占位符，支持:
+// {{uuid}} {{name}} {{email}} {{phone}} {{address}} {{card}}
+// {{now}} / {{now unix}} / {{now unixms}}
+// {{randInt 最小 最大}} {{randStr 长度}} {{seq}}
+// 未识别的占位符原样保留 (可能是场景变量等其他层的语法)
+func expandTemplateFuncs(s string) string {
+	if !strings.Contains(s, "{{") {
+		return s
+	}
+	return templateFuncRe.ReplaceAllStringFunc(s, func(match string) string {
+		parts := templateFuncRe.FindStringSubmatch(match)
+		name, arg := parts[1], strings.TrimSpace(parts[2])
+		switch name {
+		case "uuid":
+			return fakeUUIDv7()
+		case "name":
+			return fakeName()
+		case "email":
+			return fakeEmail()
+		case "phone":
+			return fakePhone()
+		case "address":
+			return fakeAddress()
+		case "card":
+			return fakeCreditCardNumber()
+		case "now":
+			switch arg {
+			case "unix":
+				return strconv.FormatInt(time.Now().Unix(), 10)
+			case "unixms":
+				return strconv.FormatInt(time.Now().UnixMilli(), 10)
+			default:
+				return time.Now().Format(time.RFC3339)
+			}
+		case "randInt":
+			fields := strings.Fields(arg)
+			if len(fields) == 2 {
+				min, err1 := strconv.Atoi(fields[0])
+				max, err2 := strconv.Atoi(fields[1])
+				if err1 == nil && err2 == nil && max >= min {
+					return strconv.Itoa(min + mathrand.Intn(max-min+1))
+				}
+			}
+			return match
+		case "randStr":
+			n, err := strconv.Atoi(arg)
+			if err != nil || n < 1 || n > 1024 {
+				return match
+			}
+			const chars = "abcdefghijklmnopqrstuvwxyz0123456789"
+			b := make([]byte, n)
+			for i := range b {
+				b[i] = chars[mathrand.Intn(len(chars))]
+			}
+			return string(b)
+		case "seq":
+			return strconv.FormatInt(atomic.AddInt64(&templateSeq, 1), 10)
+		}
+		return match
+	})
+}
+
+// 生成真实感的用户JSON对象，用于需要通过字段校验的POST负载
+func fakeUserObject() map[string]interface{} {
+	return map[string]interface{}{
+		"id":         fakeUUIDv7(),
+		"name":       fakeName(),
+		"email":      fakeEmail(),
+		"address":    fakeAddress(),
+		"phone":      fakePhone(),
+		"card":       fakeCreditCardNumber(),
+		"created_at": time.Now().Format(time.RFC3339),
+	}
+}
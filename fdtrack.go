@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ===================================================================================
+// --- 文件描述符与套接字状态采样 (FD / TCP State Sampling) ---
+// 定期经平台采样器统计FD数量和TCP连接状态分布 (ESTABLISHED/TIME_WAIT/SYN_SENT等)，
+// TIME_WAIT大量堆积时提前预警临时端口耗尽风险
+// ===================================================================================
+
+var EnableFDSampling = true
+
+const fdSampleInterval = 2 * time.Second
+
+// TIME_WAIT数量超过该值时发出预警 (Linux默认临时端口约2.8万个)
+var TimeWaitWarnThreshold = 15000
+
+// 单个采样点
+type fdSample struct {
+	Timestamp time.Time      `json:"timestamp"`
+	FDCount   int            `json:"fd_count"`
+	TCPStates map[string]int `json:"tcp_states"`
+}
+
+type fdSampler struct {
+	mu          sync.Mutex
+	samples     []fdSample
+	peakFD      int
+	peakTW      int
+	warnEmitted bool
+}
+
+var fdTrack = &fdSampler{}
+
+const maxFDSamples = 3600
+
+// 启动后台采样协程，平台取不到任何指标时静默退化
+func startFDSampler(ctx context.Context) {
+	if !EnableFDSampling {
+		return
+	}
+	if osResources.openFDs() == 0 && osResources.tcpStates() == nil {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(fdSampleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				fdTrack.sampleOnce()
+			}
+		}
+	}()
+}
+
+func (s *fdSampler) sampleOnce() {
+	fdCount := osResources.openFDs()
+	states := osResources.tcpStates()
+	if fdCount == 0 && states == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.samples) < maxFDSamples {
+		s.samples = append(s.samples, fdSample{
+			Timestamp: time.Now(),
+			FDCount:   fdCount,
+			TCPStates: states,
+		})
+	}
+	if fdCount > s.peakFD {
+		s.peakFD = fdCount
+	}
+	tw := states["TIME_WAIT"]
+	if tw > s.peakTW {
+		s.peakTW = tw
+	}
+
+	// 预警只发一次，避免刷屏
+	if tw > TimeWaitWarnThreshold && !s.warnEmitted {
+		s.warnEmitted = true
+		fmt.Printf("\n警告: TIME_WAIT连接已达 %d，临时端口可能耗尽，考虑降低连接轮换率或启用连接复用\n", tw)
+	}
+}
+
+// 打印FD与套接字状态分析
+func printFDReport() {
+	fdTrack.mu.Lock()
+	sampleCount := len(fdTrack.samples)
+	peakFD := fdTrack.peakFD
+	peakTW := fdTrack.peakTW
+	var last fdSample
+	if sampleCount > 0 {
+		last = fdTrack.samples[sampleCount-1]
+	}
+	fdTrack.mu.Unlock()
+
+	if sampleCount == 0 {
+		return
+	}
+
+	fmt.Printf("\n=== FD与套接字状态 ===\n")
+	fmt.Printf("峰值FD: %d | 峰值TIME_WAIT: %d | 采样点: %d\n", peakFD, peakTW, sampleCount)
+	if len(last.TCPStates) > 0 {
+		fmt.Printf("结束时TCP状态:")
+		for _, name := range []string{"ESTABLISHED", "TIME_WAIT", "SYN_SENT", "CLOSE_WAIT", "FIN_WAIT1", "FIN_WAIT2"} {
+			if n := last.TCPStates[name]; n > 0 {
+				fmt.Printf(" %s=%d", name, n)
+			}
+		}
+		fmt.Printf("\n")
+	}
+}
+
+// FD采样序列的JSON报告片段
+func fdAnalysis() map[string]interface{} {
+	fdTrack.mu.Lock()
+	defer fdTrack.mu.Unlock()
+
+	if len(fdTrack.samples) == 0 {
+		return nil
+	}
+	return map[string]interface{}{
+		"peak_fd_count":  fdTrack.peakFD,
+		"peak_time_wait": fdTrack.peakTW,
+		"samples":        fdTrack.samples,
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	mathrand "math/rand"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ===================================================================================
+// --- 应用层流量采样捕获 (Flow Capture) ---
+// 高压下偶发的怪异服务端行为 (半截响应/非法帧/奇怪的重定向) 靠统计数字查不出来；
+// 对一小部分连接把应用层字节原样落盘供离线协议分析。
+// tap挂在解密之后: http连接在TCP层、https连接在TLS层包装io.Reader/Writer，
+// 记录的始终是明文。输出为带时间戳、连接号和方向的hexdump流文件
+// ===================================================================================
+
+var (
+	// 非空启用流捕获
+	FlowCaptureFile = ""
+	// 采样的连接百分比
+	FlowCaptureSamplePct = 1
+	// 总捕获字节上限，防止长跑写满磁盘
+	FlowCaptureMaxBytes = int64(16 * 1024 * 1024)
+)
+
+var (
+	flowMu            sync.Mutex
+	flowOut           *os.File
+	flowStart         time.Time
+	flowConnSeq       int64
+	flowConnsTapped   int64
+	flowBytesCaptured int64
+)
+
+// 打开捕获文件并写文件头
+func initFlowCapture() error {
+	if FlowCaptureFile == "" {
+		return nil
+	}
+	f, err := os.Create(FlowCaptureFile)
+	if err != nil {
+		return fmt.Errorf("无法创建流捕获文件: %v", err)
+	}
+	flowOut = f
+	flowStart = time.Now()
+	fmt.Fprintf(f, "# load-tester flow capture %s (采样 %d%% 连接，上限 %dMB)\n",
+		flowStart.Format(time.RFC3339), FlowCaptureSamplePct, FlowCaptureMaxBytes/1024/1024)
+	fmt.Printf("流捕获已启用: %s (采样 %d%% 连接)\n", FlowCaptureFile, FlowCaptureSamplePct)
+	return nil
+}
+
+// 捕获是否仍在进行 (未启用或已达字节上限时停止)
+func flowCaptureActive() bool {
+	return flowOut != nil && atomic.LoadInt64(&flowBytesCaptured) < FlowCaptureMaxBytes
+}
+
+// 是否tap本条新连接
+func flowShouldTap() bool {
+	return flowCaptureActive() && mathrand.Intn(100) < FlowCaptureSamplePct
+}
+
+// 写一条方向性记录 (>>>发送 <<<接收 ===生命周期事件)
+func flowRecord(id int64, dir string, payload []byte, note string) {
+	if flowOut == nil {
+		return
+	}
+	if len(payload) > 0 {
+		if !flowCaptureActive() {
+			return
+		}
+		atomic.AddInt64(&flowBytesCaptured, int64(len(payload)))
+	}
+	flowMu.Lock()
+	defer flowMu.Unlock()
+	fmt.Fprintf(flowOut, "%s conn=%d t=+%.3fs %d bytes %s\n",
+		dir, id, time.Since(flowStart).Seconds(), len(payload), note)
+	if len(payload) > 0 {
+		flowOut.WriteString(hex.Dump(payload))
+	}
+}
+
+// 被tap的连接: 读写路径上把明文字节抄送到捕获文件
+type flowTapConn struct {
+	net.Conn
+	id        int64
+	closeOnce sync.Once
+}
+
+func newFlowTap(conn net.Conn, layer string) *flowTapConn {
+	id := atomic.AddInt64(&flowConnSeq, 1)
+	atomic.AddInt64(&flowConnsTapped, 1)
+	flowRecord(id, "===", nil, fmt.Sprintf("open %s %v", layer, conn.RemoteAddr()))
+	return &flowTapConn{Conn: conn, id: id}
+}
+
+func (c *flowTapConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if n > 0 {
+		flowRecord(c.id, "<<<", p[:n], "")
+	}
+	return n, err
+}
+
+func (c *flowTapConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	if n > 0 {
+		flowRecord(c.id, ">>>", p[:n], "")
+	}
+	return n, err
+}
+
+func (c *flowTapConn) Close() error {
+	c.closeOnce.Do(func() {
+		flowRecord(c.id, "===", nil, "close")
+	})
+	return c.Conn.Close()
+}
+
+// TLS层的tap包装: 转发ConnectionState让Transport仍能识别协商出的h2
+type tlsFlowConn struct {
+	*flowTapConn
+	tconn *tls.Conn
+}
+
+func (c *tlsFlowConn) ConnectionState() tls.ConnectionState {
+	return c.tconn.ConnectionState()
+}
+
+// 包装明文拨号: 采样命中的http连接在TCP层tap
+func flowCaptureDial(dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil || !flowShouldTap() {
+			return conn, err
+		}
+		return newFlowTap(conn, "tcp"), nil
+	}
+}
+
+// 自定义TLS拨号: 自行完成握手后在解密层tap，未命中采样的连接原样返回
+func flowCaptureTLSDial(dial func(ctx context.Context, network, addr string) (net.Conn, error), base *tls.Config) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		raw, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		cfg := base.Clone()
+		if cfg.ServerName == "" {
+			if host, _, err := net.SplitHostPort(addr); err == nil {
+				cfg.ServerName = host
+			}
+		}
+		// Transport不再替我们做ALPN，按配置的HTTP版本自行声明
+		if len(cfg.NextProtos) == 0 {
+			if strings.Contains(HTTPVersions, "h2") {
+				cfg.NextProtos = []string{"h2", "http/1.1"}
+			} else {
+				cfg.NextProtos = []string{"http/1.1"}
+			}
+		}
+		tconn := tls.Client(raw, cfg)
+		if err := tconn.HandshakeContext(ctx); err != nil {
+			raw.Close()
+			return nil, err
+		}
+		if !flowShouldTap() {
+			return tconn, nil
+		}
+		return &tlsFlowConn{flowTapConn: newFlowTap(tconn, "tls"), tconn: tconn}, nil
+	}
+}
+
+// 供JSON报告使用
+func flowCaptureAnalysis() map[string]interface{} {
+	if FlowCaptureFile == "" {
+		return nil
+	}
+	return map[string]interface{}{
+		"file":           FlowCaptureFile,
+		"sample_pct":     FlowCaptureSamplePct,
+		"conns_tapped":   atomic.LoadInt64(&flowConnsTapped),
+		"bytes_captured": atomic.LoadInt64(&flowBytesCaptured),
+	}
+}
+
+// 打印流捕获报告
+func printFlowCaptureReport() {
+	if FlowCaptureFile == "" {
+		return
+	}
+	fmt.Printf("\n=== 流量采样捕获 ===\n")
+	fmt.Printf("文件: %s  连接: %d  字节: %.1fKB\n", FlowCaptureFile,
+		atomic.LoadInt64(&flowConnsTapped), float64(atomic.LoadInt64(&flowBytesCaptured))/1024)
+	if atomic.LoadInt64(&flowBytesCaptured) >= FlowCaptureMaxBytes {
+		fmt.Printf("已达捕获上限 %dMB，后续连接未再记录\n", FlowCaptureMaxBytes/1024/1024)
+	}
+}
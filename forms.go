@@ -0,0 +1,372 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	mathrand "math/rand"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// ===================================================================================
+// --- HTML表单发现与提交 (Form Discovery Mode) ---
+// 随机字节的写流量在真实应用里多半被校验层直接弹回，测不到业务逻辑；
+// 该模式先GET页面解析其中的<form>，按字段类型生成像样的值，
+// 再用表单声明的method/enctype提交，产生贴近真实用户的写流量
+// 用法: 配置form_page_url后替代常规HTTP模式
+// ===================================================================================
+
+// 非空启用表单模式
+var FormPageURL = ""
+
+// 页面中发现的一个表单
+type htmlForm struct {
+	Action  string // 解析后的绝对提交地址
+	Method  string // GET/POST
+	Enctype string
+	Fields  []formField
+
+	submits int64
+	failed  int64
+	mu      sync.Mutex
+	dur     []time.Duration
+}
+
+// 表单字段及其生成策略所需的信息
+type formField struct {
+	Name    string
+	Type    string   // text/email/tel/number/password/hidden/checkbox/textarea/select
+	Value   string   // 页面预填值 (hidden/csrf令牌等原样提交)
+	Options []string // select的候选项
+}
+
+var discoveredForms []*htmlForm
+
+// 抓取页面并解析全部表单
+func discoverForms(client *http.Client) error {
+	resp, err := client.Get(FormPageURL)
+	if err != nil {
+		return fmt.Errorf("无法抓取表单页面: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("表单页面返回 %s", resp.Status)
+	}
+
+	doc, err := html.Parse(resp.Body)
+	if err != nil {
+		return fmt.Errorf("解析HTML失败: %v", err)
+	}
+	pageURL, err := url.Parse(FormPageURL)
+	if err != nil {
+		return fmt.Errorf("无效的表单页面地址: %v", err)
+	}
+
+	walkHTML(doc, func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "form" {
+			if f := parseForm(n, pageURL); f != nil {
+				discoveredForms = append(discoveredForms, f)
+			}
+		}
+	})
+
+	if len(discoveredForms) == 0 {
+		return fmt.Errorf("页面 %s 中没有发现<form>元素", FormPageURL)
+	}
+	fmt.Printf("表单发现完成: %d 个表单\n", len(discoveredForms))
+	for _, f := range discoveredForms {
+		fmt.Printf("  %s %s (%d 个字段, %s)\n", f.Method, f.Action, len(f.Fields), f.Enctype)
+	}
+	return nil
+}
+
+// 深度优先遍历DOM
+func walkHTML(n *html.Node, visit func(*html.Node)) {
+	visit(n)
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walkHTML(c, visit)
+	}
+}
+
+func htmlAttr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// 解析单个<form>节点: 提交地址相对页面解析，method/enctype按HTML默认值兜底
+func parseForm(n *html.Node, pageURL *url.URL) *htmlForm {
+	f := &htmlForm{
+		Method:  strings.ToUpper(htmlAttr(n, "method")),
+		Enctype: strings.ToLower(htmlAttr(n, "enctype")),
+	}
+	if f.Method == "" {
+		f.Method = "GET"
+	}
+	if f.Enctype == "" {
+		f.Enctype = "application/x-www-form-urlencoded"
+	}
+
+	action := htmlAttr(n, "action")
+	ref, err := url.Parse(action)
+	if err != nil {
+		return nil
+	}
+	f.Action = pageURL.ResolveReference(ref).String()
+
+	walkHTML(n, func(c *html.Node) {
+		if c.Type != html.ElementNode {
+			return
+		}
+		switch c.Data {
+		case "input":
+			name := htmlAttr(c, "name")
+			inputType := strings.ToLower(htmlAttr(c, "type"))
+			if name == "" || inputType == "submit" || inputType == "button" || inputType == "file" {
+				return
+			}
+			if inputType == "" {
+				inputType = "text"
+			}
+			f.Fields = append(f.Fields, formField{Name: name, Type: inputType, Value: htmlAttr(c, "value")})
+		case "textarea":
+			if name := htmlAttr(c, "name"); name != "" {
+				f.Fields = append(f.Fields, formField{Name: name, Type: "textarea"})
+			}
+		case "select":
+			name := htmlAttr(c, "name")
+			if name == "" {
+				return
+			}
+			field := formField{Name: name, Type: "select"}
+			walkHTML(c, func(o *html.Node) {
+				if o.Type == html.ElementNode && o.Data == "option" {
+					if v := htmlAttr(o, "value"); v != "" {
+						field.Options = append(field.Options, v)
+					}
+				}
+			})
+			f.Fields = append(f.Fields, field)
+		}
+	})
+	return f
+}
+
+// 小写字母数字随机串
+func formRandomString(n int) string {
+	const chars = "abcdefghijklmnopqrstuvwxyz0123456789"
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = chars[mathrand.Intn(len(chars))]
+	}
+	return string(b)
+}
+
+// 按字段类型生成提交值; hidden保留页面预填值 (CSRF令牌之类)
+func formFieldValue(field formField) string {
+	switch field.Type {
+	case "hidden":
+		return field.Value
+	case "email":
+		return fakeEmail()
+	case "tel":
+		return fakePhone()
+	case "number", "range":
+		return fmt.Sprintf("%d", mathrand.Intn(1000))
+	case "password":
+		return formRandomString(12)
+	case "checkbox", "radio":
+		if field.Value != "" {
+			return field.Value
+		}
+		return "on"
+	case "date":
+		return time.Now().AddDate(0, 0, -mathrand.Intn(365)).Format("2006-01-02")
+	case "url":
+		return "https://example.com/" + formRandomString(8)
+	case "select":
+		if len(field.Options) > 0 {
+			return field.Options[mathrand.Intn(len(field.Options))]
+		}
+		return ""
+	case "textarea":
+		return fakeName() + " " + formRandomString(32)
+	default:
+		return fakeName()
+	}
+}
+
+// 构造一次提交请求
+func buildFormRequest(ctx context.Context, f *htmlForm) (*http.Request, error) {
+	values := url.Values{}
+	for _, field := range f.Fields {
+		values.Set(field.Name, formFieldValue(field))
+	}
+
+	if f.Method == "GET" {
+		target, err := url.Parse(f.Action)
+		if err != nil {
+			return nil, err
+		}
+		target.RawQuery = values.Encode()
+		return http.NewRequestWithContext(ctx, "GET", target.String(), nil)
+	}
+
+	// multipart表单逐字段写入，其余按urlencoded提交
+	if strings.HasPrefix(f.Enctype, "multipart/form-data") {
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		for key := range values {
+			mw.WriteField(key, values.Get(key))
+		}
+		mw.Close()
+		req, err := http.NewRequestWithContext(ctx, f.Method, f.Action, &buf)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		return req, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, f.Method, f.Action, strings.NewReader(values.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req, nil
+}
+
+// 表单模式入口: 先发现表单，再让协程轮转提交
+func runFormLoad(ctx context.Context, stats *Stats, client *http.Client, wg *sync.WaitGroup) {
+	if err := discoverForms(client); err != nil {
+		fmt.Printf("表单发现失败: %v\n", err)
+		return
+	}
+	fmt.Printf("表单提交模式: %d 个表单 x %d 并发\n", len(discoveredForms), NumConcurrentWorkers)
+
+	var dispatched int64
+	for i := 0; i < NumConcurrentWorkers; i++ {
+		wg.Add(1)
+		go func(workerID int) {
+			defer wg.Done()
+			for iter := 0; ; iter++ {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+				}
+				if TestDuration <= 0 && atomic.AddInt64(&dispatched, 1) > int64(TotalDownloads) {
+					return
+				}
+				if !testDeadline.IsZero() && time.Now().After(testDeadline) {
+					return
+				}
+				waitWhilePaused(ctx)
+
+				form := discoveredForms[(workerID+iter)%len(discoveredForms)]
+				submitForm(ctx, form, stats, client)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+// 提交一次表单
+func submitForm(ctx context.Context, f *htmlForm, stats *Stats, client *http.Client) {
+	atomic.AddInt64(&f.submits, 1)
+	atomic.AddInt64(&stats.TotalRequests, 1)
+	start := time.Now()
+
+	req, err := buildFormRequest(ctx, f)
+	if err != nil {
+		atomic.AddInt64(&f.failed, 1)
+		atomic.AddInt64(&stats.FailedRequests, 1)
+		recordError(stats, "表单请求构建失败")
+		return
+	}
+	req.Header.Set("User-Agent", generateRandomUserAgent())
+	req.Header.Set(MarkerHeaderName, MarkerHeaderValue)
+
+	resp, err := client.Do(req)
+	duration := time.Since(start)
+	if err != nil {
+		atomic.AddInt64(&f.failed, 1)
+		atomic.AddInt64(&stats.FailedRequests, 1)
+		recordError(stats, "表单提交失败")
+		return
+	}
+	defer resp.Body.Close()
+	io.CopyN(io.Discard, resp.Body, 64*1024)
+
+	atomic.AddInt64(&stats.TotalResponses, 1)
+	recordResponseTime(stats, duration)
+	if resp.StatusCode != 200 {
+		atomic.AddInt64(&stats.Non200Responses, 1)
+	}
+	if resp.StatusCode >= 400 {
+		atomic.AddInt64(&f.failed, 1)
+		recordError(stats, fmt.Sprintf("表单HTTP_%d", resp.StatusCode))
+	}
+	f.mu.Lock()
+	if len(f.dur) < ScenarioSampleCap {
+		f.dur = append(f.dur, duration)
+	}
+	f.mu.Unlock()
+}
+
+// 供JSON报告使用的按表单统计
+func formAnalysis() []map[string]interface{} {
+	if len(discoveredForms) == 0 {
+		return nil
+	}
+	var rows []map[string]interface{}
+	for _, f := range discoveredForms {
+		row := map[string]interface{}{
+			"action":  f.Action,
+			"method":  f.Method,
+			"enctype": f.Enctype,
+			"fields":  len(f.Fields),
+			"submits": atomic.LoadInt64(&f.submits),
+			"failed":  atomic.LoadInt64(&f.failed),
+		}
+		f.mu.Lock()
+		if len(f.dur) > 0 {
+			_, p50, p95, p99 := durationPercentiles(f.dur)
+			row["p50_ms"], row["p95_ms"], row["p99_ms"] = p50, p95, p99
+		}
+		f.mu.Unlock()
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// 打印各表单的独立统计
+func printFormReport() {
+	if len(discoveredForms) == 0 {
+		return
+	}
+	fmt.Printf("\n=== 表单提交统计 ===\n")
+	for _, f := range discoveredForms {
+		fmt.Printf("%s %s: 提交 %d 失败 %d", f.Method, f.Action,
+			atomic.LoadInt64(&f.submits), atomic.LoadInt64(&f.failed))
+		f.mu.Lock()
+		if len(f.dur) > 0 {
+			_, p50, p95, _ := durationPercentiles(f.dur)
+			fmt.Printf("  P50=%.1fms P95=%.1fms", p50, p95)
+		}
+		f.mu.Unlock()
+		fmt.Println()
+	}
+}
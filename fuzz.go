@@ -0,0 +1,333 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	mathrand "math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ===================================================================================
+// --- 健壮性测试 (Fuzz/Negative Testing) ---
+// 按配置比例发送畸形输入，观察服务端是 400 拒绝、500 崩溃还是超时
+// ===================================================================================
+
+var (
+	EnableFuzzing = false
+	FuzzRatio     = 0.05 // 被模糊化的请求比例 (0.0 - 1.0)
+
+	// 请求头大小压力测试: 探测服务端的头部大小限制
+	EnableHeaderStress   = false
+	HeaderStressRatio    = 0.05 // 参与头部压测的请求比例
+	HeaderStressMaxKB    = 64   // 附加头部的最大总大小 (KB)
+	HeaderStressCount    = 8    // 附加头部的个数
+
+	// URL长度压力测试: 探测服务端/代理的URL长度限制
+	EnableURLStress    = false
+	URLStressRatio     = 0.05  // 参与URL压测的请求比例
+	URLStressMaxLength = 16384 // 生成URL的最大长度 (字节)
+)
+
+// 模糊变异类型
+var fuzzMutations = []string{
+	"oversized_header",
+	"invalid_utf8_body",
+	"wrong_content_type",
+	"truncated_json",
+}
+
+// 判断当前请求是否应被模糊化
+func shouldFuzz() bool {
+	return EnableFuzzing && mathrand.Float64() < FuzzRatio
+}
+
+// 发送一个畸形请求并记录服务端的响应类别
+func makeFuzzedHTTPRequest(client *http.Client, method, url string, payload []byte, headers map[string]string, stats *Stats) {
+	mutation := fuzzMutations[mathrand.Intn(len(fuzzMutations))]
+
+	var bodyReader io.Reader
+	contentType := ""
+
+	switch mutation {
+	case "oversized_header":
+		// 单个超大请求头 (8KB 随机内容)
+		headers["X-Fuzz-Oversized"] = strings.Repeat("A", 8*1024)
+
+	case "invalid_utf8_body":
+		// 合法JSON结构中混入非法UTF-8字节序列
+		body := append([]byte(`{"data":"`), 0xff, 0xfe, 0xc0, 0x80)
+		body = append(body, []byte(`"}`)...)
+		bodyReader = bytes.NewReader(body)
+		contentType = "application/json; charset=utf-8"
+		if method == "GET" || method == "HEAD" {
+			method = "POST"
+		}
+
+	case "wrong_content_type":
+		// 声明JSON但发送二进制垃圾
+		garbage := make([]byte, mathrand.Intn(256)+64)
+		mathrand.Read(garbage)
+		bodyReader = bytes.NewReader(garbage)
+		contentType = "application/json"
+		if method == "GET" || method == "HEAD" {
+			method = "POST"
+		}
+
+	case "truncated_json":
+		// 在中途截断的JSON文档
+		full := fmt.Sprintf(`{"id":%d,"name":"%s","items":[1,2,3`, mathrand.Int63(), fakeName())
+		bodyReader = strings.NewReader(full)
+		contentType = "application/json"
+		if method == "GET" || method == "HEAD" {
+			method = "POST"
+		}
+	}
+
+	req, err := http.NewRequest(method, url, bodyReader)
+	if err != nil {
+		atomic.AddInt64(&stats.FailedRequests, 1)
+		recordError(stats, "Fuzz请求创建失败")
+		return
+	}
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	startTime := time.Now()
+	resp, err := client.Do(req)
+	requestDuration := time.Since(startTime)
+
+	atomic.AddInt64(&stats.TotalRequests, 1)
+	atomic.AddInt64(&stats.FuzzRequests, 1)
+
+	if err != nil {
+		atomic.AddInt64(&stats.FailedRequests, 1)
+		if strings.Contains(err.Error(), "timeout") {
+			atomic.AddInt64(&stats.TimeoutCount, 1)
+			recordFuzzOutcome(stats, mutation, "timeout")
+		} else if strings.Contains(err.Error(), "reset") || strings.Contains(err.Error(), "EOF") {
+			recordFuzzOutcome(stats, mutation, "connection_reset")
+		} else {
+			recordFuzzOutcome(stats, mutation, "other_error")
+		}
+		return
+	}
+	defer resp.Body.Close()
+
+	recordResponseTime(stats, requestDuration)
+	io.Copy(io.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode >= 500:
+		recordFuzzOutcome(stats, mutation, "5xx")
+	case resp.StatusCode >= 400:
+		recordFuzzOutcome(stats, mutation, "4xx")
+	default:
+		// 服务端接受了畸形输入，同样值得关注
+		recordFuzzOutcome(stats, mutation, "accepted")
+	}
+}
+
+// 判断当前请求是否应参与头部大小压测
+func shouldHeaderStress() bool {
+	return EnableHeaderStress && mathrand.Float64() < HeaderStressRatio
+}
+
+// 附加指定总大小的额外请求头并发送，记录各大小档位下服务端的反应
+// 用于定位 431/400 或连接重置开始出现的头部大小
+func makeHeaderStressRequest(client *http.Client, url string, headers map[string]string, stats *Stats) {
+	// 随机选择大小档位: 1KB 到 HeaderStressMaxKB，按2的幂分布
+	sizeKB := 1 << mathrand.Intn(bitsForKB(HeaderStressMaxKB)+1)
+	if sizeKB > HeaderStressMaxKB {
+		sizeKB = HeaderStressMaxKB
+	}
+
+	count := HeaderStressCount
+	if count <= 0 {
+		count = 1
+	}
+	perHeader := sizeKB * 1024 / count
+	if perHeader < 1 {
+		perHeader = 1
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		atomic.AddInt64(&stats.FailedRequests, 1)
+		recordError(stats, "头部压测请求创建失败")
+		return
+	}
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	filler := strings.Repeat("x", perHeader)
+	for i := 0; i < count; i++ {
+		req.Header.Set(fmt.Sprintf("X-Stress-%d", i), filler)
+	}
+
+	startTime := time.Now()
+	resp, err := client.Do(req)
+	requestDuration := time.Since(startTime)
+
+	atomic.AddInt64(&stats.TotalRequests, 1)
+	bucket := fmt.Sprintf("%dKB", sizeKB)
+
+	if err != nil {
+		atomic.AddInt64(&stats.FailedRequests, 1)
+		if strings.Contains(err.Error(), "timeout") {
+			atomic.AddInt64(&stats.TimeoutCount, 1)
+			recordHeaderStressResult(stats, bucket, "timeout")
+		} else if strings.Contains(err.Error(), "reset") || strings.Contains(err.Error(), "EOF") {
+			recordHeaderStressResult(stats, bucket, "connection_reset")
+		} else {
+			recordHeaderStressResult(stats, bucket, "other_error")
+		}
+		return
+	}
+	defer resp.Body.Close()
+
+	recordResponseTime(stats, requestDuration)
+	io.Copy(io.Discard, resp.Body)
+	recordHeaderStressResult(stats, bucket, fmt.Sprintf("HTTP_%d", resp.StatusCode))
+}
+
+// 计算不超过maxKB的最大2的幂指数
+func bitsForKB(maxKB int) int {
+	bits := 0
+	for (1 << (bits + 1)) <= maxKB {
+		bits++
+	}
+	return bits
+}
+
+// 记录头部压测各档位的结果
+func recordHeaderStressResult(stats *Stats, bucket, outcome string) {
+	stats.mu.Lock()
+	if stats.HeaderStressResults == nil {
+		stats.HeaderStressResults = make(map[string]int64)
+	}
+	stats.HeaderStressResults[bucket+"/"+outcome]++
+	stats.mu.Unlock()
+}
+
+// 判断当前请求是否应参与URL长度压测
+func shouldURLStress() bool {
+	return EnableURLStress && mathrand.Float64() < URLStressRatio
+}
+
+// 发送超长URL请求，按URL长度档位记录服务端的状态码或连接级错误
+// 用于定位服务端/代理 (414/400/重置) 开始出现的URL长度
+func makeURLStressRequest(client *http.Client, baseURL string, headers map[string]string, stats *Stats) {
+	// 随机选择长度档位: 512 到 URLStressMaxLength，按2的幂分布
+	length := 512 << mathrand.Intn(bitsForKB(URLStressMaxLength/512)+1)
+	if length > URLStressMaxLength {
+		length = URLStressMaxLength
+	}
+
+	// 一半填充到路径，一半填充到查询串
+	const chars = "abcdefghijklmnopqrstuvwxyz0123456789"
+	filler := make([]byte, length/2)
+	for i := range filler {
+		filler[i] = chars[mathrand.Intn(len(chars))]
+	}
+
+	target := baseURL
+	if parsed, err := url.Parse(baseURL); err == nil {
+		parsed.Path = strings.TrimSuffix(parsed.Path, "/") + "/" + string(filler)
+		parsed.RawQuery = "q=" + string(filler)
+		target = parsed.String()
+	}
+
+	req, err := http.NewRequest("GET", target, nil)
+	if err != nil {
+		atomic.AddInt64(&stats.FailedRequests, 1)
+		recordError(stats, "URL压测请求创建失败")
+		return
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	startTime := time.Now()
+	resp, err := client.Do(req)
+	requestDuration := time.Since(startTime)
+
+	atomic.AddInt64(&stats.TotalRequests, 1)
+	bucket := fmt.Sprintf("%dB", length)
+
+	if err != nil {
+		atomic.AddInt64(&stats.FailedRequests, 1)
+		if strings.Contains(err.Error(), "timeout") {
+			atomic.AddInt64(&stats.TimeoutCount, 1)
+			recordURLStressResult(stats, bucket, "timeout")
+		} else if strings.Contains(err.Error(), "reset") || strings.Contains(err.Error(), "EOF") {
+			recordURLStressResult(stats, bucket, "connection_reset")
+		} else {
+			recordURLStressResult(stats, bucket, "other_error")
+		}
+		return
+	}
+	defer resp.Body.Close()
+
+	recordResponseTime(stats, requestDuration)
+	io.Copy(io.Discard, resp.Body)
+	recordURLStressResult(stats, bucket, fmt.Sprintf("HTTP_%d", resp.StatusCode))
+}
+
+// 记录URL长度压测各档位的结果
+func recordURLStressResult(stats *Stats, bucket, outcome string) {
+	stats.mu.Lock()
+	if stats.URLStressResults == nil {
+		stats.URLStressResults = make(map[string]int64)
+	}
+	stats.URLStressResults[bucket+"/"+outcome]++
+	stats.mu.Unlock()
+}
+
+// 记录模糊变异的服务端响应类别
+func recordFuzzOutcome(stats *Stats, mutation, outcome string) {
+	stats.mu.Lock()
+	if stats.FuzzOutcomes == nil {
+		stats.FuzzOutcomes = make(map[string]int64)
+	}
+	stats.FuzzOutcomes[mutation+"/"+outcome]++
+	stats.mu.Unlock()
+}
+
+// 打印健壮性测试结果
+func printFuzzReport(stats *StatsSnapshot) {
+
+	if len(stats.FuzzOutcomes) == 0 && len(stats.HeaderStressResults) == 0 && len(stats.URLStressResults) == 0 {
+		return
+	}
+
+	fmt.Printf("\n=== 健壮性测试结果 ===\n")
+	if len(stats.FuzzOutcomes) > 0 {
+		fmt.Printf("模糊请求总数: %d\n", stats.FuzzRequests)
+		for key, count := range stats.FuzzOutcomes {
+			fmt.Printf("%s: %d\n", key, count)
+		}
+	}
+	if len(stats.HeaderStressResults) > 0 {
+		fmt.Printf("--- 头部大小压测 (大小档位/结果) ---\n")
+		for key, count := range stats.HeaderStressResults {
+			fmt.Printf("%s: %d\n", key, count)
+		}
+	}
+	if len(stats.URLStressResults) > 0 {
+		fmt.Printf("--- URL长度压测 (长度档位/结果) ---\n")
+		for key, count := range stats.URLStressResults {
+			fmt.Printf("%s: %d\n", key, count)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ===================================================================================
+// --- HAR回放 (HAR Replay) ---
+// 把浏览器导出的HAR文件转成请求场景 (URL、方法、头、请求体和时间间隔)，
+// 按可配置的倍速和并发回放真实用户流量，替代纯随机生成
+// ===================================================================================
+
+var (
+	// HAR文件路径，非空启用回放模式
+	HARFile = ""
+	// 回放倍速: 2.0表示时间间隔压缩到一半，0按原始节奏
+	HARSpeed = 1.0
+)
+
+// 相邻请求的间隔上限，浏览器会话里动辄几十秒的停顿没必要照搬
+const harMaxGap = 10 * time.Second
+
+// 场景中的一条请求 (gap为与上一条的时间间隔)
+type harEntry struct {
+	method  string
+	url     string
+	headers map[string]string
+	body    []byte
+	gap     time.Duration
+}
+
+var harEntries []harEntry
+
+// HAR文件的最小结构 (只取回放需要的字段)
+type harLog struct {
+	Log struct {
+		Entries []struct {
+			StartedDateTime string `json:"startedDateTime"`
+			Request         struct {
+				Method  string `json:"method"`
+				URL     string `json:"url"`
+				Headers []struct {
+					Name  string `json:"name"`
+					Value string `json:"value"`
+				} `json:"headers"`
+				PostData struct {
+					Text string `json:"text"`
+				} `json:"postData"`
+			} `json:"request"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+// 解析HAR文件为回放场景，同时返回去重后的目标URL列表 (供允许名单检查使用)
+func loadHARScenario() error {
+	data, err := os.ReadFile(HARFile)
+	if err != nil {
+		return fmt.Errorf("无法读取HAR文件: %v", err)
+	}
+	var har harLog
+	if err := json.Unmarshal(data, &har); err != nil {
+		return fmt.Errorf("解析HAR文件失败: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	var prevStart time.Time
+	for _, e := range har.Log.Entries {
+		rawURL := e.Request.URL
+		if !strings.HasPrefix(rawURL, "http://") && !strings.HasPrefix(rawURL, "https://") {
+			continue
+		}
+
+		entry := harEntry{method: e.Request.Method, url: rawURL}
+		if entry.method == "" {
+			entry.method = "GET"
+		}
+		for _, h := range e.Request.Headers {
+			// 跳过HTTP/2伪头和由传输层自己计算的头
+			if strings.HasPrefix(h.Name, ":") {
+				continue
+			}
+			switch strings.ToLower(h.Name) {
+			case "content-length", "host", "connection":
+				continue
+			}
+			if entry.headers == nil {
+				entry.headers = make(map[string]string)
+			}
+			entry.headers[h.Name] = h.Value
+		}
+		if e.Request.PostData.Text != "" {
+			entry.body = []byte(e.Request.PostData.Text)
+		}
+
+		// 与上一条的间隔来自startedDateTime差值，解析失败按0处理
+		if start, err := time.Parse(time.RFC3339Nano, e.StartedDateTime); err == nil {
+			if !prevStart.IsZero() && start.After(prevStart) {
+				entry.gap = start.Sub(prevStart)
+				if entry.gap > harMaxGap {
+					entry.gap = harMaxGap
+				}
+			}
+			prevStart = start
+		}
+
+		harEntries = append(harEntries, entry)
+		if !seen[rawURL] {
+			seen[rawURL] = true
+			TargetURLs = append(TargetURLs, rawURL)
+		}
+	}
+
+	if len(harEntries) == 0 {
+		return fmt.Errorf("HAR文件中没有找到有效的HTTP请求")
+	}
+	log.Printf("成功从 %s 加载 %d 条请求 (%d 个目标URL)", HARFile, len(harEntries), len(TargetURLs))
+	return nil
+}
+
+// 回放主循环: 每个协程独立循环整个场景并按倍速还原时间间隔，
+// 时长模式跑到截止时间，计数模式由全局配额封顶
+func runHARReplay(ctx context.Context, stats *Stats, httpClient *http.Client, wg *sync.WaitGroup) {
+	var dispatched int64
+	fmt.Printf("场景回放: %d 条请求 x %d 并发，倍速 %.1fx\n", len(harEntries), NumConcurrentWorkers, HARSpeed)
+
+	for i := 0; i < NumConcurrentWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				for _, entry := range harEntries {
+					select {
+					case <-ctx.Done():
+						return
+					default:
+					}
+					if TestDuration <= 0 && atomic.AddInt64(&dispatched, 1) > int64(TotalDownloads) {
+						return
+					}
+					if !testDeadline.IsZero() && time.Now().After(testDeadline) {
+						return
+					}
+
+					if gap := harReplayGap(entry.gap); gap > 0 {
+						select {
+						case <-time.After(gap):
+						case <-ctx.Done():
+							return
+						}
+					}
+					waitWhilePaused(ctx)
+					makeHTTPRequest(ctx, httpClient, entry.method, entry.url, entry.body, entry.headers, stats, activeTestMode())
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// 按倍速换算回放间隔
+func harReplayGap(gap time.Duration) time.Duration {
+	if gap <= 0 || HARSpeed <= 0 {
+		return 0
+	}
+	return time.Duration(float64(gap) / HARSpeed)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// ===================================================================================
+// --- 滚动延迟直方图 (Rolling Latency Histogram) ---
+// 按秒分片的对数桶直方图，运行中即可给出最近窗口的 P50/P95/P99，无需等到结束
+// ===================================================================================
+
+const (
+	histogramBuckets = 64
+	// 桶边界: bucket i 覆盖 [minLatency * growth^i, minLatency * growth^(i+1))
+	histogramMinMs   = 0.1
+	histogramGrowth  = 1.25
+	rollingSlices    = 16 // 按秒分片的环形槽位数
+	RollingWindowSec = 10 // 计算百分位使用的最近窗口 (秒)
+)
+
+// 单秒的延迟直方图分片
+type histogramSlice struct {
+	mu      sync.Mutex
+	stamp   int64 // 该分片对应的unix秒
+	buckets [histogramBuckets]int64
+	count   int64
+}
+
+// 滚动窗口直方图: 环形复用按秒分片
+type RollingHistogram struct {
+	slices [rollingSlices]histogramSlice
+}
+
+var rollingLatency = &RollingHistogram{}
+
+// 延迟到桶下标
+func latencyBucket(d time.Duration) int {
+	ms := float64(d.Nanoseconds()) / 1e6
+	if ms <= histogramMinMs {
+		return 0
+	}
+	idx := int(math.Log(ms/histogramMinMs) / math.Log(histogramGrowth))
+	if idx >= histogramBuckets {
+		return histogramBuckets - 1
+	}
+	return idx
+}
+
+// 桶下标还原为桶上界 (毫秒)，用于输出近似百分位值
+func bucketUpperMs(idx int) float64 {
+	return histogramMinMs * math.Pow(histogramGrowth, float64(idx+1))
+}
+
+// 记录一次延迟到当前秒的分片
+func (h *RollingHistogram) Record(d time.Duration) {
+	now := time.Now().Unix()
+	slice := &h.slices[now%rollingSlices]
+
+	slice.mu.Lock()
+	if slice.stamp != now {
+		// 环形槽位被复用，清空旧秒数据
+		slice.stamp = now
+		slice.buckets = [histogramBuckets]int64{}
+		slice.count = 0
+	}
+	slice.buckets[latencyBucket(d)]++
+	slice.count++
+	slice.mu.Unlock()
+}
+
+// 合并最近windowSec秒的分片并计算 P50/P95/P99 (毫秒)，样本不足时ok为false
+func (h *RollingHistogram) Percentiles(windowSec int) (p50, p95, p99 float64, ok bool) {
+	now := time.Now().Unix()
+	var merged [histogramBuckets]int64
+	var total int64
+
+	for i := 0; i < rollingSlices; i++ {
+		slice := &h.slices[i]
+		slice.mu.Lock()
+		if now-slice.stamp < int64(windowSec) {
+			for b, c := range slice.buckets {
+				merged[b] += c
+			}
+			total += slice.count
+		}
+		slice.mu.Unlock()
+	}
+
+	if total == 0 {
+		return 0, 0, 0, false
+	}
+
+	percentile := func(q float64) float64 {
+		target := int64(math.Ceil(q * float64(total)))
+		var cum int64
+		for b, c := range merged {
+			cum += c
+			if cum >= target {
+				return bucketUpperMs(b)
+			}
+		}
+		return bucketUpperMs(histogramBuckets - 1)
+	}
+
+	return percentile(0.50), percentile(0.95), percentile(0.99), true
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http/httptrace"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ===================================================================================
+// --- 按解析IP的延迟分解 (Per-Resolved-IP Latency Breakdown) ---
+// anycast/CDN域名会解析到多个IP，整体百分位会把慢POP和快POP混在一起；
+// 通过httptrace记下每个请求实际命中的远端IP，分别统计延迟并附反向DNS注释，
+// 便于定位负载均衡后面哪个节点慢
+// ===================================================================================
+
+var EnableIPBreakdown = true
+
+// 每个IP最多保留的延迟样本数 / 最多跟踪的不同IP数
+const (
+	maxIPSamples    = 20000
+	maxTrackedIPs   = 64
+	maxPTRLookups   = 20
+	ptrLookupTimout = 500 * time.Millisecond
+)
+
+type ipLatencyCollector struct {
+	mu      sync.Mutex
+	samples map[string][]time.Duration
+}
+
+var ipLatency = &ipLatencyCollector{samples: make(map[string][]time.Duration)}
+
+// 单个请求命中的远端IP，由GotConnInfo回调写入 (回调happens-before Do返回，无需加锁)
+type ipHolder struct {
+	ip string
+}
+
+// 为一次请求注册远端IP捕获hook (与其他trace自动组合)
+func withIPTrace(ctx context.Context) (context.Context, *ipHolder) {
+	holder := &ipHolder{}
+	if !EnableIPBreakdown {
+		return ctx, holder
+	}
+
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if addr := info.Conn.RemoteAddr(); addr != nil {
+				if host, _, err := net.SplitHostPort(addr.String()); err == nil {
+					holder.ip = host
+				}
+			}
+		},
+	}
+	return httptrace.WithClientTrace(ctx, trace), holder
+}
+
+// 记录一次请求的延迟到其命中的IP桶
+func recordIPLatency(holder *ipHolder, d time.Duration) {
+	if !EnableIPBreakdown || holder.ip == "" {
+		return
+	}
+
+	ipLatency.mu.Lock()
+	defer ipLatency.mu.Unlock()
+
+	bucket, ok := ipLatency.samples[holder.ip]
+	if !ok && len(ipLatency.samples) >= maxTrackedIPs {
+		return
+	}
+	if len(bucket) < maxIPSamples {
+		ipLatency.samples[holder.ip] = append(bucket, d)
+	}
+}
+
+// 对IP做反向DNS以标注POP/机房 (数量有限、短超时，失败不影响报告；
+// ASN标注需要离线数据库，这里仅提供PTR记录)
+func reverseDNSAnnotations(ips []string) map[string]string {
+	names := make(map[string]string)
+	resolver := &net.Resolver{}
+	for i, ip := range ips {
+		if i >= maxPTRLookups {
+			break
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), ptrLookupTimout)
+		ptrs, err := resolver.LookupAddr(ctx, ip)
+		cancel()
+		if err == nil && len(ptrs) > 0 {
+			names[ip] = strings.TrimSuffix(ptrs[0], ".")
+		}
+	}
+	return names
+}
+
+// 打印按解析IP分组的延迟统计
+func printIPLatencyReport() {
+	ipLatency.mu.Lock()
+	grouped := make(map[string][]time.Duration, len(ipLatency.samples))
+	for ip, samples := range ipLatency.samples {
+		grouped[ip] = append([]time.Duration(nil), samples...)
+	}
+	ipLatency.mu.Unlock()
+
+	if len(grouped) == 0 {
+		return
+	}
+
+	ips := make([]string, 0, len(grouped))
+	for ip := range grouped {
+		ips = append(ips, ip)
+	}
+	// 按样本数降序，主要流量的IP排前面
+	sort.Slice(ips, func(i, j int) bool { return len(grouped[ips[i]]) > len(grouped[ips[j]]) })
+	names := reverseDNSAnnotations(ips)
+
+	fmt.Printf("\n=== 按解析IP的延迟分解 (%d 个IP) ===\n", len(ips))
+	for _, ip := range ips {
+		avg, p50, p95, p99 := durationPercentiles(grouped[ip])
+		label := ip
+		if name, ok := names[ip]; ok {
+			label = fmt.Sprintf("%s (%s)", ip, name)
+		}
+		fmt.Printf("%s: %d 次 | 平均: %.1fms | P50/P95/P99: %.1f/%.1f/%.1fms\n",
+			label, len(grouped[ip]), avg, p50, p95, p99)
+	}
+	if len(ips) > 1 {
+		fmt.Printf("提示: 各IP百分位差距明显时说明LB后端/POP性能不均\n")
+	}
+}
+
+// 按IP延迟的JSON报告片段
+func ipLatencyAnalysis() map[string]interface{} {
+	ipLatency.mu.Lock()
+	defer ipLatency.mu.Unlock()
+
+	if len(ipLatency.samples) == 0 {
+		return nil
+	}
+
+	ips := make([]string, 0, len(ipLatency.samples))
+	for ip := range ipLatency.samples {
+		ips = append(ips, ip)
+	}
+	names := reverseDNSAnnotations(ips)
+
+	analysis := make(map[string]interface{}, len(ips))
+	for ip, samples := range ipLatency.samples {
+		avg, p50, p95, p99 := durationPercentiles(samples)
+		entry := map[string]interface{}{
+			"count": len(samples), "avg_ms": avg, "p50_ms": p50, "p95_ms": p95, "p99_ms": p99,
+		}
+		if name, ok := names[ip]; ok {
+			entry["reverse_dns"] = name
+		}
+		analysis[ip] = entry
+	}
+	return analysis
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// ===================================================================================
+// --- 响应Schema校验 (Response JSON Schema Validation) ---
+// 按URL前缀挂接JSON Schema，对采样的响应体做校验，
+// schema违例作为独立的失败类别计入统计和报告；
+// 校验器为自实现的常用子集 (type/properties/required/items/enum/范围/长度)，不引入新依赖
+// ===================================================================================
+
+// 响应体采样校验的百分比
+var SchemaSamplePct = 10
+
+// 一条schema规则
+type responseSchema struct {
+	prefix     string
+	schema     map[string]interface{}
+	checked    int64
+	violations int64
+}
+
+var responseSchemas []*responseSchema
+
+// 加载配置的schema文件 (URL前缀 -> 文件路径)
+func initResponseSchemas(mapping map[string]string) error {
+	for prefix, path := range mapping {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("无法读取schema文件 %s: %v", path, err)
+		}
+		var schema map[string]interface{}
+		if err := json.Unmarshal(data, &schema); err != nil {
+			return fmt.Errorf("解析schema文件 %s 失败: %v", path, err)
+		}
+		responseSchemas = append(responseSchemas, &responseSchema{prefix: prefix, schema: schema})
+	}
+	return nil
+}
+
+// 本次请求是否需要schema校验 (命中规则且落入采样)
+func schemaForURL(url string) *responseSchema {
+	for _, rule := range responseSchemas {
+		if strings.HasPrefix(url, rule.prefix) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// 校验响应体并记录违例
+func validateResponseSchema(stats *Stats, rule *responseSchema, url string, body []byte) {
+	atomic.AddInt64(&rule.checked, 1)
+
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		schemaViolation(stats, rule, url, "响应不是合法JSON: "+err.Error())
+		return
+	}
+	if msg := checkJSONSchema(doc, rule.schema, "$"); msg != "" {
+		schemaViolation(stats, rule, url, msg)
+	}
+}
+
+func schemaViolation(stats *Stats, rule *responseSchema, url, msg string) {
+	atomic.AddInt64(&rule.violations, 1)
+	atomic.AddInt64(&stats.SchemaViolations, 1)
+	recordError(stats, "Schema校验失败")
+	recordErrorSample(stats, "Schema校验失败", url, msg, 0)
+}
+
+// 递归校验，返回第一条违例描述 (空串为通过)
+func checkJSONSchema(doc interface{}, schema map[string]interface{}, path string) string {
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		for _, allowed := range enum {
+			if fmt.Sprintf("%v", doc) == fmt.Sprintf("%v", allowed) {
+				return ""
+			}
+		}
+		return fmt.Sprintf("%s: 值不在enum允许范围内", path)
+	}
+
+	schemaType, _ := schema["type"].(string)
+	switch schemaType {
+	case "object":
+		obj, ok := doc.(map[string]interface{})
+		if !ok {
+			return fmt.Sprintf("%s: 期望object", path)
+		}
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, r := range required {
+				name, _ := r.(string)
+				if _, present := obj[name]; !present {
+					return fmt.Sprintf("%s: 缺少必填字段 %q", path, name)
+				}
+			}
+		}
+		if props, ok := schema["properties"].(map[string]interface{}); ok {
+			for name, propSchema := range props {
+				value, present := obj[name]
+				if !present {
+					continue
+				}
+				ps, ok := propSchema.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if msg := checkJSONSchema(value, ps, path+"."+name); msg != "" {
+					return msg
+				}
+			}
+		}
+	case "array":
+		arr, ok := doc.([]interface{})
+		if !ok {
+			return fmt.Sprintf("%s: 期望array", path)
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range arr {
+				if msg := checkJSONSchema(item, items, fmt.Sprintf("%s[%d]", path, i)); msg != "" {
+					return msg
+				}
+			}
+		}
+	case "string":
+		s, ok := doc.(string)
+		if !ok {
+			return fmt.Sprintf("%s: 期望string", path)
+		}
+		if minLen, ok := schema["minLength"].(float64); ok && len(s) < int(minLen) {
+			return fmt.Sprintf("%s: 长度小于minLength %d", path, int(minLen))
+		}
+		if maxLen, ok := schema["maxLength"].(float64); ok && len(s) > int(maxLen) {
+			return fmt.Sprintf("%s: 长度超过maxLength %d", path, int(maxLen))
+		}
+	case "integer", "number":
+		n, ok := doc.(float64)
+		if !ok {
+			return fmt.Sprintf("%s: 期望%s", path, schemaType)
+		}
+		if schemaType == "integer" && n != float64(int64(n)) {
+			return fmt.Sprintf("%s: 期望integer", path)
+		}
+		if minVal, ok := schema["minimum"].(float64); ok && n < minVal {
+			return fmt.Sprintf("%s: 小于minimum %g", path, minVal)
+		}
+		if maxVal, ok := schema["maximum"].(float64); ok && n > maxVal {
+			return fmt.Sprintf("%s: 大于maximum %g", path, maxVal)
+		}
+	case "boolean":
+		if _, ok := doc.(bool); !ok {
+			return fmt.Sprintf("%s: 期望boolean", path)
+		}
+	case "null":
+		if doc != nil {
+			return fmt.Sprintf("%s: 期望null", path)
+		}
+	}
+	return ""
+}
+
+// 打印各规则的校验情况
+func printSchemaReport() {
+	if len(responseSchemas) == 0 {
+		return
+	}
+
+	fmt.Printf("\n=== 响应Schema校验 ===\n")
+	for _, rule := range responseSchemas {
+		checked := atomic.LoadInt64(&rule.checked)
+		violations := atomic.LoadInt64(&rule.violations)
+		fmt.Printf("%s: 采样 %d 次 | 违例 %d", rule.prefix, checked, violations)
+		if checked > 0 {
+			fmt.Printf(" (%.1f%%)", float64(violations)/float64(checked)*100)
+		}
+		fmt.Printf("\n")
+	}
+}
+
+// 供JSON报告使用的校验数据
+func schemaAnalysis() []map[string]interface{} {
+	if len(responseSchemas) == 0 {
+		return nil
+	}
+	var rows []map[string]interface{}
+	for _, rule := range responseSchemas {
+		rows = append(rows, map[string]interface{}{
+			"prefix":     rule.prefix,
+			"checked":    atomic.LoadInt64(&rule.checked),
+			"violations": atomic.LoadInt64(&rule.violations),
+		})
+	}
+	return rows
+}
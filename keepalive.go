@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	mathrand "math/rand"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ===================================================================================
+// --- 空闲期保活探测 (Idle Keep-Alive Probes) ---
+// 阶段化负载中协程数为0的空闲阶段会让连接池和会话冷却，
+// 配置低速率探测可在阶段间维持连接温热；探测流量独立计数，不混入压测统计
+// ===================================================================================
+
+// 空闲阶段每秒探测次数，0不探测
+var IdleProbeRate float64
+
+var idleProbeCount int64
+var idleProbeFails int64
+
+var idleProbeMu sync.Mutex
+var idleProbeDur []time.Duration
+
+// 空闲阶段启动保活探测循环，返回停止函数；未启用时返回nil
+func startIdleProbes(ctx context.Context, httpClient *http.Client) context.CancelFunc {
+	if IdleProbeRate <= 0 {
+		return nil
+	}
+	probeCtx, cancel := context.WithCancel(ctx)
+	interval := time.Duration(float64(time.Second) / IdleProbeRate)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-probeCtx.Done():
+				return
+			case <-ticker.C:
+				sendIdleProbe(probeCtx, httpClient)
+			}
+		}
+	}()
+	return cancel
+}
+
+// 发送一次保活探测: 轻量GET并丢弃响应体，只为保持连接/会话活性
+func sendIdleProbe(ctx context.Context, httpClient *http.Client) {
+	urls := snapshotTargetURLs()
+	if len(urls) == 0 {
+		return
+	}
+	atomic.AddInt64(&idleProbeCount, 1)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", urls[mathrand.Intn(len(urls))], nil)
+	if err != nil {
+		atomic.AddInt64(&idleProbeFails, 1)
+		return
+	}
+	req.Header.Set("User-Agent", generateRandomUserAgent())
+
+	start := time.Now()
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		atomic.AddInt64(&idleProbeFails, 1)
+		return
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		atomic.AddInt64(&idleProbeFails, 1)
+		return
+	}
+
+	idleProbeMu.Lock()
+	if len(idleProbeDur) < 10000 {
+		idleProbeDur = append(idleProbeDur, time.Since(start))
+	}
+	idleProbeMu.Unlock()
+}
+
+// 打印保活探测统计 (与压测统计分开呈现)
+func printIdleProbeReport() {
+	count := atomic.LoadInt64(&idleProbeCount)
+	if count == 0 {
+		return
+	}
+
+	fmt.Printf("\n=== 空闲期保活探测 ===\n")
+	fmt.Printf("探测: %d 次 | 失败: %d 次", count, atomic.LoadInt64(&idleProbeFails))
+	idleProbeMu.Lock()
+	if len(idleProbeDur) > 0 {
+		avg, p50, _, _ := durationPercentiles(idleProbeDur)
+		fmt.Printf(" | 平均/P50: %.1f/%.1fms", avg, p50)
+	}
+	idleProbeMu.Unlock()
+	fmt.Printf("\n")
+}
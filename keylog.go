@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+)
+
+// ===================================================================================
+// --- TLS密钥日志 (TLS Key Log) ---
+// 以SSLKEYLOGFILE格式导出TLS会话密钥 (tls.Config.KeyLogWriter)，
+// 配合Wireshark解密测试流量的抓包，排查协议层问题
+// ===================================================================================
+
+// 密钥日志文件路径，为空不导出
+var TLSKeyLogFile = ""
+
+var (
+	keyLogOnce sync.Once
+	keyLogFile io.Writer
+)
+
+// 返回共享的密钥日志写入器 (追加模式、0600权限)，未启用或打开失败时返回nil，
+// 三类客户端 (HTTP/HTTP3/WebSocket) 的tls.Config共用同一个文件
+func tlsKeyLogWriter() io.Writer {
+	if TLSKeyLogFile == "" {
+		return nil
+	}
+	keyLogOnce.Do(func() {
+		f, err := os.OpenFile(TLSKeyLogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			log.Printf("警告: 无法打开TLS密钥日志文件: %v", err)
+			return
+		}
+		fmt.Printf("TLS密钥日志已启用: %s (密钥材料可解密全部流量，仅限调试使用)\n", TLSKeyLogFile)
+		keyLogFile = f
+	})
+	return keyLogFile
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ===================================================================================
+// --- 大文件下载基准 (Large File Benchmark) ---
+// 每次迭代把同一个大对象从头到尾下载完整 (可选按Range分段并行拉取)，
+// 报告按文件的吞吐分布与停顿次数；常规按请求计数的统计无法表达
+// "这个对象下完要多久、中途卡了几次"这类问题
+// 用法: 配置large_file_url后替代常规HTTP模式
+// ===================================================================================
+
+var (
+	// 非空启用大文件模式
+	LargeFileURL = ""
+	// 每个文件的并行Range分段数，1为整体顺序下载
+	LargeFileRangeParts = 1
+	// 单次Read超过此时长计为一次停顿
+	LargeFileStallThreshold = 2 * time.Second
+)
+
+// 按文件的统计: 吞吐样本(MB/s)在锁下追加，计数器原子更新
+var (
+	largeFileDownloads int64
+	largeFileFailed    int64
+	largeFileStalls    int64
+	largeFileBytes     int64
+
+	largeFileMu         sync.Mutex
+	largeFileThroughput []float64 // 每个完整文件的MB/s
+)
+
+// 探测到的对象大小，-1表示服务端不支持Range或探测失败
+var (
+	largeFileSize     int64
+	largeFileSizeOnce sync.Once
+)
+
+// HEAD探测对象大小与Range支持 (分段模式需要)
+func probeLargeFileSize(client *http.Client) int64 {
+	largeFileSizeOnce.Do(func() {
+		largeFileSize = -1
+		req, err := http.NewRequest("HEAD", LargeFileURL, nil)
+		if err != nil {
+			return
+		}
+		req.Header.Set("User-Agent", generateRandomUserAgent())
+		resp, err := client.Do(req)
+		if err != nil {
+			return
+		}
+		resp.Body.Close()
+		if resp.ContentLength > 0 && strings.Contains(strings.ToLower(resp.Header.Get("Accept-Ranges")), "bytes") {
+			largeFileSize = resp.ContentLength
+		}
+	})
+	return largeFileSize
+}
+
+// 大文件模式入口: 协程循环下载同一对象，每个完整文件计一次请求
+func runLargeFileLoad(ctx context.Context, stats *Stats, client *http.Client, wg *sync.WaitGroup) {
+	parts := LargeFileRangeParts
+	if parts > 1 {
+		if size := probeLargeFileSize(client); size <= 0 {
+			fmt.Printf("警告: 目标不支持Range或无法探测大小，退回整体顺序下载\n")
+			parts = 1
+		} else {
+			fmt.Printf("对象大小: %.1fMB，每文件 %d 个并行Range分段\n", float64(largeFileSize)/1024/1024, parts)
+		}
+	}
+	fmt.Printf("大文件模式: %s x %d 并发\n", LargeFileURL, NumConcurrentWorkers)
+
+	var dispatched int64
+	for i := 0; i < NumConcurrentWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+				}
+				if TestDuration <= 0 && atomic.AddInt64(&dispatched, 1) > int64(TotalDownloads) {
+					return
+				}
+				if !testDeadline.IsZero() && time.Now().After(testDeadline) {
+					return
+				}
+				waitWhilePaused(ctx)
+
+				downloadLargeFile(ctx, stats, client, parts)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// 下载一个完整文件，成功后记录吞吐样本
+func downloadLargeFile(ctx context.Context, stats *Stats, client *http.Client, parts int) {
+	atomic.AddInt64(&stats.TotalRequests, 1)
+	start := time.Now()
+
+	var bytes int64
+	var err error
+	if parts > 1 {
+		bytes, err = downloadRangeParts(ctx, client, parts)
+	} else {
+		bytes, err = downloadOnePart(ctx, client, -1, -1)
+	}
+	if err != nil {
+		atomic.AddInt64(&largeFileFailed, 1)
+		atomic.AddInt64(&stats.FailedRequests, 1)
+		recordError(stats, fmt.Sprintf("LargeFile_%v", err))
+		return
+	}
+
+	duration := time.Since(start)
+	atomic.AddInt64(&largeFileDownloads, 1)
+	atomic.AddInt64(&largeFileBytes, bytes)
+	atomic.AddInt64(&stats.TotalResponses, 1)
+	atomic.AddInt64(&stats.TotalResponseSize, bytes)
+	recordResponseTime(stats, duration)
+
+	mbps := float64(bytes) / 1024 / 1024 / duration.Seconds()
+	largeFileMu.Lock()
+	if len(largeFileThroughput) < 50000 {
+		largeFileThroughput = append(largeFileThroughput, mbps)
+	}
+	largeFileMu.Unlock()
+}
+
+// 分段并行: 把对象均分为parts个Range，全部成功才算一个文件
+func downloadRangeParts(ctx context.Context, client *http.Client, parts int) (int64, error) {
+	size := largeFileSize
+	chunk := size / int64(parts)
+
+	var partWG sync.WaitGroup
+	var total int64
+	errs := make(chan error, parts)
+	for p := 0; p < parts; p++ {
+		from := int64(p) * chunk
+		to := from + chunk - 1
+		if p == parts-1 {
+			to = size - 1
+		}
+		partWG.Add(1)
+		go func(from, to int64) {
+			defer partWG.Done()
+			n, err := downloadOnePart(ctx, client, from, to)
+			atomic.AddInt64(&total, n)
+			if err != nil {
+				errs <- err
+			}
+		}(from, to)
+	}
+	partWG.Wait()
+	select {
+	case err := <-errs:
+		return total, err
+	default:
+		return total, nil
+	}
+}
+
+// 下载一个分段 (from<0表示整个对象)，按块读并统计停顿
+func downloadOnePart(ctx context.Context, client *http.Client, from, to int64) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", LargeFileURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("构建请求失败")
+	}
+	req.Header.Set("User-Agent", generateRandomUserAgent())
+	if from >= 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", from, to))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("连接失败")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return 0, fmt.Errorf("HTTP_%d", resp.StatusCode)
+	}
+
+	var total int64
+	buf := make([]byte, 64*1024)
+	for {
+		readStart := time.Now()
+		n, err := resp.Body.Read(buf)
+		if time.Since(readStart) > LargeFileStallThreshold {
+			atomic.AddInt64(&largeFileStalls, 1)
+		}
+		total += int64(n)
+		if err == io.EOF {
+			return total, nil
+		}
+		if err != nil {
+			return total, fmt.Errorf("传输中断")
+		}
+	}
+}
+
+// 吞吐样本的avg/p50/p95/p99 (MB/s)
+func throughputPercentiles(samples []float64) (avg, p50, p95, p99 float64) {
+	if len(samples) == 0 {
+		return 0, 0, 0, 0
+	}
+	sorted := append([]float64(nil), samples...)
+	sort.Float64s(sorted)
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+	pick := func(q float64) float64 {
+		idx := int(q * float64(len(sorted)-1))
+		return sorted[idx]
+	}
+	return sum / float64(len(sorted)), pick(0.50), pick(0.95), pick(0.99)
+}
+
+// 供JSON报告使用
+func largeFileAnalysis() map[string]interface{} {
+	if LargeFileURL == "" {
+		return nil
+	}
+	row := map[string]interface{}{
+		"url":         LargeFileURL,
+		"range_parts": LargeFileRangeParts,
+		"downloads":   atomic.LoadInt64(&largeFileDownloads),
+		"failed":      atomic.LoadInt64(&largeFileFailed),
+		"stalls":      atomic.LoadInt64(&largeFileStalls),
+		"total_mb":    float64(atomic.LoadInt64(&largeFileBytes)) / 1024 / 1024,
+	}
+	largeFileMu.Lock()
+	if len(largeFileThroughput) > 0 {
+		avg, p50, p95, p99 := throughputPercentiles(largeFileThroughput)
+		row["throughput_mbps"] = map[string]float64{"avg": avg, "p50": p50, "p95": p95, "p99": p99}
+	}
+	largeFileMu.Unlock()
+	return row
+}
+
+// 打印大文件基准报告
+func printLargeFileReport() {
+	if LargeFileURL == "" {
+		return
+	}
+	fmt.Printf("\n=== 大文件下载基准 ===\n")
+	fmt.Printf("完整下载: %d  失败: %d  停顿(>%v): %d  总流量: %.1fMB\n",
+		atomic.LoadInt64(&largeFileDownloads), atomic.LoadInt64(&largeFileFailed),
+		LargeFileStallThreshold, atomic.LoadInt64(&largeFileStalls),
+		float64(atomic.LoadInt64(&largeFileBytes))/1024/1024)
+	largeFileMu.Lock()
+	if len(largeFileThroughput) > 0 {
+		avg, p50, p95, p99 := throughputPercentiles(largeFileThroughput)
+		fmt.Printf("单文件吞吐(MB/s): 平均=%.1f P50=%.1f P95=%.1f P99=%.1f\n", avg, p50, p95, p99)
+	}
+	largeFileMu.Unlock()
+}
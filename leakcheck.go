@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ===================================================================================
+// --- 协程泄漏检测 (Goroutine Leak Detection) ---
+// 在wg.Wait()结束后对比协程数基线，按创建点归类残留协程并转储堆栈，
+// 检出泄漏时扣减资源利用得分
+// ===================================================================================
+
+var EnableLeakDetection = true
+
+// 启动工作协程前的协程数基线
+var baselineGoroutines int
+
+// 检出的泄漏协程数，供calculateResourceScore扣分使用
+var detectedLeakedGoroutines int
+
+// 在派发负载前记录基线 (此时进度监控、信号处理等常驻协程已经启动)
+func captureGoroutineBaseline() {
+	baselineGoroutines = runtime.NumGoroutine()
+}
+
+// wg.Wait()之后调用: 留出短暂宽限期让正常退出的协程被回收，再对比基线
+func detectGoroutineLeaks() {
+	if !EnableLeakDetection || baselineGoroutines == 0 {
+		return
+	}
+
+	// 宽限期: 刚返回的协程可能尚未被调度器回收
+	time.Sleep(500 * time.Millisecond)
+
+	current := runtime.NumGoroutine()
+	leaked := current - baselineGoroutines
+	if leaked <= 0 {
+		detectedLeakedGoroutines = 0
+		return
+	}
+	detectedLeakedGoroutines = leaked
+
+	fmt.Printf("\n=== 协程泄漏检测 ===\n")
+	fmt.Printf("基线: %d | 结束时: %d | 疑似泄漏: %d\n", baselineGoroutines, current, leaked)
+
+	// 转储全部堆栈并按创建点归类，定位泄漏来源 (如卡住的挂机模式休眠、未停止的缓存刷新)
+	buf := make([]byte, 1<<20)
+	n := runtime.Stack(buf, true)
+	categories := categorizeGoroutineStacks(string(buf[:n]))
+
+	type catCount struct {
+		origin string
+		count  int
+	}
+	var sorted []catCount
+	for origin, count := range categories {
+		sorted = append(sorted, catCount{origin, count})
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].count > sorted[j].count })
+
+	fmt.Printf("残留协程按创建点归类:\n")
+	for _, c := range sorted {
+		fmt.Printf("  %d × %s\n", c.count, c.origin)
+	}
+}
+
+// 解析runtime.Stack输出，按 "created by" 行 (退化为栈顶函数) 归类协程
+func categorizeGoroutineStacks(dump string) map[string]int {
+	categories := make(map[string]int)
+
+	for _, block := range strings.Split(dump, "\n\n") {
+		lines := strings.Split(block, "\n")
+		if len(lines) < 2 || !strings.HasPrefix(lines[0], "goroutine ") {
+			continue
+		}
+
+		origin := ""
+		for _, line := range lines {
+			if strings.HasPrefix(line, "created by ") {
+				origin = strings.TrimPrefix(line, "created by ")
+				if idx := strings.Index(origin, " in goroutine"); idx > 0 {
+					origin = origin[:idx]
+				}
+				break
+			}
+		}
+		if origin == "" {
+			// 主协程或运行时协程: 用栈顶函数标识
+			origin = strings.TrimSpace(lines[1])
+			if idx := strings.Index(origin, "("); idx > 0 {
+				origin = origin[:idx]
+			}
+		}
+		categories[origin]++
+	}
+	return categories
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// ===================================================================================
+// --- 运行环境清单 (Run Manifest) ---
+// 不同机器跑出的结果没有环境信息就无法公平对比；
+// 把内核版本、CPU型号、ulimit、网卡速率和关键sysctl一并写入报告的test_info
+// ===================================================================================
+
+// 读取/proc或/sys下的单值文件，失败返回空串 (非Linux环境自然全部缺省)
+func readProcValue(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// 从/proc/cpuinfo提取CPU型号
+func cpuModelName() string {
+	data, err := os.ReadFile("/proc/cpuinfo")
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "model name") {
+			if idx := strings.Index(line, ":"); idx >= 0 {
+				return strings.TrimSpace(line[idx+1:])
+			}
+		}
+	}
+	return ""
+}
+
+// 各物理网卡的协商速率 (Mbps)，虚拟接口和未知速率跳过
+func nicSpeeds() map[string]string {
+	speeds := make(map[string]string)
+	entries, err := os.ReadDir("/sys/class/net")
+	if err != nil {
+		return speeds
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if name == "lo" {
+			continue
+		}
+		speed := readProcValue(filepath.Join("/sys/class/net", name, "speed"))
+		if speed != "" && speed != "-1" {
+			speeds[name] = speed + " Mbps"
+		}
+	}
+	return speeds
+}
+
+// 采集完整的运行环境清单
+func runManifest() map[string]interface{} {
+	manifest := map[string]interface{}{
+		"os":         runtime.GOOS,
+		"arch":       runtime.GOARCH,
+		"go_version": runtime.Version(),
+		"cpu_cores":  runtime.NumCPU(),
+	}
+
+	if hostname, err := os.Hostname(); err == nil {
+		manifest["hostname"] = hostname
+	}
+	if kernel := readProcValue("/proc/sys/kernel/osrelease"); kernel != "" {
+		manifest["kernel"] = kernel
+	}
+	if model := cpuModelName(); model != "" {
+		manifest["cpu_model"] = model
+	}
+	if speeds := nicSpeeds(); len(speeds) > 0 {
+		manifest["nic_speeds"] = speeds
+	}
+
+	// 文件描述符限制: 直接决定可持有的并发连接上限
+	if info := fdLimitInfo(); info != nil {
+		manifest["ulimit_nofile"] = info
+	}
+
+	// 影响高并发短连接行为的关键sysctl
+	sysctls := make(map[string]string)
+	for name, path := range map[string]string{
+		"net.core.somaxconn":            "/proc/sys/net/core/somaxconn",
+		"net.ipv4.tcp_tw_reuse":         "/proc/sys/net/ipv4/tcp_tw_reuse",
+		"net.ipv4.tcp_fin_timeout":      "/proc/sys/net/ipv4/tcp_fin_timeout",
+		"net.ipv4.ip_local_port_range":  "/proc/sys/net/ipv4/ip_local_port_range",
+		"net.core.netdev_max_backlog":   "/proc/sys/net/core/netdev_max_backlog",
+	} {
+		if value := readProcValue(path); value != "" {
+			sysctls[name] = value
+		}
+	}
+	if len(sysctls) > 0 {
+		manifest["sysctl"] = sysctls
+	}
+
+	// Go构建信息: 模块版本与VCS修订，方便追溯报告出自哪个构建
+	if info, ok := debug.ReadBuildInfo(); ok {
+		build := map[string]string{"module": info.Main.Path}
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision", "vcs.time", "vcs.modified", "-ldflags":
+				build[setting.Key] = setting.Value
+			}
+		}
+		manifest["build_info"] = build
+	}
+
+	return manifest
+}
+
+// 打印环境清单的简要一行，完整内容见JSON报告
+func printManifestSummary() {
+	kernel := readProcValue("/proc/sys/kernel/osrelease")
+	if kernel == "" {
+		kernel = "未知内核"
+	}
+	fmt.Printf("运行环境: %s/%s | %d 核 | 内核 %s | %s\n",
+		runtime.GOOS, runtime.GOARCH, runtime.NumCPU(), kernel, runtime.Version())
+}
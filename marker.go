@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	mathrand "math/rand"
+	"time"
+)
+
+// ===================================================================================
+// --- 压测流量标记头 (Load Test Marker Header) ---
+// 所有协议的每个请求都带上统一的标记头 (默认 X-Load-Test: run-id)，
+// 服务端团队可据此过滤或剔除压测流量；标记值同时写入报告元数据
+// ===================================================================================
+
+var (
+	MarkerHeaderName  = "X-Load-Test"
+	MarkerHeaderValue = "" // 为空时启动自动生成run-id
+)
+
+// 生成本次运行的标记值 (未显式配置时)
+func initMarkerHeader() {
+	if MarkerHeaderValue == "" {
+		MarkerHeaderValue = fmt.Sprintf("run-%s-%04d",
+			time.Now().Format("20060102-150405"), mathrand.Intn(10000))
+	}
+	fmt.Printf("压测流量标记: %s: %s\n", MarkerHeaderName, MarkerHeaderValue)
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ===================================================================================
+// --- 配置矩阵编排 (Configuration Matrix) ---
+// "并发1k/5k/10k各配h1/h2跑一遍对比" 这类网格实验过去靠手改配置反复重跑；
+// 矩阵模式按各轴取值的笛卡尔积依次执行全部组合 (子进程隔离，与守护进程模式同理)，
+// 结束后输出合并对比表、QPS柱状图和合并JSON报告
+// 用法: load-tester matrix <matrix.json>
+// matrix.json: {"base": {普通配置字段...}, "axes": {"concurrent_workers": [1000,5000], "http_versions": ["h1","h2"]}}
+// ===================================================================================
+
+const matrixRunRoot = "matrix_runs"
+
+// 矩阵描述文件
+type MatrixSpec struct {
+	Base map[string]interface{}   `json:"base"` // 所有组合共享的基础配置
+	Axes map[string][]interface{} `json:"axes"` // 轴名(配置字段) -> 取值列表
+}
+
+// 单个组合的执行结果 (指标提取复用多区域编排的逻辑)
+type matrixRun struct {
+	Name      string
+	Overrides map[string]interface{}
+	Dir       string
+	Result    RegionResult
+}
+
+// 矩阵入口: 生成组合、逐个执行、汇总对比
+func runMatrix(specFile string) error {
+	data, err := os.ReadFile(specFile)
+	if err != nil {
+		return fmt.Errorf("无法读取矩阵文件: %v", err)
+	}
+	var spec MatrixSpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return fmt.Errorf("矩阵文件格式错误: %v", err)
+	}
+	if len(spec.Axes) == 0 {
+		return fmt.Errorf("矩阵文件缺少axes")
+	}
+
+	combos := buildMatrixCombos(spec.Axes)
+	selfPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("无法定位自身二进制: %v", err)
+	}
+	if err := os.MkdirAll(matrixRunRoot, 0755); err != nil {
+		return fmt.Errorf("无法创建矩阵工作目录: %v", err)
+	}
+
+	fmt.Printf("=== 配置矩阵模式 ===\n")
+	fmt.Printf("组合数量: %d (按顺序执行)\n", len(combos))
+
+	runs := make([]*matrixRun, 0, len(combos))
+	for i, combo := range combos {
+		run := &matrixRun{
+			Name:      comboName(combo),
+			Overrides: combo,
+			Dir:       filepath.Join(matrixRunRoot, fmt.Sprintf("run_%02d", i+1)),
+		}
+		fmt.Printf("\n[%d/%d] %s\n", i+1, len(combos), run.Name)
+		executeMatrixRun(run, spec.Base, selfPath)
+		if run.Result.Err != nil {
+			fmt.Printf("  失败: %v\n", run.Result.Err)
+		} else {
+			fmt.Printf("  QPS: %.1f  成功率: %.1f%%  P95: %.1fms\n",
+				run.Result.QPS, run.Result.SuccessRate, run.Result.P95Ms)
+		}
+		runs = append(runs, run)
+	}
+
+	printMatrixComparison(runs)
+	saveMatrixReport(runs)
+	return nil
+}
+
+// 各轴取值的笛卡尔积，轴名排序保证组合顺序稳定可复现
+func buildMatrixCombos(axes map[string][]interface{}) []map[string]interface{} {
+	names := make([]string, 0, len(axes))
+	for name := range axes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	combos := []map[string]interface{}{{}}
+	for _, name := range names {
+		var next []map[string]interface{}
+		for _, combo := range combos {
+			for _, value := range axes[name] {
+				expanded := make(map[string]interface{}, len(combo)+1)
+				for k, v := range combo {
+					expanded[k] = v
+				}
+				expanded[name] = value
+				next = append(next, expanded)
+			}
+		}
+		combos = next
+	}
+	return combos
+}
+
+// 组合的展示名，如 concurrent_workers=5000 http_versions=h2
+func comboName(combo map[string]interface{}) string {
+	keys := make([]string, 0, len(combo))
+	for k := range combo {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", k, combo[k]))
+	}
+	return strings.Join(parts, " ")
+}
+
+// 执行单个组合: 基础配置+轴覆盖写入独立目录，子进程跑完后提取报告指标
+func executeMatrixRun(run *matrixRun, base map[string]interface{}, selfPath string) {
+	run.Result = RegionResult{Region: run.Name}
+
+	merged := make(map[string]interface{}, len(base)+len(run.Overrides))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range run.Overrides {
+		merged[k] = v
+	}
+
+	if err := os.MkdirAll(run.Dir, 0755); err != nil {
+		run.Result.Err = fmt.Errorf("无法创建组合目录: %v", err)
+		return
+	}
+	configData, _ := json.MarshalIndent(merged, "", "  ")
+	configPath := filepath.Join(run.Dir, "config.json")
+	if err := os.WriteFile(configPath, configData, 0644); err != nil {
+		run.Result.Err = fmt.Errorf("无法写入组合配置: %v", err)
+		return
+	}
+
+	logFile, err := os.Create(filepath.Join(run.Dir, "run.log"))
+	if err != nil {
+		run.Result.Err = fmt.Errorf("无法创建日志文件: %v", err)
+		return
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command(selfPath, "-config", "config.json")
+	cmd.Dir = run.Dir
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	if err := cmd.Run(); err != nil {
+		run.Result.Err = fmt.Errorf("组合执行失败: %v (详见 %s/run.log)", err, run.Dir)
+		return
+	}
+
+	reports, _ := filepath.Glob(filepath.Join(run.Dir, "detailed_report_*.json"))
+	if len(reports) == 0 {
+		run.Result.Err = fmt.Errorf("组合未产出详细报告")
+		return
+	}
+	sort.Strings(reports) // 文件名含时间戳，字典序即时间序
+	reportData, err := os.ReadFile(reports[len(reports)-1])
+	if err != nil {
+		run.Result.Err = fmt.Errorf("读取组合报告失败: %v", err)
+		return
+	}
+	if err := extractRegionMetrics(reportData, &run.Result); err != nil {
+		run.Result.Err = err
+	}
+}
+
+// 打印对比表和QPS柱状图
+func printMatrixComparison(runs []*matrixRun) {
+	fmt.Printf("\n" + strings.Repeat("=", 72) + "\n")
+	fmt.Printf("                     矩阵组合结果对比\n")
+	fmt.Printf(strings.Repeat("=", 72) + "\n")
+
+	maxQPS := 0.0
+	for _, run := range runs {
+		if run.Result.Err == nil && run.Result.QPS > maxQPS {
+			maxQPS = run.Result.QPS
+		}
+	}
+
+	for _, run := range runs {
+		if run.Result.Err != nil {
+			fmt.Printf("%-40s 失败: %v\n", run.Name, run.Result.Err)
+			continue
+		}
+		fmt.Printf("%-40s QPS: %8.1f | 成功率: %5.1f%% | 平均: %7.1fms | P95: %7.1fms\n",
+			run.Name, run.Result.QPS, run.Result.SuccessRate, run.Result.AvgMs, run.Result.P95Ms)
+	}
+
+	if maxQPS > 0 {
+		fmt.Printf("\nQPS对比:\n")
+		for _, run := range runs {
+			if run.Result.Err != nil {
+				continue
+			}
+			bar := strings.Repeat("█", int(run.Result.QPS/maxQPS*40+0.5))
+			fmt.Printf("%-40s %s %.1f\n", run.Name, bar, run.Result.QPS)
+		}
+	}
+	fmt.Printf(strings.Repeat("=", 72) + "\n")
+}
+
+// 保存合并JSON报告，每个组合带覆盖项和关键指标
+func saveMatrixReport(runs []*matrixRun) {
+	rows := make([]map[string]interface{}, 0, len(runs))
+	for _, run := range runs {
+		row := map[string]interface{}{
+			"name":      run.Name,
+			"overrides": run.Overrides,
+			"dir":       run.Dir,
+		}
+		if run.Result.Err != nil {
+			row["error"] = run.Result.Err.Error()
+		} else {
+			row["qps"] = run.Result.QPS
+			row["success_rate"] = run.Result.SuccessRate
+			row["latency_average_ms"] = run.Result.AvgMs
+			row["latency_p50_ms"] = run.Result.P50Ms
+			row["latency_p95_ms"] = run.Result.P95Ms
+			row["latency_p99_ms"] = run.Result.P99Ms
+			row["total_requests"] = run.Result.TotalRequests
+			row["failed_requests"] = run.Result.FailedRequests
+		}
+		rows = append(rows, row)
+	}
+
+	merged := map[string]interface{}{
+		"combinations": rows,
+		"timestamp":    time.Now().Format(time.RFC3339),
+	}
+	fileName := fmt.Sprintf("matrix_report_%s.json", time.Now().Format("20060102_150405"))
+	file, err := os.Create(fileName)
+	if err != nil {
+		log.Printf("无法创建矩阵报告文件: %v", err)
+		return
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(merged); err != nil {
+		log.Printf("无法保存矩阵报告: %v", err)
+		return
+	}
+	log.Printf("矩阵报告已保存到: %s", fileName)
+}
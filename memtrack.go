@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// ===================================================================================
+// --- 内存水位与分配速率采样 (Memory High-Water Mark) ---
+// 测试期间持续采样runtime.MemStats，记录峰值堆内存、分配速率和GC暂停分布，
+// 代替仅在结束时读一次Alloc的粗糙做法，让资源评分更真实
+// ===================================================================================
+
+var EnableMemSampling = true
+
+const memSampleInterval = 1 * time.Second
+
+type memSampler struct {
+	mu             sync.Mutex
+	peakHeap       uint64
+	firstTotal     uint64
+	lastTotal      uint64
+	firstSample    time.Time
+	lastSample     time.Time
+	gcPauses       []time.Duration
+	lastNumGC      uint32
+	sampleCount    int
+}
+
+var memTrack = &memSampler{}
+
+// 启动后台采样协程，随ctx取消而退出
+func startMemSampler(ctx context.Context) {
+	if !EnableMemSampling {
+		return
+	}
+	memTrack.sampleOnce()
+
+	go func() {
+		ticker := time.NewTicker(memSampleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				memTrack.sampleOnce()
+			}
+		}
+	}()
+}
+
+func (s *memSampler) sampleOnce() {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.sampleCount == 0 {
+		s.firstTotal = m.TotalAlloc
+		s.firstSample = now
+		s.lastNumGC = m.NumGC
+	}
+	s.sampleCount++
+	s.lastTotal = m.TotalAlloc
+	s.lastSample = now
+
+	if m.HeapAlloc > s.peakHeap {
+		s.peakHeap = m.HeapAlloc
+	}
+
+	// 收集自上次采样以来新发生的GC暂停 (PauseNs是256项环形缓冲)
+	for gc := s.lastNumGC; gc < m.NumGC && m.NumGC-gc <= 256; gc++ {
+		s.gcPauses = append(s.gcPauses, time.Duration(m.PauseNs[(gc+255)%256]))
+	}
+	s.lastNumGC = m.NumGC
+}
+
+// 采样到的峰值堆内存 (MB)，无采样数据时返回0
+func memPeakHeapMB() float64 {
+	memTrack.mu.Lock()
+	defer memTrack.mu.Unlock()
+	return float64(memTrack.peakHeap) / (1024 * 1024)
+}
+
+// 平均分配速率 (MB/s)
+func memAllocRateMBps() float64 {
+	memTrack.mu.Lock()
+	defer memTrack.mu.Unlock()
+	elapsed := memTrack.lastSample.Sub(memTrack.firstSample).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(memTrack.lastTotal-memTrack.firstTotal) / (1024 * 1024) / elapsed
+}
+
+// 打印内存采样分析
+func printMemReport() {
+	memTrack.mu.Lock()
+	sampleCount := memTrack.sampleCount
+	pauses := append([]time.Duration(nil), memTrack.gcPauses...)
+	memTrack.mu.Unlock()
+
+	if sampleCount == 0 {
+		return
+	}
+
+	fmt.Printf("\n=== 内存采样分析 ===\n")
+	fmt.Printf("峰值堆内存: %.2f MB | 平均分配速率: %.2f MB/s | 采样点: %d\n",
+		memPeakHeapMB(), memAllocRateMBps(), sampleCount)
+	if len(pauses) > 0 {
+		avg, p50, p95, p99 := durationPercentiles(pauses)
+		fmt.Printf("GC暂停: %d 次 | 平均: %.2fms | P50/P95/P99: %.2f/%.2f/%.2fms\n",
+			len(pauses), avg, p50, p95, p99)
+	}
+}
+
+// 内存采样的JSON报告片段
+func memAnalysis() map[string]interface{} {
+	memTrack.mu.Lock()
+	sampleCount := memTrack.sampleCount
+	pauses := append([]time.Duration(nil), memTrack.gcPauses...)
+	memTrack.mu.Unlock()
+
+	if sampleCount == 0 {
+		return nil
+	}
+
+	analysis := map[string]interface{}{
+		"peak_heap_mb":      memPeakHeapMB(),
+		"alloc_rate_mbps":   memAllocRateMBps(),
+		"sample_count":      sampleCount,
+	}
+	if len(pauses) > 0 {
+		avg, p50, p95, p99 := durationPercentiles(pauses)
+		analysis["gc_pauses"] = map[string]interface{}{
+			"count": len(pauses), "avg_ms": avg, "p50_ms": p50, "p95_ms": p95, "p99_ms": p99,
+		}
+	}
+	return analysis
+}
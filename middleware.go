@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ===================================================================================
+// --- 请求变换中间件链 (Request Mutation Middleware) ---
+// 签名、注头、改写请求体这类需求以前只能改makeHTTPRequest本身；
+// 现在统一为发送前按注册顺序依次应用的中间件，新增变换只需注册函数
+// ===================================================================================
+
+// 单个中间件: 发送前对请求做一次变换，返回错误则该请求不发出并计为失败
+type RequestMiddleware func(*http.Request) error
+
+var (
+	middlewareMu       sync.RWMutex
+	requestMiddlewares []RequestMiddleware
+)
+
+// 注册一个中间件，按注册顺序应用
+func RegisterRequestMiddleware(mw RequestMiddleware) {
+	middlewareMu.Lock()
+	requestMiddlewares = append(requestMiddlewares, mw)
+	middlewareMu.Unlock()
+}
+
+// 按顺序应用全部中间件，遇错即停
+func applyRequestMiddlewares(req *http.Request) error {
+	middlewareMu.RLock()
+	chain := requestMiddlewares
+	middlewareMu.RUnlock()
+
+	for i, mw := range chain {
+		if err := mw(req); err != nil {
+			return fmt.Errorf("中间件 %d 处理失败: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// 响应观察者: 每个成功返回的响应按注册顺序回调 (自定义指标/校验/提取)。
+// 回调必须只读，不得消费或关闭Body——响应体仍由核心路径统一读取
+type ResponseObserver func(*http.Response, time.Duration)
+
+var responseObservers []ResponseObserver
+
+// 注册一个响应观察者，按注册顺序调用
+func RegisterResponseObserver(ob ResponseObserver) {
+	middlewareMu.Lock()
+	responseObservers = append(responseObservers, ob)
+	middlewareMu.Unlock()
+}
+
+// 依次通知全部观察者
+func notifyResponseObservers(resp *http.Response, duration time.Duration) {
+	middlewareMu.RLock()
+	observers := responseObservers
+	middlewareMu.RUnlock()
+
+	for _, ob := range observers {
+		ob(resp, duration)
+	}
+}
+
+// 配置的静态附加请求头，启动时注册为第一个中间件
+var ExtraRequestHeaders map[string]string
+
+// 注册配置驱动的内置中间件
+func registerBuiltinMiddlewares() {
+	if len(ExtraRequestHeaders) > 0 {
+		headers := ExtraRequestHeaders
+		RegisterRequestMiddleware(func(req *http.Request) error {
+			for key, value := range headers {
+				req.Header.Set(key, value)
+			}
+			return nil
+		})
+	}
+}
@@ -16,28 +16,39 @@ import (
 	"net/http/cookiejar"
 	"net/url"
 	"os"
+	"os/signal"
 	"runtime"
-	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/quic-go/quic-go/http3"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 // ===================================================================================
 // --- 模式配置区域 (Mode Configuration Section) ---
 // ===================================================================================
 
-const SelectedTestMode = ModeNormal
+var SelectedTestMode = ModeNormal
+
+// --- 优化的性能参数 (可通过配置文件/命令行覆盖，无需重新编译) ---
+var (
+	// 按时长运行: 大于0时工作协程跑到截止时间为止，TotalDownloads仅用于协程预算计算
+	TestDuration time.Duration = 0
+
+	// 每个协程的目标请求间隔 (大于0时启用配速和协调遗漏补偿)
+	PacingInterval time.Duration = 0
 
-// --- 优化的性能参数 ---
-const (
 	TotalDownloads       = 30000000               // 降低默认请求数以提高稳定性
 	NumConcurrentWorkers = 10000                 // 优化并发数
 	CacheSize            = 20000                // 增大缓存以减少重复生成
@@ -81,6 +92,10 @@ var (
 	EnableSlowPost        = false
 	EnableRandomUserAgent = true
 
+	// 工作协程目标亲和性: 每个协程固定访问一部分目标以最大化连接复用
+	EnableWorkerAffinity  = false
+	WorkerAffinityTargets = 1 // 每个协程固定绑定的目标数量
+
 	OnlyShowNon200Errors = true
 	EnableVerboseLogging = true
 	EnableProgressBar    = true
@@ -175,16 +190,16 @@ func calculateErrorRateScore(errorRate float64) float64 {
 }
 
 // 计算协议支持得分 (满分20分)
-func calculateProtocolScore(stats *Stats) float64 {
+func calculateProtocolScore(stats *StatsSnapshot) float64 {
 	score := 8.0 // HTTP基础分
 	
-	if atomic.LoadInt64(&stats.WSRequests) > 0 {
+	if stats.WSRequests > 0 {
 		score += 4.0 // WebSocket支持
 	}
-	if atomic.LoadInt64(&stats.GRPCRequests) > 0 {
+	if stats.GRPCRequests > 0 {
 		score += 4.0 // gRPC支持
 	}
-	if atomic.LoadInt64(&stats.HTTP3Requests) > 0 {
+	if stats.HTTP3Requests > 0 {
 		score += 4.0 // HTTP/3支持
 	}
 	
@@ -207,17 +222,22 @@ func calculateResourceScore(memUsageMB float64, goroutines int) float64 {
 	if goroutines > expectedGoroutines*2 {
 		goroutineScore = math.Max(0, 5.0-float64(goroutines-expectedGoroutines)/float64(expectedGoroutines))
 	}
-	
+
+	// 检出协程泄漏: 协程管理部分直接判零分
+	if detectedLeakedGoroutines > 0 {
+		goroutineScore = 0
+	}
+
 	return memScore + goroutineScore
 }
 
 // 计算综合评分
-func calculateScore(stats *Stats, totalDuration time.Duration, memUsageMB float64, goroutines int) *ScoreCard {
+func calculateScore(stats *StatsSnapshot, totalDuration time.Duration, memUsageMB float64, goroutines int) *ScoreCard {
 	scoreCard := &ScoreCard{}
 	
-	totalReq := atomic.LoadInt64(&stats.TotalRequests)
-	totalResp := atomic.LoadInt64(&stats.TotalResponses)
-	totalFailed := atomic.LoadInt64(&stats.FailedRequests)
+	totalReq := stats.TotalRequests
+	totalResp := stats.TotalResponses
+	totalFailed := stats.FailedRequests
 	
 	if totalReq == 0 {
 		return scoreCard
@@ -226,19 +246,11 @@ func calculateScore(stats *Stats, totalDuration time.Duration, memUsageMB float6
 	// 计算基础指标
 	qps := float64(totalReq) / totalDuration.Seconds()
 	successRate := float64(totalResp) / float64(totalReq) * 100
-	errorRate := float64(totalFailed) / float64(totalReq) * 100
+	// 断言失败虽然请求本身成功，但同样计入错误率评分
+	errorRate := float64(totalFailed+stats.AssertionFailures) / float64(totalReq) * 100
 	
-	// 计算平均响应时间
-	var avgResponseTime time.Duration
-	stats.mu.RLock()
-	if len(stats.ResponseTimes) > 0 {
-		var total time.Duration
-		for _, rt := range stats.ResponseTimes {
-			total += rt
-		}
-		avgResponseTime = total / time.Duration(len(stats.ResponseTimes))
-	}
-	stats.mu.RUnlock()
+	// 平均响应时间: 流式聚合覆盖全程，不受样本切片滚动截断影响
+	avgResponseTime := streamingAvg()
 	
 	// 计算各项得分
 	scoreCard.QPSScore = calculateQPSScore(qps)
@@ -271,13 +283,13 @@ func calculateScore(stats *Stats, totalDuration time.Duration, memUsageMB float6
 }
 
 // 显示评分报告
-func printScoreReport(scoreCard *ScoreCard, stats *Stats, totalDuration time.Duration) {
+func printScoreReport(scoreCard *ScoreCard, stats *StatsSnapshot, totalDuration time.Duration) {
 	fmt.Printf("\n" + strings.Repeat("=", 60) + "\n")
 	fmt.Printf("                    流量测试评分报告\n")
 	fmt.Printf(strings.Repeat("=", 60) + "\n")
 	
-	totalReq := atomic.LoadInt64(&stats.TotalRequests)
-	totalResp := atomic.LoadInt64(&stats.TotalResponses)
+	totalReq := stats.TotalRequests
+	totalResp := stats.TotalResponses
 	qps := float64(totalReq) / totalDuration.Seconds()
 	successRate := float64(totalResp) / float64(totalReq) * 100
 	
@@ -305,8 +317,10 @@ func printScoreReport(scoreCard *ScoreCard, stats *Stats, totalDuration time.Dur
 	printPerformanceSuggestions(scoreCard, qps, successRate)
 }
 
-// 性能优化建议
+// 性能优化建议: 先输出自动分析识别到的具体模式，再给通用建议
 func printPerformanceSuggestions(scoreCard *ScoreCard, qps, successRate float64) {
+	printAnalysisFindings(runFindings)
+
 	fmt.Printf("\n💡 性能优化建议:\n")
 	
 	if scoreCard.QPSScore < 15 {
@@ -339,6 +353,13 @@ func printPerformanceSuggestions(scoreCard *ScoreCard, qps, successRate float64)
 // ===================================================================================
 
 var (
+	// 时长模式下的全局截止时间 (零值表示请求数模式)
+	testDeadline time.Time
+
+	// 随机种子: 0表示用时间播种；显式指定后两次运行生成相同的请求序列，
+	// 实际生效值会写入报告以便复现
+	RandomSeed int64
+
 	TargetURLs         []string
 	GlobalCookies      = make(map[string]string)
 	GlobalFixedHeaders = make(map[string]string)
@@ -379,12 +400,195 @@ type Stats struct {
 	CookieUpdates         int64
 	TLSConnections        int64
 	TimeoutCount          int64 // 新增超时计数
+	FuzzRequests          int64 // 模糊请求计数
+	TruncatedTransfers    int64 // 截断传输计数
+	ChecksumMismatches    int64 // 摘要不匹配计数
+	CoordinatedOmissionSamples int64 // 协调遗漏补录的合成样本数
+	DroppedArrivals       int64 // 开放模型下执行池饱和导致的丢弃数
+	AssertionFailures     int64 // 断言失败总数
+	SchemaViolations      int64 // 响应schema违例总数
+	FilteredRequests      int64 // URL过滤拦截数
+	BudgetDeniedHolds     int64 // 压迫模式超预算被拒绝的占用数
+	RedirectsFollowed     int64 // 跟随的重定向总跳数
+	Redirect3xxResponses  int64 // 终态3xx响应数 (策略不跟随或链被截停)
 	ErrorTypes            map[string]int64
+	AssertionResults      map[string]int64 // 断言名称 -> 失败次数
+	GRPCStatusCodes       map[string]int64 // gRPC状态码 -> 次数
+	WSCloseEvents         map[string]int64 // WebSocket关闭码/断连原因 -> 次数
+	TimeoutsByHost        map[string]int64 // 超时按目标主机分布，供自动分析定位慢目标
+	FuzzOutcomes          map[string]int64 // 变异类型/响应类别 -> 次数
+	HeaderStressResults   map[string]int64 // 头部大小档位/结果 -> 次数
+	URLStressResults      map[string]int64 // URL长度档位/结果 -> 次数
+	RedirectChains        map[string]int64 // 单链跳转次数分布 ("2跳" -> 链数)
+	ErrorSamples          map[string][]ErrorSample // 每类错误的前若干条具体实例
 	ResponseTimes         []time.Duration
 	StartTime             time.Time
 	mu                    sync.RWMutex
 }
 
+// 每类错误保留的具体实例上限
+const MaxErrorSamplesPerType = 3
+
+// 单条错误实例: 让报告中的错误分析带上可排查的具体信息
+type ErrorSample struct {
+	URL        string    `json:"url"`
+	Time       time.Time `json:"time"`
+	Message    string    `json:"message"`
+	StatusCode int       `json:"status_code,omitempty"`
+}
+
+// 记录一条错误实例，每类只保留最早出现的前MaxErrorSamplesPerType条
+func recordErrorSample(stats *Stats, errType, url, message string, statusCode int) {
+	stats.mu.Lock()
+	if stats.ErrorSamples == nil {
+		stats.ErrorSamples = make(map[string][]ErrorSample)
+	}
+	if len(stats.ErrorSamples[errType]) < MaxErrorSamplesPerType {
+		stats.ErrorSamples[errType] = append(stats.ErrorSamples[errType], ErrorSample{
+			URL:        url,
+			Time:       time.Now(),
+			Message:    message,
+			StatusCode: statusCode,
+		})
+	}
+	stats.mu.Unlock()
+}
+
+// 记录一次超时所属的目标主机，供自动分析判断超时是否集中在单个目标
+func recordTimeoutHost(stats *Stats, rawURL string) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return
+	}
+	stats.mu.Lock()
+	if stats.TimeoutsByHost == nil {
+		stats.TimeoutsByHost = make(map[string]int64)
+	}
+	stats.TimeoutsByHost[u.Host]++
+	stats.mu.Unlock()
+}
+
+// Stats的不可变快照: 报表、看板、指标推送统一消费快照，
+// 避免把atomic.Load和锁散落在各个输出路径里
+type StatsSnapshot struct {
+	TotalRequests              int64
+	TotalResponses             int64
+	Non200Responses            int64
+	FailedRequests             int64
+	TotalResponseSize          int64
+	HangingConnections         int64
+	OneByteModeConns           int64
+	SlowReceiveConns           int64
+	GRPCRequests               int64
+	WSRequests                 int64
+	HTTP3Requests              int64
+	CookieUpdates              int64
+	TLSConnections             int64
+	TimeoutCount               int64
+	FuzzRequests               int64
+	TruncatedTransfers         int64
+	ChecksumMismatches         int64
+	CoordinatedOmissionSamples int64
+	DroppedArrivals            int64
+	AssertionFailures          int64
+	SchemaViolations           int64
+	FilteredRequests           int64
+	BudgetDeniedHolds          int64
+	RedirectsFollowed          int64
+	Redirect3xxResponses       int64
+	ErrorTypes                 map[string]int64
+	AssertionResults           map[string]int64
+	GRPCStatusCodes            map[string]int64
+	WSCloseEvents              map[string]int64
+	TimeoutsByHost             map[string]int64
+	FuzzOutcomes               map[string]int64
+	HeaderStressResults        map[string]int64
+	URLStressResults           map[string]int64
+	RedirectChains             map[string]int64
+	ErrorSamples               map[string][]ErrorSample
+	ResponseTimes              []time.Duration
+	StartTime                  time.Time
+}
+
+// 生成线程安全的一致性快照 (计数器原子读取，map和切片深拷贝)
+func (s *Stats) Snapshot() *StatsSnapshot {
+	snap := &StatsSnapshot{
+		TotalRequests:              atomic.LoadInt64(&s.TotalRequests),
+		TotalResponses:             atomic.LoadInt64(&s.TotalResponses),
+		Non200Responses:            atomic.LoadInt64(&s.Non200Responses),
+		FailedRequests:             atomic.LoadInt64(&s.FailedRequests),
+		TotalResponseSize:          atomic.LoadInt64(&s.TotalResponseSize),
+		HangingConnections:         atomic.LoadInt64(&s.HangingConnections),
+		OneByteModeConns:           atomic.LoadInt64(&s.OneByteModeConns),
+		SlowReceiveConns:           atomic.LoadInt64(&s.SlowReceiveConns),
+		GRPCRequests:               atomic.LoadInt64(&s.GRPCRequests),
+		WSRequests:                 atomic.LoadInt64(&s.WSRequests),
+		HTTP3Requests:              atomic.LoadInt64(&s.HTTP3Requests),
+		CookieUpdates:              atomic.LoadInt64(&s.CookieUpdates),
+		TLSConnections:             atomic.LoadInt64(&s.TLSConnections),
+		TimeoutCount:               atomic.LoadInt64(&s.TimeoutCount),
+		FuzzRequests:               atomic.LoadInt64(&s.FuzzRequests),
+		TruncatedTransfers:         atomic.LoadInt64(&s.TruncatedTransfers),
+		ChecksumMismatches:         atomic.LoadInt64(&s.ChecksumMismatches),
+		CoordinatedOmissionSamples: atomic.LoadInt64(&s.CoordinatedOmissionSamples),
+		DroppedArrivals:            atomic.LoadInt64(&s.DroppedArrivals),
+		AssertionFailures:          atomic.LoadInt64(&s.AssertionFailures),
+		SchemaViolations:           atomic.LoadInt64(&s.SchemaViolations),
+		FilteredRequests:           atomic.LoadInt64(&s.FilteredRequests),
+		BudgetDeniedHolds:          atomic.LoadInt64(&s.BudgetDeniedHolds),
+		RedirectsFollowed:          atomic.LoadInt64(&s.RedirectsFollowed),
+		Redirect3xxResponses:       atomic.LoadInt64(&s.Redirect3xxResponses),
+		ErrorTypes:                 make(map[string]int64),
+		AssertionResults:           make(map[string]int64),
+		GRPCStatusCodes:            make(map[string]int64),
+		WSCloseEvents:              make(map[string]int64),
+		TimeoutsByHost:             make(map[string]int64),
+		FuzzOutcomes:               make(map[string]int64),
+		HeaderStressResults:        make(map[string]int64),
+		URLStressResults:           make(map[string]int64),
+		RedirectChains:             make(map[string]int64),
+		ErrorSamples:               make(map[string][]ErrorSample),
+	}
+
+	s.mu.RLock()
+	snap.StartTime = s.StartTime
+	for k, v := range s.ErrorTypes {
+		snap.ErrorTypes[k] = v
+	}
+	for k, v := range s.AssertionResults {
+		snap.AssertionResults[k] = v
+	}
+	for k, v := range s.GRPCStatusCodes {
+		snap.GRPCStatusCodes[k] = v
+	}
+	for k, v := range s.WSCloseEvents {
+		snap.WSCloseEvents[k] = v
+	}
+	for k, v := range s.TimeoutsByHost {
+		snap.TimeoutsByHost[k] = v
+	}
+	for k, v := range s.FuzzOutcomes {
+		snap.FuzzOutcomes[k] = v
+	}
+	for k, v := range s.HeaderStressResults {
+		snap.HeaderStressResults[k] = v
+	}
+	for k, v := range s.URLStressResults {
+		snap.URLStressResults[k] = v
+	}
+	for k, v := range s.RedirectChains {
+		snap.RedirectChains[k] = v
+	}
+	for k, v := range s.ErrorSamples {
+		snap.ErrorSamples[k] = append([]ErrorSample(nil), v...)
+	}
+	snap.ResponseTimes = make([]time.Duration, len(s.ResponseTimes))
+	copy(snap.ResponseTimes, s.ResponseTimes)
+	s.mu.RUnlock()
+
+	return snap
+}
+
 type RequestCache struct {
 	URLs     []string
 	Payloads [][]byte
@@ -443,6 +647,7 @@ var contentTypes = []string{
 }
 
 func init() {
+	// 占位种子: 配置解析后若指定了-seed会重新用该值播种 (见applySeed)
 	mathrand.Seed(time.Now().UnixNano())
 	programStartTime = time.Now()
 	runtime.GOMAXPROCS(runtime.NumCPU())
@@ -465,37 +670,65 @@ func initLogFile() {
 }
 
 func loadTargetURLs() error {
+	// HAR回放模式: 场景和目标URL都来自HAR文件
+	if HARFile != "" {
+		return loadHARScenario()
+	}
+	// 二进制协议模式: 目标为 tcp:// 伪URL，仅作展示
+	if len(RawTCPTargets) > 0 {
+		for _, t := range RawTCPTargets {
+			TargetURLs = append(TargetURLs, "tcp://"+t.Address)
+		}
+		return nil
+	}
+	// 大文件模式: 目标就是那一个对象
+	if LargeFileURL != "" {
+		TargetURLs = append(TargetURLs, LargeFileURL)
+		return nil
+	}
+	// 表单模式: 目标为表单所在页面，具体提交地址运行时发现
+	if FormPageURL != "" {
+		TargetURLs = append(TargetURLs, FormPageURL)
+		return nil
+	}
+	// 场景模式: 目标URL来自步骤定义
+	if len(ScenarioSteps) > 0 {
+		for _, step := range ScenarioSteps {
+			TargetURLs = append(TargetURLs, step.URL)
+		}
+		return nil
+	}
+	// 访问日志回放模式: 生产流量重放到指定目标主机
+	if AccessLogFile != "" {
+		return loadAccessLogScenario()
+	}
+	// OpenAPI模式: 按文档操作生成合法请求
+	if OpenAPIFile != "" {
+		return loadOpenAPITargets()
+	}
+	// 配置了远程地址时从中心列表加载 (并按需周期刷新)
+	if TargetListURL != "" {
+		return loadRemoteTargetList()
+	}
+
 	file, err := os.Open("dependency.txt")
 	if err != nil {
 		return fmt.Errorf("无法打开dependency.txt文件: %v", err)
 	}
 	defer file.Close()
 	
-	scanner := bufio.NewScanner(file)
-	lineNum := 0
-	for scanner.Scan() {
-		lineNum++
-		line := strings.TrimSpace(scanner.Text())
-		if line != "" && !strings.HasPrefix(line, "#") {
-			if !strings.HasPrefix(line, "http://") && !strings.HasPrefix(line, "https://") {
-				log.Printf("警告: 跳过无效URL格式 (行 %d): %s", lineNum, line)
-				continue
-			}
-			
-			if _, err := url.ParseRequestURI(line); err != nil {
-				log.Printf("警告: 跳过无效URL (行 %d): %s", lineNum, line)
-				continue
-			}
-			TargetURLs = append(TargetURLs, line)
-		}
+	specs, urls, err := parseTargetList(file)
+	if err != nil {
+		return err
 	}
-	
-	if len(TargetURLs) == 0 {
+	if len(urls) == 0 {
 		return fmt.Errorf("dependency.txt文件中没有找到有效的URL")
 	}
-	
+	TargetURLs = urls
+	registerTargetSpecs(specs)
+
 	log.Printf("成功加载 %d 个目标URL", len(TargetURLs))
-	return scanner.Err()
+	return nil
 }
 
 // 优化的计数写入器
@@ -524,8 +757,12 @@ func generateRandomIP() string {
 
 // 优化的负载生成 - 提高性能
 func generateRandomPayload() []byte {
-	payloadType := mathrand.Intn(5) // 增加一种类型
-	
+	// 配置了负载模板时按模板展开，保证请求体贴合目标API的schema
+	if PayloadTemplate != "" {
+		return []byte(expandTemplateFuncs(PayloadTemplate))
+	}
+	payloadType := mathrand.Intn(6) // 增加一种类型
+
 	switch payloadType {
 	case 0: // 轻量JSON
 		data := map[string]interface{}{
@@ -536,6 +773,10 @@ func generateRandomPayload() []byte {
 		}
 		jsonData, _ := json.Marshal(data)
 		return jsonData
+
+	case 5: // 真实感用户数据 (通过服务端字段校验)
+		jsonData, _ := json.Marshal(fakeUserObject())
+		return jsonData
 		
 	case 1: // 表单数据
 		values := url.Values{}
@@ -566,14 +807,19 @@ func generateRandomPath() string {
 	if !EnableRandomPath {
 		return ""
 	}
-	
+
+	// 自定义词汇表优先，使路径匹配目标应用的实际路由
+	if len(customPaths) > 0 {
+		return customPaths[mathrand.Intn(len(customPaths))]
+	}
+
 	// 预定义路径池以提高性能
 	commonPaths := []string{
 		"api/v1/test", "api/v2/data", "user/profile", "admin/dashboard",
 		"public/assets", "private/data", "test/endpoint", "health/check",
 		"metrics/stats", "config/settings", "cache/clear", "auth/login",
 	}
-	
+
 	if mathrand.Float32() < 0.6 {
 		return commonPaths[mathrand.Intn(len(commonPaths))]
 	}
@@ -608,11 +854,17 @@ func generateRandomQueryParams() string {
 		"format":   {"json", "xml", "csv"},
 		"version":  {"v1", "v2", "latest"},
 	}
-	
+	keys := []string{"page", "limit", "sort", "filter", "format", "version"}
+
+	// 自定义参数词汇表优先
+	if len(customParams) > 0 {
+		commonParams = customParams
+		keys = customParamKeys
+	}
+
 	paramCount := mathrand.Intn(4) + 1
 	var params []string
-	
-	keys := []string{"page", "limit", "sort", "filter", "format", "version"}
+
 	used := make(map[string]bool)
 	
 	for i := 0; i < paramCount; i++ {
@@ -631,8 +883,25 @@ func generateRandomQueryParams() string {
 }
 
 func generateRandomURL() string {
-	baseURL := TargetURLs[mathrand.Intn(len(TargetURLs))]
-	
+	// 探索模式下以一定比例访问爬取发现的目标 (不再附加随机路径，保持URL可达)
+	if EnableTargetDiscovery && mathrand.Float32() < 0.3 {
+		if target := discovered.pick(); target != "" {
+			return target
+		}
+	}
+	// 按行内声明的权重采样基础URL (远程列表刷新时整体替换，读取需经快照)
+	urls := snapshotTargetURLs()
+	base := urls[mathrand.Intn(len(urls))]
+	if spec := pickTargetSpec(mathrand.Intn); spec != nil {
+		base = spec.URL
+	}
+	return generateRandomURLFrom(base)
+}
+
+// 基于指定的基础URL生成随机URL (亲和模式下协程只在自己绑定的目标上变化路径/参数)
+func generateRandomURLFrom(baseURL string) string {
+	// URL中的 {{函数}} 占位符先展开 (如 /users/{{uuid}}?ts={{now unix}})
+	baseURL = expandTemplateFuncs(baseURL)
 	if !EnableRandomPath && !EnableRandomQueryParams {
 		return baseURL
 	}
@@ -819,25 +1088,34 @@ func createOptimizedHTTPClient() *http.Client {
 			MinVersion:         uint16(MinTLSVersion),
 			MaxVersion:         uint16(MaxTLSVersion),
 			ClientSessionCache: nil,
+			KeyLogWriter:       tlsKeyLogWriter(),
 		}
 	} else {
 		tlsConfig = &tls.Config{
 			InsecureSkipVerify: IgnoreSSLErrors,
 			MinVersion:         uint16(MinTLSVersion),
 			MaxVersion:         uint16(MaxTLSVersion),
+			KeyLogWriter:       tlsKeyLogWriter(),
 		}
 		
 		if EnableSharedTLSSessionCache && globalTLSCache != nil {
 			tlsConfig.ClientSessionCache = globalTLSCache
 		}
 	}
-	
+
+	// mTLS身份池: 握手间轮换客户端证书
+	attachClientCertPool(tlsConfig)
+
+
+	dialChain := chaosDialContext(connectLimitedDial((&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: KeepAliveTimeout,
+		Control:   dialerControl(),
+	}).DialContext))
+
 	transport := &http.Transport{
-		Proxy: http.ProxyFromEnvironment,
-		DialContext: (&net.Dialer{
-			Timeout:   30 * time.Second,
-			KeepAlive: KeepAliveTimeout,
-		}).DialContext,
+		Proxy:       http.ProxyFromEnvironment,
+		DialContext: dialChain,
 		ForceAttemptHTTP2:     strings.Contains(HTTPVersions, "h2"),
 		MaxIdleConns:          MaxIdleConns,
 		MaxIdleConnsPerHost:   MaxIdleConnsPerHost,
@@ -849,10 +1127,17 @@ func createOptimizedHTTPClient() *http.Client {
 		DisableKeepAlives:     !EnableConnectionReuse,
 		DisableCompression:    !EnableCompression,
 	}
-	
+
+	// 流捕获: http连接在TCP层tap，https改走自定义TLS拨号在解密层tap
+	if FlowCaptureFile != "" {
+		transport.DialContext = flowCaptureDial(dialChain)
+		transport.DialTLSContext = flowCaptureTLSDial(dialChain, tlsConfig)
+	}
+
 	return &http.Client{
-		Transport: transport,
-		Timeout:   RequestTimeout,
+		Transport:     transport,
+		Timeout:       RequestTimeout,
+		CheckRedirect: checkRedirect,
 	}
 }
 
@@ -861,15 +1146,21 @@ func createHTTP3Client() *http.Client {
 		return nil
 	}
 	
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: IgnoreSSLErrors,
+		MinVersion:         uint16(MinTLSVersion),
+		MaxVersion:         uint16(MaxTLSVersion),
+		KeyLogWriter:       tlsKeyLogWriter(),
+	}
+	attachClientCertPool(tlsConfig)
+
 	return &http.Client{
 		Transport: &http3.RoundTripper{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: IgnoreSSLErrors,
-				MinVersion:         uint16(MinTLSVersion),
-				MaxVersion:         uint16(MaxTLSVersion),
-			},
+			QuicConfig:      buildQuicConfig(),
+			TLSClientConfig: tlsConfig,
 		},
-		Timeout: RequestTimeout,
+		Timeout:       RequestTimeout,
+		CheckRedirect: checkRedirect,
 	}
 }
 
@@ -898,10 +1189,34 @@ func initRequestCache(cache *RequestCache) {
 		go func(start, end int) {
 			defer wg.Done()
 			for j := start; j < end; j++ {
+				// 带显式定义 (方法/请求体/头/协议) 的目标按行内声明原样生成
+				if spec := pickTargetSpec(mathrand.Intn); spec != nil && spec.explicit() {
+					cache.URLs[j] = spec.URL
+					if spec.Body != nil {
+						cache.Payloads[j] = spec.Body
+					} else {
+						cache.Payloads[j] = generateRandomPayload()
+					}
+					headers := generateRandomHeaders()
+					for k, v := range spec.Headers {
+						headers[k] = v
+					}
+					cache.Headers[j] = headers
+					switch {
+					case spec.Method != "":
+						cache.Methods[j] = spec.Method
+					case UseRandomMethod:
+						cache.Methods[j] = httpMethods[mathrand.Intn(len(httpMethods))]
+					default:
+						cache.Methods[j] = "GET"
+					}
+					continue
+				}
+
 				cache.URLs[j] = generateRandomURL()
 				cache.Payloads[j] = generateRandomPayload()
 				cache.Headers[j] = generateRandomHeaders()
-				
+
 				if UseRandomMethod {
 					cache.Methods[j] = httpMethods[mathrand.Intn(len(httpMethods))]
 				} else {
@@ -916,6 +1231,12 @@ func initRequestCache(cache *RequestCache) {
 }
 
 func getFromCache(cache *RequestCache) (string, []byte, map[string]string, string) {
+	// 脚本钩子接管请求构建 (脚本完全控制URL/方法/头/体，出错时回落默认逻辑)
+	if scriptBuildRequest != nil {
+		if url, payload, headers, method, ok := scriptNextRequest(); ok {
+			return url, payload, headers, method
+		}
+	}
 	index := atomic.AddInt64(&cache.index, 1) % int64(CacheSize)
 	
 	cache.mu.RLock()
@@ -932,6 +1253,11 @@ func getFromCache(cache *RequestCache) (string, []byte, map[string]string, strin
 			newHeaders["Cookie"] = cookieStr
 		}
 	}
+
+	// 鉴权: OAuth缓存令牌优先，其次静态Bearer/Basic配置
+	if v := authorizationValue(); v != "" {
+		newHeaders["Authorization"] = v
+	}
 	
 	// 降低缓存更新频率以提高性能
 	if mathrand.Float32() < 0.01 { // 1% 概率更新
@@ -1039,6 +1365,9 @@ func recordError(stats *Stats, errType string) {
 }
 
 func recordResponseTime(stats *Stats, duration time.Duration) {
+	rollingLatency.Record(duration)
+	recordStreamingDuration(duration)
+
 	stats.mu.Lock()
 	stats.ResponseTimes = append(stats.ResponseTimes, duration)
 	if len(stats.ResponseTimes) > 50000 { // 增大响应时间样本
@@ -1047,15 +1376,55 @@ func recordResponseTime(stats *Stats, duration time.Duration) {
 	stats.mu.Unlock()
 }
 
+// 常见WebSocket关闭码含义
+var wsCloseCodeNames = map[int]string{
+	websocket.CloseNormalClosure:           "正常关闭",
+	websocket.CloseGoingAway:               "服务端离开",
+	websocket.CloseProtocolError:           "协议错误",
+	websocket.CloseUnsupportedData:         "不支持的数据",
+	websocket.CloseAbnormalClosure:         "异常断开",
+	websocket.CloseMessageTooBig:           "消息过大",
+	websocket.ClosePolicyViolation:         "策略违规",
+	websocket.CloseInternalServerErr:       "服务端内部错误",
+	websocket.CloseServiceRestart:          "服务重启",
+	websocket.CloseTryAgainLater:           "稍后重试",
+}
+
+// 归类一次WS断连/读写错误: 服务端关闭帧带码记录，否则按读写错误类别记录
+func recordWSDisconnect(stats *Stats, op string, err error) {
+	var key string
+	if ce, ok := err.(*websocket.CloseError); ok {
+		name := wsCloseCodeNames[ce.Code]
+		if name == "" {
+			name = "未知关闭码"
+		}
+		key = fmt.Sprintf("关闭帧_%d_%s", ce.Code, name)
+	} else if strings.Contains(err.Error(), "timeout") {
+		key = op + "超时"
+	} else {
+		key = op + "错误"
+	}
+
+	stats.mu.Lock()
+	if stats.WSCloseEvents == nil {
+		stats.WSCloseEvents = make(map[string]int64)
+	}
+	stats.WSCloseEvents[key]++
+	stats.mu.Unlock()
+}
+
 // 优化的WebSocket处理
 func makeWebSocketRequest(url string, headers map[string]string, stats *Stats, mode TestMode) {
+	wsTLSConfig := &tls.Config{
+		InsecureSkipVerify: IgnoreSSLErrors,
+		MinVersion:         uint16(MinTLSVersion),
+		MaxVersion:         uint16(MaxTLSVersion),
+		KeyLogWriter:       tlsKeyLogWriter(),
+	}
+	attachClientCertPool(wsTLSConfig)
 	dialer := websocket.Dialer{
-		HandshakeTimeout: 30 * time.Second,
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: IgnoreSSLErrors,
-			MinVersion:         uint16(MinTLSVersion),
-			MaxVersion:         uint16(MaxTLSVersion),
-		},
+		HandshakeTimeout:  30 * time.Second,
+		TLSClientConfig:   wsTLSConfig,
 		EnableCompression: EnableCompression,
 	}
 	
@@ -1066,6 +1435,7 @@ func makeWebSocketRequest(url string, headers map[string]string, stats *Stats, m
 	for k, v := range headers {
 		wsHeaders.Set(k, v)
 	}
+	wsHeaders.Set(MarkerHeaderName, MarkerHeaderValue)
 	
 	startTime := time.Now()
 	conn, resp, err := dialer.Dial(wsURL, wsHeaders)
@@ -1081,6 +1451,7 @@ func makeWebSocketRequest(url string, headers map[string]string, stats *Stats, m
 	
 	if resp != nil {
 		recordResponseTime(stats, time.Since(startTime))
+		recordLatencySketch(ProtocolWebSocket, time.Since(startTime))
 		if resp.StatusCode != 101 {
 			atomic.AddInt64(&stats.Non200Responses, 1)
 			recordError(stats, fmt.Sprintf("WebSocket_HTTP_%d", resp.StatusCode))
@@ -1099,27 +1470,37 @@ func makeWebSocketRequest(url string, headers map[string]string, stats *Stats, m
 	if err := conn.WriteJSON(testMessage); err != nil {
 		atomic.AddInt64(&stats.FailedRequests, 1)
 		recordError(stats, "WebSocket发送失败")
+		recordWSDisconnect(stats, "写", err)
 		return
 	}
-	
+
 	switch mode {
 	case ModeOneByte:
 		conn.SetReadDeadline(time.Now().Add(time.Second))
-		_, _, _ = conn.ReadMessage()
+		if _, _, err := conn.ReadMessage(); err != nil {
+			recordWSDisconnect(stats, "读", err)
+		}
 		atomic.AddInt64(&stats.OneByteModeConns, 1)
 	case ModeSlowReceive:
 		for i := 0; i < 3; i++ {
 			conn.SetReadDeadline(time.Now().Add(2 * time.Second))
 			_, _, err := conn.ReadMessage()
 			if err != nil {
+				recordWSDisconnect(stats, "读", err)
 				break
 			}
 			time.Sleep(time.Duration(mathrand.Intn(1000)+300) * time.Millisecond)
 		}
 		atomic.AddInt64(&stats.SlowReceiveConns, 1)
 	case ModeHangUp:
+		holdFor := time.Duration(mathrand.Intn(180)+30) * time.Second
+		if !holdBudget.acquire(holdFor) {
+			atomic.AddInt64(&stats.BudgetDeniedHolds, 1)
+			return
+		}
 		atomic.AddInt64(&stats.HangingConnections, 1)
-		time.Sleep(time.Duration(mathrand.Intn(180)+30) * time.Second)
+		time.Sleep(holdFor)
+		holdBudget.release()
 	default:
 		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
 		_, message, err := conn.ReadMessage()
@@ -1128,6 +1509,7 @@ func makeWebSocketRequest(url string, headers map[string]string, stats *Stats, m
 			atomic.AddInt64(&stats.TotalResponseSize, int64(len(message)))
 		} else {
 			recordError(stats, "WebSocket读取失败")
+			recordWSDisconnect(stats, "读", err)
 		}
 	}
 }
@@ -1167,28 +1549,65 @@ func makeGRPCRequest(target string, stats *Stats) {
 		return
 	}
 	defer conn.Close()
-	
-	recordResponseTime(stats, time.Since(startTime))
-	
-	ctx = metadata.NewOutgoingContext(ctx,
-		metadata.Pairs(
-			"user-agent", generateRandomUserAgent(),
-			"request-id", fmt.Sprintf("%d", mathrand.Int63())))
+
+	grpcMD := metadata.Pairs(
+		"user-agent", generateRandomUserAgent(),
+		"request-id", fmt.Sprintf("%d", mathrand.Int63()),
+		strings.ToLower(MarkerHeaderName), MarkerHeaderValue)
+	if v := authorizationValue(); v != "" {
+		grpcMD.Set("authorization", v)
+	}
+	ctx = metadata.NewOutgoingContext(ctx, grpcMD)
 
 	atomic.AddInt64(&stats.GRPCRequests, 1)
 	atomic.AddInt64(&stats.TotalRequests, 1)
-	atomic.AddInt64(&stats.TotalResponses, 1)
+
+	// 发起真实RPC (标准健康检查服务)，按gRPC状态码归类，与HTTP状态统计对应
+	healthClient := grpc_health_v1.NewHealthClient(conn)
+	_, rpcErr := healthClient.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	recordResponseTime(stats, time.Since(startTime))
+	recordLatencySketch(ProtocolGRPC, time.Since(startTime))
+
+	st, _ := status.FromError(rpcErr)
+	code := st.Code()
+	recordGRPCStatus(stats, code.String())
+
+	switch code {
+	case codes.OK:
+		atomic.AddInt64(&stats.TotalResponses, 1)
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
+		// 传输层面失败: 与HTTP请求执行失败同等计入错误率
+		atomic.AddInt64(&stats.FailedRequests, 1)
+		if code == codes.DeadlineExceeded {
+			atomic.AddInt64(&stats.TimeoutCount, 1)
+		}
+		recordError(stats, "gRPC_"+code.String())
+	default:
+		// 服务端返回了非OK状态 (如Unimplemented): 对应HTTP非2xx
+		atomic.AddInt64(&stats.Non200Responses, 1)
+		recordError(stats, "gRPC_"+code.String())
+	}
 }
 
-func makeHTTP3Request(client *http.Client, method, url string, payload []byte, headers map[string]string, stats *Stats, mode TestMode) {
+// 记录gRPC状态码分布
+func recordGRPCStatus(stats *Stats, code string) {
+	stats.mu.Lock()
+	if stats.GRPCStatusCodes == nil {
+		stats.GRPCStatusCodes = make(map[string]int64)
+	}
+	stats.GRPCStatusCodes[code]++
+	stats.mu.Unlock()
+}
+
+func makeHTTP3Request(ctx context.Context, client *http.Client, method, url string, payload []byte, headers map[string]string, stats *Stats, mode TestMode) {
 	if client == nil {
 		atomic.AddInt64(&stats.FailedRequests, 1)
 		recordError(stats, "HTTP3客户端未初始化")
 		return
 	}
-	
+
 	atomic.AddInt64(&stats.HTTP3Requests, 1)
-	makeHTTPRequest(client, method, url, payload, headers, stats, mode)
+	makeHTTPRequest(ctx, client, method, url, payload, headers, stats, mode)
 }
 
 // 分块读取器
@@ -1204,7 +1623,7 @@ func (c *ChunkedReader) Read(p []byte) (n int, err error) {
 }
 
 // 核心HTTP请求处理 - 高度优化版本
-func makeHTTPRequest(client *http.Client, method, url string, payload []byte, headers map[string]string, stats *Stats, mode TestMode) {
+func makeHTTPRequest(ctx context.Context, client *http.Client, method, url string, payload []byte, headers map[string]string, stats *Stats, mode TestMode) {
 	var req *http.Request
 	var err error
 
@@ -1246,29 +1665,75 @@ func makeHTTPRequest(client *http.Client, method, url string, payload []byte, he
 		req.Header.Set(key, value)
 	}
 
-	resp, err := client.Do(req)
+	// 标记头: 供服务端识别并过滤压测流量
+	req.Header.Set(MarkerHeaderName, MarkerHeaderValue)
+
+	// 目标级鉴权头: 按URL子串规则附加 (如各目标独立的X-API-Key)
+	if len(TargetAuthRules) > 0 {
+		applyTargetAuthHeaders(url, req.Header.Set)
+	}
+
+	// 独立会话模式: 套用本协程虚拟用户的粘性UA和专属Cookie
+	vu := virtualUserFrom(ctx)
+	if vu != nil {
+		vu.applyTo(req)
+	}
+
+	// 发送前按注册顺序应用变换中间件 (签名/注头/改写请求体)
+	if err := applyRequestMiddlewares(req); err != nil {
+		atomic.AddInt64(&stats.FailedRequests, 1)
+		recordError(stats, "中间件处理失败")
+		recordErrorSample(stats, "中间件处理失败", url, err.Error(), 0)
+		return
+	}
+
+	tracedCtx, remoteIP := withIPTrace(withPhaseTrace(withConnTrackTrace(withTLSHandshakeTrace(ctx))))
+	resp, err := client.Do(req.WithContext(tracedCtx))
 	requestDuration := time.Since(startTime)
 	
 	if err != nil {
 		atomic.AddInt64(&stats.FailedRequests, 1)
-		
+
+		errType := "请求执行失败"
 		if strings.Contains(err.Error(), "timeout") {
 			atomic.AddInt64(&stats.TimeoutCount, 1)
-			recordError(stats, "请求超时")
+			recordTimeoutHost(stats, url)
+			errType = "请求超时"
 		} else if strings.Contains(err.Error(), "connection refused") {
-			recordError(stats, "连接被拒绝")
+			errType = "连接被拒绝"
 		} else if strings.Contains(err.Error(), "no such host") {
-			recordError(stats, "主机不存在")
-		} else {
-			recordError(stats, "请求执行失败")
+			errType = "主机不存在"
 		}
+		recordError(stats, errType)
+		recordErrorSample(stats, errType, url, err.Error(), 0)
 		return
 	}
 
 	atomic.AddInt64(&stats.TotalRequests, 1)
 	recordResponseTime(stats, requestDuration)
+	recordIPLatency(remoteIP, requestDuration)
 
-	if EnableFixedHeaders {
+	// 延迟进入统一草图管道 (HTTP3经由同一路径，按响应协议版本区分)
+	sketchProto := ProtocolHTTP
+	if resp.ProtoMajor == 3 {
+		sketchProto = ProtocolHTTP3
+	}
+	recordLatencySketch(sketchProto, requestDuration)
+	recordTargetSketch(url, requestDuration)
+
+	// 回溯重定向链，统计本次请求实际跟随的跳数
+	recordRedirectChain(stats, resp)
+
+	// 通知注册的响应观察者 (只读，不消费Body)
+	notifyResponseObservers(resp, requestDuration)
+
+	if vu != nil {
+		// 独立会话模式: Set-Cookie进本用户的jar，不落全局
+		vu.capture(resp)
+		if len(resp.Cookies()) > 0 {
+			atomic.AddInt64(&stats.CookieUpdates, 1)
+		}
+	} else if EnableFixedHeaders {
 		extractAndSaveCookies(resp)
 		if len(resp.Cookies()) > 0 {
 			atomic.AddInt64(&stats.CookieUpdates, 1)
@@ -1287,16 +1752,55 @@ func makeHTTPRequest(client *http.Client, method, url string, payload []byte, he
 		if EnableRateLimit {
 			reader = NewRateLimitedReader(resp.Body)
 		}
-		
+
 		counter := &countingWriter{}
-		io.Copy(counter, reader)
+		var dst io.Writer = counter
+		hasher := newContentHasher(resp)
+		if hasher != nil {
+			dst = io.MultiWriter(counter, hasher)
+		}
+		// 断言、目标发现或schema校验需要检查响应体时截取前64KB
+		var bodyCapture *limitedCaptureWriter
+		var schemaRule *responseSchema
+		if len(responseSchemas) > 0 && mathrand.Intn(100) < SchemaSamplePct {
+			schemaRule = schemaForURL(url)
+		}
+		if EnableTargetDiscovery || schemaRule != nil || (EnablePageAssets && isHTMLResponse(resp)) || (len(Assertions) > 0 && assertionsNeedBody(url)) {
+			bodyCapture = &limitedCaptureWriter{}
+			dst = io.MultiWriter(dst, bodyCapture)
+		}
+		io.Copy(dst, reader)
 		atomic.AddInt64(&stats.TotalResponseSize, counter.count)
-		
+		verifyContent(resp, counter.count, hasher, stats)
+		var body []byte
+		if bodyCapture != nil {
+			body = bodyCapture.buf.Bytes()
+		}
+		if len(Assertions) > 0 {
+			checkAssertions(stats, url, resp.StatusCode, time.Since(startTime), body)
+		}
+		if schemaRule != nil && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			validateResponseSchema(stats, schemaRule, url, body)
+		}
+		discoverTargets(url, resp, body)
+
+		// 页面加载模式: 解析HTML内嵌资源并并行抓取，记录复合耗时
+		if EnablePageAssets && isHTMLResponse(resp) && resp.StatusCode < 300 && len(body) > 0 {
+			fetchPageAssets(ctx, client, url, requestDuration, body, stats)
+		}
+
+
 		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
 			atomic.AddInt64(&stats.TotalResponses, 1)
+		} else if resp.StatusCode >= 300 && resp.StatusCode < 400 {
+			// 终态3xx: 策略不跟随时的预期结果，独立归类不计入错误
+			atomic.AddInt64(&stats.Redirect3xxResponses, 1)
+			atomic.AddInt64(&stats.Non200Responses, 1)
 		} else {
 			atomic.AddInt64(&stats.Non200Responses, 1)
-			recordError(stats, fmt.Sprintf("HTTP_%d", resp.StatusCode))
+			errType := fmt.Sprintf("HTTP_%d", resp.StatusCode)
+			recordError(stats, errType)
+			recordErrorSample(stats, errType, url, resp.Status, resp.StatusCode)
 		}
 		
 	case ModeOneByte:
@@ -1304,109 +1808,239 @@ func makeHTTPRequest(client *http.Client, method, url string, payload []byte, he
 		atomic.AddInt64(&stats.OneByteModeConns, 1)
 		
 	case ModeSlowReceive:
-		io.Copy(io.Discard, NewSlowReader(resp.Body))
+		// 慢速接收同样占用服务端连接，超预算时退化为正常速度排空
+		if holdBudget.acquire(10 * time.Second) {
+			io.Copy(io.Discard, NewSlowReader(resp.Body))
+			holdBudget.release()
+		} else {
+			atomic.AddInt64(&stats.BudgetDeniedHolds, 1)
+			io.Copy(io.Discard, resp.Body)
+		}
 		atomic.AddInt64(&stats.SlowReceiveConns, 1)
 		
 	case ModeHangUp:
+		holdFor := time.Duration(mathrand.Intn(180)+30) * time.Second
+		if !holdBudget.acquire(holdFor) {
+			atomic.AddInt64(&stats.BudgetDeniedHolds, 1)
+			return
+		}
 		atomic.AddInt64(&stats.HangingConnections, 1)
-		time.Sleep(time.Duration(mathrand.Intn(180)+30) * time.Second)
+		time.Sleep(holdFor)
+		holdBudget.release()
 		return
 	}
 }
 
 // 优化的工作协程
-func worker(workerID int, cache *RequestCache, stats *Stats, httpClient, http3Client *http.Client, wg *sync.WaitGroup) {
+func worker(ctx context.Context, workerID int, cache *RequestCache, stats *Stats, httpClient, http3Client *http.Client, wg *sync.WaitGroup) {
 	defer wg.Done()
 	
 	requestCount := TotalDownloads / NumConcurrentWorkers
 	if workerID < TotalDownloads%NumConcurrentWorkers {
 		requestCount++
 	}
-	
-	for i := 0; i < requestCount; i++ {
+
+	// 协程私有RNG: 由全局种子和workerID派生，各协程的随机决策互不干扰，
+	// 相同种子下每个协程的序列可独立复现 (全局RNG受并发调度影响无法保证这一点)
+	rng := mathrand.New(mathrand.NewSource(RandomSeed + int64(workerID)*2654435761))
+
+	// 亲和模式: 协程启动时绑定固定的目标子集，整个生命周期内只访问这些目标
+	var pinnedTargets []string
+	if affinityURLs := snapshotTargetURLs(); EnableWorkerAffinity && len(affinityURLs) > 0 {
+		n := WorkerAffinityTargets
+		if n <= 0 || n > len(affinityURLs) {
+			n = 1
+		}
+		start := workerID % len(affinityURLs)
+		for k := 0; k < n; k++ {
+			pinnedTargets = append(pinnedTargets, affinityURLs[(start+k)%len(affinityURLs)])
+		}
+	}
+
+	// 独立会话模式: 本协程扮演一个固定的虚拟用户，cookie不与其他协程共享；
+	// 配置了登录流程时进入主循环前先登录换取本用户的令牌
+	if EnableUserSessions || loginConfigured() {
+		vu := newVirtualUser(workerID)
+		vu.login(httpClient)
+		ctx = withVirtualUser(ctx, vu)
+	}
+
+	durationMode := TestDuration > 0
+
+	for i := 0; durationMode || i < requestCount; i++ {
+		// 收到终止信号后不再发起新请求
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		// 暂停状态下挂起，保持连接温热
+		waitWhilePaused(ctx)
+
+		// 时长模式: 到达截止时间后结束
+		if durationMode && time.Now().After(testDeadline) {
+			return
+		}
+
+		// 代理模式下受控制器动态分配的预算约束
+		if !agentBudgetAllows() {
+			return
+		}
+
 		url, payload, headers, method := getFromCache(cache)
-		
-		// 优化的协议选择逻辑
-		protocolChoice := mathrand.Intn(100)
-		
-		if EnableHTTP3 && protocolChoice < 5 && http3Client != nil {
-			makeHTTP3Request(http3Client, method, url, payload, headers, stats, SelectedTestMode)
-		} else if EnableWebSocket && protocolChoice < 15 {
-			makeWebSocketRequest(url, headers, stats, SelectedTestMode)
-		} else if EnableGRPC && protocolChoice < 20 {
-			makeGRPCRequest(url, stats)
-		} else {
-			makeHTTPRequest(httpClient, method, url, payload, headers, stats, SelectedTestMode)
+
+		if len(pinnedTargets) > 0 {
+			url = generateRandomURLFrom(pinnedTargets[rng.Intn(len(pinnedTargets))])
 		}
-		
+
+		iterStart := time.Now()
+		dispatchRequest(ctx, httpClient, http3Client, method, url, payload, headers, stats)
+
+		// 配速与协调遗漏补偿
+		if PacingInterval > 0 {
+			elapsed := time.Since(iterStart)
+			if elapsed > PacingInterval {
+				// 响应慢于预定配速时，按HdrHistogram方式补录被"遗漏"的采样，
+				// 避免闭环测量得到系统性偏乐观的延迟百分位
+				for corrected := elapsed - PacingInterval; corrected > 0; corrected -= PacingInterval {
+					recordResponseTime(stats, corrected)
+					recordLatencySketch(ProtocolHTTP, corrected)
+					atomic.AddInt64(&stats.CoordinatedOmissionSamples, 1)
+				}
+			} else {
+				time.Sleep(PacingInterval - elapsed)
+			}
+			continue
+		}
+
 		// 减少不必要的延迟
-		if mathrand.Float32() < 0.05 {
-			time.Sleep(time.Duration(mathrand.Intn(50)+10) * time.Millisecond)
+		if rng.Float32() < 0.05 {
+			time.Sleep(time.Duration(rng.Intn(50)+10) * time.Millisecond)
+		}
+	}
+}
+
+// 按功能开关和协议权重把一次请求派发到对应的协议处理函数
+func dispatchRequest(ctx context.Context, httpClient, http3Client *http.Client, method, url string, payload []byte, headers map[string]string, stats *Stats) {
+	// URL过滤: 危险端点在发出前拦截
+	if urlFilteringActive() {
+		if ok, reason := urlAllowed(url); !ok {
+			atomic.AddInt64(&stats.FilteredRequests, 1)
+			recordErrorSample(stats, "URL被过滤拦截", url, reason, 0)
+			return
+		}
+	}
+
+	// 按配置比例发送畸形请求
+	if shouldFuzz() {
+		makeFuzzedHTTPRequest(httpClient, method, url, payload, headers, stats)
+		return
+	}
+
+	// 按配置比例进行头部大小压测
+	if shouldHeaderStress() {
+		makeHeaderStressRequest(httpClient, url, headers, stats)
+		return
+	}
+
+	// 按配置比例进行URL长度压测
+	if shouldURLStress() {
+		makeURLStressRequest(httpClient, url, headers, stats)
+		return
+	}
+
+	// 协议选择走执行器注册表 (阶段计划可覆盖测试模式与协议权重)
+	r := &protocolRequest{
+		ctx:         ctx,
+		httpClient:  httpClient,
+		http3Client: http3Client,
+		method:      method,
+		url:         url,
+		payload:     payload,
+		headers:     headers,
+		stats:       stats,
+		mode:        activeTestMode(),
+	}
+
+	// 目标行内proto=指令强制协议，跳过权重选择
+	if proto, ok := forcedProtocolFor(url); ok {
+		if ex := executorForProtocol(proto); ex != nil {
+			ex.run(r)
+			return
 		}
 	}
+
+	selectProtocolExecutor(mathrand.Intn(100)).run(r)
 }
 
 // 优化的统计显示
-func printStats(stats *Stats) {
-	stats.mu.RLock()
-	defer stats.mu.RUnlock()
+func printStats(stats *StatsSnapshot) {
 	
 	elapsed := time.Since(stats.StartTime)
-	totalReq := atomic.LoadInt64(&stats.TotalRequests)
-	totalResp := atomic.LoadInt64(&stats.TotalResponses)
+	totalReq := stats.TotalRequests
+	totalResp := stats.TotalResponses
 	
 	fmt.Printf("\n=== 详细统计信息 ===\n")
 	fmt.Printf("运行时间: %v\n", elapsed)
 	fmt.Printf("总请求数: %d\n", totalReq)
 	fmt.Printf("成功响应: %d\n", totalResp)
-	fmt.Printf("非2xx响应: %d\n", atomic.LoadInt64(&stats.Non200Responses))
-	fmt.Printf("失败请求: %d\n", atomic.LoadInt64(&stats.FailedRequests))
-	fmt.Printf("超时次数: %d\n", atomic.LoadInt64(&stats.TimeoutCount))
-	fmt.Printf("响应总大小: %.2f MB\n", float64(atomic.LoadInt64(&stats.TotalResponseSize))/(1024*1024))
+	fmt.Printf("非2xx响应: %d\n", stats.Non200Responses)
+	fmt.Printf("失败请求: %d\n", stats.FailedRequests)
+	fmt.Printf("超时次数: %d\n", stats.TimeoutCount)
+
+	if co := stats.CoordinatedOmissionSamples; co > 0 {
+		fmt.Printf("协调遗漏补偿样本: %d (配速 %v 下有响应慢于间隔)\n", co, PacingInterval)
+	}
+	fmt.Printf("响应总大小: %.2f MB\n", float64(stats.TotalResponseSize)/(1024*1024))
+
+	if EnableContentVerification {
+		fmt.Printf("截断传输: %d\n", stats.TruncatedTransfers)
+		fmt.Printf("摘要不匹配: %d\n", stats.ChecksumMismatches)
+	}
 	
 	if totalReq > 0 {
 		qps := float64(totalReq) / elapsed.Seconds()
 		successRate := float64(totalResp) / float64(totalReq) * 100
 		fmt.Printf("请求速率: %.2f QPS\n", qps)
 		fmt.Printf("成功率: %.2f%%\n", successRate)
-		fmt.Printf("错误率: %.2f%%\n", float64(atomic.LoadInt64(&stats.FailedRequests))/float64(totalReq)*100)
+		fmt.Printf("错误率: %.2f%%\n", float64(stats.FailedRequests)/float64(totalReq)*100)
 	}
 	
-	// 响应时间统计
-	if len(stats.ResponseTimes) > 0 {
-		times := make([]time.Duration, len(stats.ResponseTimes))
-		copy(times, stats.ResponseTimes)
-		sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
-		
-		var total time.Duration
-		for _, rt := range times {
-			total += rt
-		}
-		
-		avg := total / time.Duration(len(times))
-		p50 := times[len(times)/2]
-		p95 := times[int(float64(len(times))*0.95)]
-		p99 := times[int(float64(len(times))*0.99)]
-		
+	// 响应时间统计: 流式聚合覆盖全程且无需排序，浸泡测试收尾不再卡在这里
+	if streamingCount() > 0 {
+		minDur, maxDur := streamingMinMax()
 		fmt.Printf("\n=== 响应时间分析 ===\n")
-		fmt.Printf("平均响应时间: %v\n", avg)
-		fmt.Printf("P50 响应时间: %v\n", p50)
-		fmt.Printf("P95 响应时间: %v\n", p95)
-		fmt.Printf("P99 响应时间: %v\n", p99)
-		fmt.Printf("最快响应: %v\n", times[0])
-		fmt.Printf("最慢响应: %v\n", times[len(times)-1])
+		fmt.Printf("平均响应时间: %v\n", streamingAvg())
+		fmt.Printf("P50 响应时间: %v\n", streamingQuantile(0.50))
+		fmt.Printf("P95 响应时间: %v\n", streamingQuantile(0.95))
+		fmt.Printf("P99 响应时间: %v\n", streamingQuantile(0.99))
+		fmt.Printf("最快响应: %v\n", minDur)
+		fmt.Printf("最慢响应: %v\n", maxDur)
 	}
 	
 	// 协议统计
 	fmt.Printf("\n=== 协议分布 ===\n")
-	fmt.Printf("HTTP请求: %d\n", totalReq-atomic.LoadInt64(&stats.WSRequests)-atomic.LoadInt64(&stats.GRPCRequests)-atomic.LoadInt64(&stats.HTTP3Requests))
-	if ws := atomic.LoadInt64(&stats.WSRequests); ws > 0 {
+	fmt.Printf("HTTP请求: %d\n", totalReq-stats.WSRequests-stats.GRPCRequests-stats.HTTP3Requests)
+	if ws := stats.WSRequests; ws > 0 {
 		fmt.Printf("WebSocket请求: %d\n", ws)
 	}
-	if grpc := atomic.LoadInt64(&stats.GRPCRequests); grpc > 0 {
+	if stats.FilteredRequests > 0 {
+		fmt.Printf("URL过滤拦截: %d\n", stats.FilteredRequests)
+	}
+	if ws := stats.WSRequests; ws > 0 && len(stats.WSCloseEvents) > 0 {
+		fmt.Printf("WebSocket断连分布:\n")
+		for reason, count := range stats.WSCloseEvents {
+			fmt.Printf("  %s: %d\n", reason, count)
+		}
+	}
+	if grpc := stats.GRPCRequests; grpc > 0 {
 		fmt.Printf("gRPC请求: %d\n", grpc)
+		for code, count := range stats.GRPCStatusCodes {
+			fmt.Printf("  gRPC状态 %s: %d\n", code, count)
+		}
 	}
-	if h3 := atomic.LoadInt64(&stats.HTTP3Requests); h3 > 0 {
+	if h3 := stats.HTTP3Requests; h3 > 0 {
 		fmt.Printf("HTTP/3请求: %d\n", h3)
 	}
 	
@@ -1427,6 +2061,11 @@ func printStats(stats *Stats) {
 		for errType, count := range stats.ErrorTypes {
 			percentage := float64(count) / float64(totalReq) * 100
 			fmt.Printf("%s: %d (%.2f%%)\n", errType, count, percentage)
+			// 附带首次出现的具体实例，便于直接排查
+			for _, sample := range stats.ErrorSamples[errType] {
+				fmt.Printf("    例: %s @ %s (%s)\n",
+					sample.URL, sample.Time.Format("15:04:05"), sample.Message)
+			}
 		}
 	}
 }
@@ -1454,11 +2093,25 @@ func progressMonitor(stats *Stats, done chan bool) {
 			intervalDuration := now.Sub(lastTime)
 			rps := float64(current-lastRequests) / intervalDuration.Seconds()
 			
-			progress := float64(current) / float64(TotalDownloads) * 100
 			successRate := float64(responses) / math.Max(float64(current), 1) * 100
-			
-			fmt.Printf("\r[进度] %.1f%% | 请求: %d/%d | RPS: %.1f | 成功率: %.1f%% | 失败: %d", 
-				progress, current, TotalDownloads, rps, successRate, failed)
+
+			// 最近10秒窗口的滚动延迟百分位
+			latencySuffix := ""
+			if p50, p95, p99, ok := rollingLatency.Percentiles(RollingWindowSec); ok {
+				latencySuffix = fmt.Sprintf(" | P50/P95/P99: %.0f/%.0f/%.0fms", p50, p95, p99)
+			}
+
+			if TestDuration > 0 {
+				// 时长模式: 进度按已运行时间计算
+				elapsed := time.Since(stats.StartTime)
+				progress := math.Min(elapsed.Seconds()/TestDuration.Seconds()*100, 100)
+				fmt.Printf("\r[进度] %.1f%% | 已运行: %v/%v | 请求: %d | RPS: %.1f | 成功率: %.1f%% | 失败: %d%s",
+					progress, elapsed.Round(time.Second), TestDuration, current, rps, successRate, failed, latencySuffix)
+			} else {
+				progress := float64(current) / float64(TotalDownloads) * 100
+				fmt.Printf("\r[进度] %.1f%% | 请求: %d/%d | RPS: %.1f | 成功率: %.1f%% | 失败: %d%s",
+					progress, current, TotalDownloads, rps, successRate, failed, latencySuffix)
+			}
 			
 			lastRequests = current
 			lastTime = now
@@ -1522,9 +2175,7 @@ func performHealthCheck() error {
 }
 
 // 保存详细报告 (包含评分)
-func saveDetailedReport(stats *Stats, totalDuration time.Duration, scoreCard *ScoreCard) {
-	stats.mu.RLock()
-	defer stats.mu.RUnlock()
+func saveDetailedReport(stats *StatsSnapshot, totalDuration time.Duration, scoreCard *ScoreCard) {
 	
 	var m runtime.MemStats
 	runtime.ReadMemStats(&m)
@@ -1539,8 +2190,10 @@ func saveDetailedReport(stats *Stats, totalDuration time.Duration, scoreCard *Sc
 			"cpu_cores":         runtime.NumCPU(),
 			"memory_usage_mb":   memUsageMB,
 			"active_goroutines": runtime.NumGoroutine(),
+			"environment":       runManifest(),
 		},
 		"test_config": map[string]interface{}{
+			"profile":               LoadProfile,
 			"total_downloads":        TotalDownloads,
 			"concurrent_workers":     NumConcurrentWorkers,
 			"cache_size":            CacheSize,
@@ -1553,23 +2206,28 @@ func saveDetailedReport(stats *Stats, totalDuration time.Duration, scoreCard *Sc
 			"use_random_method":     UseRandomMethod,
 			"enable_fixed_headers":  EnableFixedHeaders,
 			"http_versions":         HTTPVersions,
+			"autotune_optimal_workers": atomic.LoadInt64(&autoTuneOptimalWorkers),
+			"marker_header":         fmt.Sprintf("%s: %s", MarkerHeaderName, MarkerHeaderValue),
+			"random_seed":           RandomSeed,
 		},
 		"performance_results": map[string]interface{}{
-			"total_requests":       atomic.LoadInt64(&stats.TotalRequests),
-			"successful_responses": atomic.LoadInt64(&stats.TotalResponses),
-			"failed_requests":      atomic.LoadInt64(&stats.FailedRequests),
-			"timeout_count":        atomic.LoadInt64(&stats.TimeoutCount),
-			"total_response_size":  atomic.LoadInt64(&stats.TotalResponseSize),
-			"requests_per_second":  float64(atomic.LoadInt64(&stats.TotalRequests)) / totalDuration.Seconds(),
-			"success_rate_percent": float64(atomic.LoadInt64(&stats.TotalResponses)) / math.Max(float64(atomic.LoadInt64(&stats.TotalRequests)), 1) * 100,
-			"error_rate_percent":   float64(atomic.LoadInt64(&stats.FailedRequests)) / math.Max(float64(atomic.LoadInt64(&stats.TotalRequests)), 1) * 100,
+			"total_requests":       stats.TotalRequests,
+			"successful_responses": stats.TotalResponses,
+			"failed_requests":      stats.FailedRequests,
+			"timeout_count":        stats.TimeoutCount,
+			"total_response_size":  stats.TotalResponseSize,
+			"requests_per_second":  float64(stats.TotalRequests) / totalDuration.Seconds(),
+			"success_rate_percent": float64(stats.TotalResponses) / math.Max(float64(stats.TotalRequests), 1) * 100,
+			"error_rate_percent":   float64(stats.FailedRequests) / math.Max(float64(stats.TotalRequests), 1) * 100,
 		},
 		"protocol_stats": map[string]interface{}{
-			"http_requests":    atomic.LoadInt64(&stats.TotalRequests) - atomic.LoadInt64(&stats.WSRequests) - atomic.LoadInt64(&stats.GRPCRequests) - atomic.LoadInt64(&stats.HTTP3Requests),
-			"websocket_requests": atomic.LoadInt64(&stats.WSRequests),
-			"grpc_requests":    atomic.LoadInt64(&stats.GRPCRequests),
-			"http3_requests":   atomic.LoadInt64(&stats.HTTP3Requests),
-			"tls_connections":  atomic.LoadInt64(&stats.TLSConnections),
+			"http_requests":    stats.TotalRequests - stats.WSRequests - stats.GRPCRequests - stats.HTTP3Requests,
+			"websocket_requests": stats.WSRequests,
+			"grpc_requests":    stats.GRPCRequests,
+			"grpc_status_codes": stats.GRPCStatusCodes,
+			"ws_close_events":  stats.WSCloseEvents,
+			"http3_requests":   stats.HTTP3Requests,
+			"tls_connections":  stats.TLSConnections,
 		},
 		"score_card": map[string]interface{}{
 			"qps_score":           scoreCard.QPSScore,
@@ -1583,30 +2241,140 @@ func saveDetailedReport(stats *Stats, totalDuration time.Duration, scoreCard *Sc
 			"grade":              scoreCard.Grade,
 		},
 		"error_analysis": stats.ErrorTypes,
+		"error_samples":  stats.ErrorSamples,
+		"assertion_failures": stats.AssertionFailures,
+		"schema_violations":  stats.SchemaViolations,
+		"filtered_requests":  stats.FilteredRequests,
+		"assertion_results":  stats.AssertionResults,
+		"robustness": map[string]interface{}{
+			"fuzz_requests":         stats.FuzzRequests,
+			"fuzz_outcomes":         stats.FuzzOutcomes,
+			"header_stress_results": stats.HeaderStressResults,
+			"url_stress_results":    stats.URLStressResults,
+		},
 		"target_urls":    TargetURLs,
 		"timestamp":      time.Now().Format(time.RFC3339),
 	}
-	
-	// 响应时间统计
-	if len(stats.ResponseTimes) > 0 {
-		times := make([]time.Duration, len(stats.ResponseTimes))
-		copy(times, stats.ResponseTimes)
-		sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
-		
-		var total time.Duration
-		for _, rt := range times {
-			total += rt
+
+	// TLS握手延迟分析 (完整/复用分开)
+	if handshakes := tlsHandshakeAnalysis(); len(handshakes) > 0 {
+		report["tls_handshake_analysis"] = handshakes
+	}
+
+	// 阶段耗时分解 (DNS/建连/TTFB)
+	if phases := phaseAnalysis(); phases != nil {
+		report["phase_timing_analysis"] = phases
+	}
+	if byIP := ipLatencyAnalysis(); byIP != nil {
+		report["latency_by_ip"] = byIP
+	}
+	if len(runFindings) > 0 {
+		report["analysis_findings"] = runFindings
+	}
+	if sketches := sketchAnalysis(); sketches != nil {
+		report["latency_sketches"] = sketches
+	}
+	if util := executorUtilization(); util != nil {
+		report["executor_utilization"] = util
+	}
+	if ops := openapiAnalysis(); ops != nil {
+		report["openapi_operations"] = ops
+	}
+	if ryw := rywAnalysis(); ryw != nil {
+		report["read_your_writes"] = ryw
+	}
+	if steps := scenarioAnalysis(); steps != nil {
+		report["scenario_steps"] = steps
+	}
+	if raw := rawTCPAnalysis(); raw != nil {
+		report["raw_tcp_targets"] = raw
+	}
+	if lf := largeFileAnalysis(); lf != nil {
+		report["large_file"] = lf
+	}
+	if forms := formAnalysis(); forms != nil {
+		report["forms"] = forms
+	}
+	if pages := pageLoadAnalysis(); pages != nil {
+		report["page_load"] = pages
+	}
+	if rd := redirectAnalysis(stats); rd != nil {
+		report["redirects"] = rd
+	}
+	if flow := flowCaptureAnalysis(); flow != nil {
+		report["flow_capture"] = flow
+	}
+	if alerts := alertAnalysis(); alerts != nil {
+		report["alert_rules"] = alerts
+	}
+	if schemas := schemaAnalysis(); schemas != nil {
+		report["schema_validation"] = schemas
+	}
+
+	// 阶段化负载的分阶段统计
+	if len(stageResults) > 0 {
+		report["stage_results"] = stageResults
+	}
+
+	// 尖峰测试的逐次恢复时间
+	if len(spikeResults) > 0 {
+		report["spike_results"] = spikeResults
+	}
+
+	// 阶梯加压的各级结果与容量估计
+	if len(stepLoadResults) > 0 {
+		report["step_load_results"] = stepLoadResults
+		if capacity := stepLoadCapacity(); capacity != nil {
+			report["capacity_estimate"] = capacity
 		}
-		avg := total / time.Duration(len(times))
-		
+	}
+
+	// 动态发现的目标
+	if EnableTargetDiscovery {
+		report["discovered_targets"] = discovered.count()
+	}
+
+	// 客户端混沌注入
+	if chaos := chaosAnalysis(); chaos != nil {
+		report["chaos_injection"] = chaos
+	}
+
+	// 鉴权凭证管理: 令牌实际请求次数应远小于压测请求数
+	if authEnabled() {
+		report["auth_token_fetches"] = atomic.LoadInt64(&authTokenFetches)
+	}
+
+	// 建连限速
+	if ConnectRateLimit > 0 {
+		report["connects_rate_limited"] = atomic.LoadInt64(&connectsRateLimited)
+	}
+
+	// 连接复用与生命周期分析
+	if conns := connAnalysis(); conns != nil {
+		report["connection_analysis"] = conns
+	}
+
+	// 内存采样分析
+	if mem := memAnalysis(); mem != nil {
+		report["memory_analysis"] = mem
+	}
+
+	// FD与TCP状态采样序列
+	if fd := fdAnalysis(); fd != nil {
+		report["fd_analysis"] = fd
+	}
+	
+	// 响应时间统计: 流式聚合，收尾O(1)且覆盖全程
+	if streamingCount() > 0 {
+		minDur, maxDur := streamingMinMax()
 		report["response_time_analysis"] = map[string]interface{}{
-			"average_ms":    float64(avg.Nanoseconds()) / 1e6,
-			"min_ms":        float64(times[0].Nanoseconds()) / 1e6,
-			"max_ms":        float64(times[len(times)-1].Nanoseconds()) / 1e6,
-			"p50_ms":        float64(times[len(times)/2].Nanoseconds()) / 1e6,
-			"p95_ms":        float64(times[int(float64(len(times))*0.95)].Nanoseconds()) / 1e6,
-			"p99_ms":        float64(times[int(float64(len(times))*0.99)].Nanoseconds()) / 1e6,
-			"sample_count":  len(times),
+			"average_ms":   float64(streamingAvg().Nanoseconds()) / 1e6,
+			"min_ms":       float64(minDur.Nanoseconds()) / 1e6,
+			"max_ms":       float64(maxDur.Nanoseconds()) / 1e6,
+			"p50_ms":       float64(streamingQuantile(0.50).Nanoseconds()) / 1e6,
+			"p95_ms":       float64(streamingQuantile(0.95).Nanoseconds()) / 1e6,
+			"p99_ms":       float64(streamingQuantile(0.99).Nanoseconds()) / 1e6,
+			"sample_count": streamingCount(),
 		}
 	}
 	
@@ -1628,6 +2396,54 @@ func saveDetailedReport(stats *Stats, totalDuration time.Duration, scoreCard *Sc
 	log.Printf("详细报告已保存到: %s", fileName)
 }
 
+// SIGUSR1触发: 打印当前完整统计并写一份中间JSON快照，不影响测试继续运行
+func dumpIntermediateStats(stats *Stats, startTime time.Time) {
+	snap := stats.Snapshot()
+	elapsed := time.Since(startTime) - totalPausedTime()
+
+	fmt.Printf("\n=== 中间统计快照 (已运行 %v) ===\n", elapsed.Round(time.Second))
+	printStats(snap)
+
+	report := map[string]interface{}{
+		"snapshot_time":     time.Now().Format(time.RFC3339),
+		"elapsed_seconds":   elapsed.Seconds(),
+		"total_requests":    snap.TotalRequests,
+		"total_responses":   snap.TotalResponses,
+		"failed_requests":   snap.FailedRequests,
+		"non_200_responses": snap.Non200Responses,
+		"timeout_count":     snap.TimeoutCount,
+		"dropped_arrivals":  snap.DroppedArrivals,
+		"error_types":       snap.ErrorTypes,
+	}
+	if elapsed > 0 {
+		report["qps"] = float64(snap.TotalRequests) / elapsed.Seconds()
+	}
+	if streamingCount() > 0 {
+		report["latency"] = map[string]interface{}{
+			"avg_ms": float64(streamingAvg().Nanoseconds()) / 1e6,
+			"p50_ms": float64(streamingQuantile(0.50).Nanoseconds()) / 1e6,
+			"p95_ms": float64(streamingQuantile(0.95).Nanoseconds()) / 1e6,
+			"p99_ms": float64(streamingQuantile(0.99).Nanoseconds()) / 1e6,
+		}
+	}
+
+	fileName := fmt.Sprintf("snapshot_%s.json", time.Now().Format("20060102_150405"))
+	file, err := os.Create(fileName)
+	if err != nil {
+		log.Printf("无法创建中间快照文件: %v", err)
+		return
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Printf("无法保存中间快照: %v", err)
+		return
+	}
+	log.Printf("中间快照已保存到: %s", fileName)
+}
+
 // 创建示例配置文件
 func createSampleDependencyFile() {
 	if _, err := os.Stat("dependency.txt"); os.IsNotExist(err) {
@@ -1694,6 +2510,87 @@ func min(a, b int) int {
 
 // 主函数 - 集成评分系统
 func main() {
+	// 编排模式: 把测试分发到多台远程机器执行
+	if len(os.Args) > 2 && os.Args[1] == "orchestrate" {
+		if err := runOrchestrator(os.Args[2]); err != nil {
+			log.Fatalf("编排执行失败: %v", err)
+		}
+		return
+	}
+
+	// 矩阵模式: 按配置组合的笛卡尔积依次执行并输出对比
+	if len(os.Args) > 2 && os.Args[1] == "matrix" {
+		if err := runMatrix(os.Args[2]); err != nil {
+			log.Fatalf("矩阵执行失败: %v", err)
+		}
+		return
+	}
+
+	// 控制器模式: 接收代理注册/心跳并动态分配工作量
+	if len(os.Args) > 2 && os.Args[1] == "controller" {
+		if err := runController(os.Args[2]); err != nil {
+			log.Fatalf("控制器执行失败: %v", err)
+		}
+		return
+	}
+
+	// 守护进程模式: 常驻服务，经REST接口接收并运行测试任务 (可选第二参数为并发任务上限)
+	if len(os.Args) > 2 && os.Args[1] == "daemon" {
+		if len(os.Args) > 3 {
+			if n, err := strconv.Atoi(os.Args[3]); err == nil && n > 0 {
+				DaemonMaxConcurrentJobs = n
+			}
+		}
+		if err := runDaemon(os.Args[2]); err != nil {
+			log.Fatalf("守护进程启动失败: %v", err)
+		}
+		return
+	}
+
+	// 录制模式: 本地正向代理捕获流量，落盘为扩展dependency格式
+	if len(os.Args) > 2 && os.Args[1] == "record" {
+		outPath := "recorded.txt"
+		if len(os.Args) > 3 {
+			outPath = os.Args[3]
+		}
+		if err := runRecordProxy(os.Args[2], outPath); err != nil {
+			log.Fatalf("录制代理失败: %v", err)
+		}
+		return
+	}
+
+	// 查看器模式: 渲染一份已保存的详细报告 (可选第二参数指定监听地址)
+	if len(os.Args) > 2 && os.Args[1] == "view" {
+		addr := ""
+		if len(os.Args) > 3 {
+			addr = os.Args[3]
+		}
+		if err := runReportViewer(os.Args[2], addr); err != nil {
+			log.Fatalf("报告查看器启动失败: %v", err)
+		}
+		return
+	}
+
+	// 解析配置 (命令行 > 配置文件 > 默认值)；代理模式下跳过控制器URL参数
+	configArgs := os.Args[1:]
+	if len(os.Args) > 2 && os.Args[1] == "agent" {
+		configArgs = os.Args[3:]
+	}
+	if err := parseConfig(configArgs); err != nil {
+		log.Fatalf("配置解析失败: %v", err)
+	}
+
+	// 确定并公示本次运行的随机种子: 请求缓存在播种之后生成，
+	// 相同种子两次运行产生相同的URL/路径/载荷序列
+	if RandomSeed == 0 {
+		RandomSeed = time.Now().UnixNano()
+	}
+	mathrand.Seed(RandomSeed)
+	fmt.Printf("随机种子: %d (使用 -seed %d 可复现本次请求序列)\n", RandomSeed, RandomSeed)
+
+	// 注册配置驱动的请求变换中间件
+	registerBuiltinMiddlewares()
+
 	fmt.Printf("=== 网络流量测试工具 v2.1 (含评分系统) ===\n")
 	fmt.Printf("开始时间: %s\n", time.Now().Format("2006-01-02 15:04:05"))
 	fmt.Printf("Go版本: %s | CPU核心: %d | 最大并发: %d | 总请求: %d\n", 
@@ -1704,100 +2601,364 @@ func main() {
 		ModeOneByte: "单字节模式", ModeSlowReceive: "慢速接收模式",
 	}
 	fmt.Printf("测试模式: %s\n", modeNames[SelectedTestMode])
-	
-	createSampleDependencyFile()
-	
-	if err := validateConfiguration(); err != nil {
-		log.Fatalf("配置验证失败: %v", err)
+	printManifestSummary()
+	applyProcessTuning()
+
+	if err := initScripting(); err != nil {
+		log.Fatalf("初始化脚本钩子失败: %v", err)
 	}
-	
+
+	// 高并发前先确认FD限制足够，不够时尝试提升到硬限制
+	raiseFDLimit()
+
+	// 生成并公示本次运行的压测流量标记
+	initMarkerHeader()
+
+	createSampleDependencyFile()
+
 	initLogFile()
-	
+
+	// 应用层流量采样捕获文件
+	if err := initFlowCapture(); err != nil {
+		log.Fatalf("流捕获初始化失败: %v", err)
+	}
+
+	// 原始测量采样导出文件
+	if err := initSampleExport(); err != nil {
+		log.Fatalf("采样导出初始化失败: %v", err)
+	}
+
 	if err := loadTargetURLs(); err != nil {
 		log.Fatalf("加载目标URL失败: %v", err)
 	}
-	
-	if err := performHealthCheck(); err != nil {
-		log.Printf("健康检查失败: %v", err)
-		fmt.Print("是否继续? (y/N): ")
-		
-		reader := bufio.NewReader(os.Stdin)
-		input, _ := reader.ReadString('\n')
-		if strings.ToLower(strings.TrimSpace(input)) != "y" {
-			return
+
+	// 高并发模式下目标必须在允许名单中或经用户显式确认
+	enforceTargetAllowlist()
+
+	// 鉴权凭证在启动时取一次，运行中提前刷新 (演练模式不发出任何请求，跳过)
+	if !EnableDryRun {
+		if err := initAuthManager(); err != nil {
+			log.Fatalf("凭证管理初始化失败: %v", err)
 		}
 	}
-	
+
+	// mTLS客户端证书池
+	if err := loadClientCertPool(); err != nil {
+		log.Fatalf("加载客户端证书池失败: %v", err)
+	}
+
+	// 每用户登录流程的凭证文件
+	if loginConfigured() {
+		if err := loadLoginCredentials(); err != nil {
+			log.Fatalf("加载登录凭证失败: %v", err)
+		}
+	}
+
+	if err := loadVocabularies(); err != nil {
+		log.Fatalf("加载URL词汇表失败: %v", err)
+	}
+
+	// 原始TCP目标不是HTTP端点，跳过健康检查
+	if !EnableDryRun && len(RawTCPTargets) == 0 {
+		if err := performHealthCheck(); err != nil {
+			log.Printf("健康检查失败: %v", err)
+			fmt.Print("是否继续? (y/N): ")
+
+			reader := bufio.NewReader(os.Stdin)
+			input, _ := reader.ReadString('\n')
+			if strings.ToLower(strings.TrimSpace(input)) != "y" {
+				return
+			}
+		}
+	}
+
 	stats := &Stats{
 		StartTime:     time.Now(),
 		ErrorTypes:    make(map[string]int64),
 		ResponseTimes: make([]time.Duration, 0, 10000),
 	}
 	
+	// 代理模式: 注册到控制器并按其动态下发的预算执行
+	if len(os.Args) > 2 && os.Args[1] == "agent" {
+		if err := startAgentMode(os.Args[2], stats); err != nil {
+			log.Fatalf("代理模式启动失败: %v", err)
+		}
+	}
+
 	cache := &RequestCache{}
 	initRequestCache(cache)
 	
 	if err := initializeGlobalHeaders(); err != nil {
 		log.Printf("警告: 初始化Headers失败: %v", err)
 	}
-	
+
+	// 演练模式: 打印请求样本和协议占比后直接退出
+	if EnableDryRun {
+		printDryRun(cache)
+		return
+	}
+
 	httpClient := createOptimizedHTTPClient()
 	var http3Client *http.Client
 	if EnableHTTP3 {
 		http3Client = createHTTP3Client()
 	}
-	
+
+	// 校准通过: 短测量推算合适的并发数
+	if EnableCalibration {
+		runCalibration(context.Background(), cache, httpClient, http3Client)
+	}
+
 	fmt.Printf("\n=== 开始流量测试 ===\n")
 	fmt.Printf("目标URL: %d 个\n", len(TargetURLs))
 	
 	progressDone := make(chan bool, 1)
-	if EnableProgressBar {
+	if EnableTUI && stdoutIsTTY() {
+		// 全屏TUI替代单行进度条
+		go runTUI(stats, progressDone)
+	} else if EnableProgressBar {
 		go progressMonitor(stats, progressDone)
 	}
-	
+
+	// 实时仪表盘 (如已配置监听地址)
+	if DashboardAddr != "" {
+		startDashboard(stats)
+	}
+
+	// 捕获 SIGINT/SIGTERM: 第一次优雅停止并输出部分结果报告，第二次强制退出
+	ctx, cancel := context.WithCancel(context.Background())
+	sigChan := make(chan os.Signal, 2)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		fmt.Printf("\n收到终止信号，正在停止工作协程并生成部分结果报告... (再按一次强制退出)\n")
+		cancel()
+		<-sigChan
+		fmt.Println("\n强制退出")
+		os.Exit(1)
+	}()
+	defer cancel()
+
+	// SIGUSR2切换暂停/恢复 (仅Unix平台，见signals_unix.go)
+	pauseChan := make(chan os.Signal, 1)
+	notifyPauseSignal(pauseChan)
+	go func() {
+		for range pauseChan {
+			togglePause()
+		}
+	}()
+
+	// 后台持续采样MemStats，供峰值内存和GC暂停分析
+	startMemSampler(ctx)
+
+	// 后台采样FD数量与TCP状态分布
+	startFDSampler(ctx)
+
+	// 写后读一致性探测 (如已配置)
+	startRYWProber(ctx, httpClient)
+
+	// 每秒记录吞吐/错误/延迟时间序列，结束后导出CSV
+	startTimeSeriesRecorder(ctx, stats)
+
+	// 运行中告警规则评估 (abort动作通过cancel优雅终止)
+	startAlertEvaluator(ctx, stats, cancel)
+
+	// 常驻协程均已启动，记录泄漏检测基线
+	captureGoroutineBaseline()
+	captureCPUBaseline()
+
 	var wg sync.WaitGroup
 	startTime := time.Now()
-	
-	// 分批启动协程
-	batchSize := 50
-	for i := 0; i < NumConcurrentWorkers; i += batchSize {
-		end := min(i+batchSize, NumConcurrentWorkers)
-		
-		for j := i; j < end; j++ {
-			wg.Add(1)
-			go worker(j, cache, stats, httpClient, http3Client, &wg)
+
+	// SIGUSR1按需输出当前统计并落盘中间快照，多小时浸泡压测时无需中断即可巡检
+	statsChan := make(chan os.Signal, 1)
+	notifyStatsSignal(statsChan)
+	go func() {
+		for range statsChan {
+			dumpIntermediateStats(stats, startTime)
 		}
-		
-		if end < NumConcurrentWorkers {
-			time.Sleep(100 * time.Millisecond)
+	}()
+
+	if len(RawTCPTargets) > 0 {
+		// 二进制协议模式: 按hex模板发原始字节
+		if TestDuration > 0 {
+			testDeadline = startTime.Add(TestDuration)
+			fmt.Printf("运行时长: %v\n", TestDuration)
+		}
+		runRawTCPLoad(ctx, stats, &wg)
+	} else if LargeFileURL != "" {
+		// 大文件基准: 每次迭代下载一个完整对象
+		if TestDuration > 0 {
+			testDeadline = startTime.Add(TestDuration)
+			fmt.Printf("运行时长: %v\n", TestDuration)
+		}
+		runLargeFileLoad(ctx, stats, httpClient, &wg)
+	} else if FormPageURL != "" {
+		// 表单模式: 发现页面中的表单并按声明的method/enctype提交
+		if TestDuration > 0 {
+			testDeadline = startTime.Add(TestDuration)
+			fmt.Printf("运行时长: %v\n", TestDuration)
+		}
+		runFormLoad(ctx, stats, httpClient, &wg)
+	} else if len(harEntries) > 0 {
+		// HAR回放: 按录制场景的顺序和节奏发请求
+		if TestDuration > 0 {
+			testDeadline = startTime.Add(TestDuration)
+			fmt.Printf("运行时长: %v\n", TestDuration)
+		}
+		runHARReplay(ctx, stats, httpClient, &wg)
+	} else if len(ScenarioSteps) > 0 {
+		// 场景模式: 虚拟用户循环多步旅程
+		if TestDuration > 0 {
+			testDeadline = startTime.Add(TestDuration)
+			fmt.Printf("运行时长: %v\n", TestDuration)
+		}
+		runScenarioLoad(ctx, stats, httpClient, &wg)
+	} else if EnableAutoTune {
+		// 自适应并发: AIMD探测最大可持续并发，默认探测5分钟
+		if TestDuration <= 0 {
+			TestDuration = 5 * time.Minute
+		}
+		testDeadline = startTime.Add(TestDuration)
+		fmt.Printf("自适应并发探测时长: %v\n", TestDuration)
+		runAutoTuneLoad(ctx, cache, stats, httpClient, http3Client, &wg)
+	} else if ArrivalRate > 0 {
+		// 开放模型: 节拍器按恒定到达率派发
+		if TestDuration > 0 {
+			testDeadline = startTime.Add(TestDuration)
+			fmt.Printf("运行时长: %v\n", TestDuration)
+		}
+		runOpenModelLoad(ctx, cache, stats, httpClient, http3Client, &wg)
+	} else if StepLoadIncrement > 0 {
+		// 阶梯模式: 逐级加压直到越过阈值，估计容量拐点
+		if TestDuration <= 0 {
+			TestDuration = 30 * time.Minute // 安全上限，通常在越过阈值时提前结束
+		}
+		testDeadline = startTime.Add(TestDuration)
+		runStepLoad(ctx, cache, stats, httpClient, http3Client, &wg)
+	} else if SpikeMultiplier >= 2 {
+		// 尖峰模式: 基线与突发并发交替，测量恢复时间
+		if TestDuration <= 0 {
+			TestDuration = 5 * time.Minute
+		}
+		testDeadline = startTime.Add(TestDuration)
+		fmt.Printf("尖峰模式运行时长: %v\n", TestDuration)
+		runSpikeLoad(ctx, cache, stats, httpClient, http3Client, &wg)
+	} else if len(LoadStages) > 0 {
+		// 阶段模式: 协程按时长模式运行，截止时间为全部阶段结束
+		TestDuration = totalStageDuration()
+		testDeadline = startTime.Add(TestDuration)
+		fmt.Printf("阶段化负载: %d 个阶段，总时长 %v\n", len(LoadStages), TestDuration)
+		runStagedLoad(ctx, cache, stats, httpClient, http3Client, &wg)
+	} else {
+		if TestDuration > 0 {
+			testDeadline = startTime.Add(TestDuration)
+			fmt.Printf("运行时长: %v\n", TestDuration)
+		}
+
+		// 分批启动协程
+		batchSize := 50
+		for i := 0; i < NumConcurrentWorkers; i += batchSize {
+			end := min(i+batchSize, NumConcurrentWorkers)
+
+			for j := i; j < end; j++ {
+				wg.Add(1)
+				go worker(ctx, j, cache, stats, httpClient, http3Client, &wg)
+			}
+
+			if end < NumConcurrentWorkers {
+				time.Sleep(100 * time.Millisecond)
+			}
 		}
 	}
-	
+
 	wg.Wait()
 	
-	if EnableProgressBar {
+	if EnableProgressBar || (EnableTUI && stdoutIsTTY()) {
 		progressDone <- true
 	}
 	
 	totalDuration := time.Since(startTime)
-	
+
+	// 暂停时长不计入有效测试时间，否则QPS和评分会被稀释
+	if paused := totalPausedTime(); paused > 0 {
+		totalDuration -= paused
+		fmt.Printf("\n累计暂停时长: %v (已从有效时长中剔除)\n", paused.Round(time.Second))
+	}
+
 	fmt.Printf("\n=== 测试完成 ===\n")
 	fmt.Printf("总耗时: %v\n", totalDuration)
-	
-	printStats(stats)
-	
+
+	// 泄漏检测需在评分之前完成，结果会影响资源得分
+	detectGoroutineLeaks()
+	
+	// 所有输出路径统一消费同一份统计快照
+	snap := stats.Snapshot()
+
+	// 基于快照和时间序列做模式识别，结果进入终端建议和JSON报告
+	runFindings = analyzeRun(snap)
+
+	printStats(snap)
+	printAutoTuneSummary()
+	printOpenModelSummary(snap)
+	printStageReport()
+	printIdleProbeReport()
+	printLoginReport()
+	printScenarioReport()
+	printRawTCPReport()
+	printLargeFileReport()
+	printFormReport()
+	printPageLoadReport()
+	printRedirectReport(snap)
+	printFlowCaptureReport()
+	printAlertReport()
+	printScriptReport()
+	printSpikeReport()
+	printStepLoadReport()
+	printAssertionReport(snap)
+	printSchemaReport()
+	printTLSHandshakeStats()
+	printSketchReport()
+	printOpenAPIReport()
+	printExecutorReport()
+	printPhaseReport()
+	printIPLatencyReport()
+	printConnStats()
+	printRYWReport()
+	printMemReport()
+	printCPUReport()
+	printFDReport()
+	printBudgetReport(snap)
+	printFuzzReport(snap)
+
 	// 计算并显示评分
+	var scoreCard *ScoreCard
 	if EnableScoring {
 		var m runtime.MemStats
 		runtime.ReadMemStats(&m)
 		memUsageMB := float64(m.Alloc) / (1024 * 1024)
-		
-		scoreCard := calculateScore(stats, totalDuration, memUsageMB, runtime.NumGoroutine())
-		printScoreReport(scoreCard, stats, totalDuration)
-		
+
+		// 有采样数据时用全程峰值堆内存评分，比结束时的一次读数更诚实
+		if peak := memPeakHeapMB(); peak > memUsageMB {
+			memUsageMB = peak
+		}
+
+		scoreCard = calculateScore(snap, totalDuration, memUsageMB, runtime.NumGoroutine())
+		printScoreReport(scoreCard, snap, totalDuration)
+
 		// 保存包含评分的详细报告
-		saveDetailedReport(stats, totalDuration, scoreCard)
+		saveDetailedReport(snap, totalDuration, scoreCard)
 	}
+
+	// 导出每秒时间序列CSV
+	saveTimeSeriesCSV()
+	closeSampleExport()
+
+	// 按配置导出wrk/vegeta/k6兼容格式，既有工具链可直接消费
+	saveCompatOutputs(snap, totalDuration)
+
+	// 推送汇总指标到Pushgateway (如已配置)
+	pushSummaryMetrics(snap, totalDuration, scoreCard)
 	
 	// 性能总结
 	fmt.Printf("\n=== 性能总结 ===\n")
@@ -1819,6 +2980,11 @@ func main() {
 	fmt.Printf("峰值内存: %.2f MB\n", float64(m.Sys)/(1024*1024))
 	fmt.Printf("GC次数: %d\n", m.NumGC)
 	fmt.Printf("程序总运行时间: %v\n", time.Since(programStartTime))
-	
+
 	fmt.Println("\n测试完成! 🎯")
+
+	// SLA门禁: 任一阈值被突破时以非零码退出，供CI判定
+	if evaluateSLA(snap, totalDuration, scoreCard) > 0 {
+		os.Exit(slaExitCode)
+	}
 }
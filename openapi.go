@@ -0,0 +1,375 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	mathrand "math/rand"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ===================================================================================
+// --- OpenAPI导入 (OpenAPI Import) ---
+// 读取OpenAPI 3 / Swagger 2文档，按操作生成合法请求 (路径参数填值、
+// 符合schema的JSON请求体、正确的Content-Type)，替代大概率404的随机路径；
+// 按操作聚合统计，经响应观察器 (middleware.go) 挂接
+// ===================================================================================
+
+var (
+	// OpenAPI文档路径，非空启用导入模式
+	OpenAPIFile = ""
+	// 覆盖文档内servers/host声明的基础URL
+	OpenAPIBaseURL = ""
+)
+
+// 每个操作展开的具体请求实例数 (参数和请求体独立随机)
+const openapiInstancesPerOp = 20
+
+// schema递归生成的深度上限，防止自引用schema无限展开
+const openapiMaxDepth = 5
+
+// 文档中的一个操作
+type openapiOperation struct {
+	method   string
+	path     string // 原始模板，如 /users/{id}
+	pathRe   *regexp.Regexp
+	requests int64
+	failed   int64
+	totalDur time.Duration
+}
+
+var (
+	openapiOps   []*openapiOperation
+	openapiOpsMu sync.Mutex
+)
+
+// 加载OpenAPI文档并展开为目标定义，随机路径生成被完全绕过
+func loadOpenAPITargets() error {
+	data, err := os.ReadFile(OpenAPIFile)
+	if err != nil {
+		return fmt.Errorf("无法读取OpenAPI文档: %v", err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("解析OpenAPI文档失败: %v", err)
+	}
+
+	base := strings.TrimSuffix(OpenAPIBaseURL, "/")
+	if base == "" {
+		base = openapiServerURL(doc)
+	}
+	if base == "" {
+		return fmt.Errorf("OpenAPI文档未声明服务器地址，需设置 openapi_base_url")
+	}
+
+	paths, _ := doc["paths"].(map[string]interface{})
+	if len(paths) == 0 {
+		return fmt.Errorf("OpenAPI文档中没有paths定义")
+	}
+
+	var specs []TargetSpec
+	seen := make(map[string]bool)
+	for _, path := range sortedKeys(paths) {
+		pathItem, _ := paths[path].(map[string]interface{})
+		for _, method := range []string{"get", "post", "put", "delete", "patch", "head", "options"} {
+			opRaw, ok := pathItem[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			op := &openapiOperation{
+				method: strings.ToUpper(method),
+				path:   path,
+				pathRe: openapiPathRegexp(path),
+			}
+			openapiOps = append(openapiOps, op)
+
+			// 每个操作展开若干个参数/请求体独立随机的实例
+			for k := 0; k < openapiInstancesPerOp; k++ {
+				spec := openapiInstance(doc, op, opRaw, base)
+				specs = append(specs, spec)
+				if !seen[spec.URL] {
+					seen[spec.URL] = true
+					TargetURLs = append(TargetURLs, spec.URL)
+				}
+			}
+		}
+	}
+	if len(specs) == 0 {
+		return fmt.Errorf("OpenAPI文档中没有可用的操作")
+	}
+
+	registerTargetSpecs(specs)
+	RegisterResponseObserver(observeOpenAPIResponse)
+	log.Printf("成功从 %s 加载 %d 个操作 (%d 个请求实例)", OpenAPIFile, len(openapiOps), len(specs))
+	return nil
+}
+
+// 文档声明的第一个服务器地址 (OpenAPI 3的servers或Swagger 2的host+basePath)
+func openapiServerURL(doc map[string]interface{}) string {
+	if servers, ok := doc["servers"].([]interface{}); ok && len(servers) > 0 {
+		if server, ok := servers[0].(map[string]interface{}); ok {
+			if u, ok := server["url"].(string); ok {
+				return strings.TrimSuffix(u, "/")
+			}
+		}
+	}
+	if host, ok := doc["host"].(string); ok && host != "" {
+		scheme := "https"
+		if schemes, ok := doc["schemes"].([]interface{}); ok && len(schemes) > 0 {
+			if s, ok := schemes[0].(string); ok {
+				scheme = s
+			}
+		}
+		basePath, _ := doc["basePath"].(string)
+		return scheme + "://" + host + strings.TrimSuffix(basePath, "/")
+	}
+	return ""
+}
+
+// 把一个操作展开为具体的目标定义: 填路径参数、生成请求体
+func openapiInstance(doc map[string]interface{}, op *openapiOperation, opRaw map[string]interface{}, base string) TargetSpec {
+	spec := TargetSpec{Method: op.method, Weight: 1}
+
+	// 路径参数按schema类型填值
+	path := op.path
+	params, _ := opRaw["parameters"].([]interface{})
+	for _, p := range params {
+		param, _ := p.(map[string]interface{})
+		if param["in"] != "path" {
+			continue
+		}
+		name, _ := param["name"].(string)
+		schema, _ := param["schema"].(map[string]interface{})
+		if schema == nil {
+			schema = param // Swagger 2把type直接放在参数上
+		}
+		value := openapiScalarString(doc, schema)
+		path = strings.ReplaceAll(path, "{"+name+"}", value)
+	}
+	spec.URL = base + path
+
+	// JSON请求体 (OpenAPI 3的requestBody或Swagger 2的body参数)
+	if schema := openapiBodySchema(doc, opRaw, params); schema != nil {
+		value := openapiSchemaValue(doc, schema, 0)
+		if data, err := json.Marshal(value); err == nil {
+			spec.Body = data
+			spec.Headers = map[string]string{"Content-Type": "application/json"}
+		}
+	}
+	return spec
+}
+
+// 操作的JSON请求体schema，没有则返回nil
+func openapiBodySchema(doc, opRaw map[string]interface{}, params []interface{}) map[string]interface{} {
+	if body, ok := opRaw["requestBody"].(map[string]interface{}); ok {
+		if content, ok := body["content"].(map[string]interface{}); ok {
+			if media, ok := content["application/json"].(map[string]interface{}); ok {
+				if schema, ok := media["schema"].(map[string]interface{}); ok {
+					return schema
+				}
+			}
+		}
+	}
+	for _, p := range params {
+		param, _ := p.(map[string]interface{})
+		if param["in"] == "body" {
+			if schema, ok := param["schema"].(map[string]interface{}); ok {
+				return schema
+			}
+		}
+	}
+	return nil
+}
+
+// 解析 $ref 引用 (#/components/schemas/X 或 #/definitions/X)
+func openapiResolveRef(doc map[string]interface{}, ref string) map[string]interface{} {
+	parts := strings.Split(strings.TrimPrefix(ref, "#/"), "/")
+	cur := interface{}(doc)
+	for _, part := range parts {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		cur = m[part]
+	}
+	resolved, _ := cur.(map[string]interface{})
+	return resolved
+}
+
+// 按schema递归生成一个符合定义的值
+func openapiSchemaValue(doc map[string]interface{}, schema map[string]interface{}, depth int) interface{} {
+	if depth > openapiMaxDepth {
+		return nil
+	}
+	if ref, ok := schema["$ref"].(string); ok {
+		if resolved := openapiResolveRef(doc, ref); resolved != nil {
+			return openapiSchemaValue(doc, resolved, depth+1)
+		}
+		return nil
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[mathrand.Intn(len(enum))]
+	}
+
+	schemaType, _ := schema["type"].(string)
+	switch schemaType {
+	case "object", "":
+		props, _ := schema["properties"].(map[string]interface{})
+		if props == nil {
+			return map[string]interface{}{}
+		}
+		obj := make(map[string]interface{}, len(props))
+		for _, name := range sortedKeys(props) {
+			propSchema, _ := props[name].(map[string]interface{})
+			if propSchema != nil {
+				obj[name] = openapiSchemaValue(doc, propSchema, depth+1)
+			}
+		}
+		return obj
+	case "array":
+		items, _ := schema["items"].(map[string]interface{})
+		if items == nil {
+			return []interface{}{}
+		}
+		n := mathrand.Intn(3) + 1
+		arr := make([]interface{}, 0, n)
+		for k := 0; k < n; k++ {
+			arr = append(arr, openapiSchemaValue(doc, items, depth+1))
+		}
+		return arr
+	case "string":
+		return openapiStringValue(schema)
+	case "integer":
+		return mathrand.Intn(10000) + 1
+	case "number":
+		return float64(mathrand.Intn(100000)) / 100
+	case "boolean":
+		return mathrand.Intn(2) == 0
+	}
+	return nil
+}
+
+// 按format生成字符串值
+func openapiStringValue(schema map[string]interface{}) string {
+	format, _ := schema["format"].(string)
+	switch format {
+	case "date-time":
+		return time.Now().Add(-time.Duration(mathrand.Intn(86400)) * time.Second).Format(time.RFC3339)
+	case "date":
+		return time.Now().AddDate(0, 0, -mathrand.Intn(365)).Format("2006-01-02")
+	case "email":
+		return fmt.Sprintf("user%d@example.com", mathrand.Intn(100000))
+	case "uuid":
+		return fmt.Sprintf("%08x-%04x-4%03x-%04x-%012x",
+			mathrand.Uint32(), mathrand.Intn(0x10000), mathrand.Intn(0x1000),
+			mathrand.Intn(0x4000)+0x8000, mathrand.Int63n(1<<48))
+	case "uri":
+		return fmt.Sprintf("https://example.com/r/%d", mathrand.Intn(100000))
+	}
+	return fmt.Sprintf("str-%d", mathrand.Intn(100000))
+}
+
+// 路径参数的标量填值 (用于URL内)
+func openapiScalarString(doc map[string]interface{}, schema map[string]interface{}) string {
+	value := openapiSchemaValue(doc, schema, openapiMaxDepth-1)
+	switch v := value.(type) {
+	case string:
+		return v
+	case int:
+		return fmt.Sprintf("%d", v)
+	case float64:
+		return fmt.Sprintf("%g", v)
+	case bool:
+		return fmt.Sprintf("%t", v)
+	}
+	return fmt.Sprintf("%d", mathrand.Intn(10000)+1)
+}
+
+// 路径模板转匹配用的正则: {id} -> [^/]+
+func openapiPathRegexp(path string) *regexp.Regexp {
+	escaped := regexp.QuoteMeta(path)
+	pattern := regexp.MustCompile(`\\\{[^/]+?\\\}`).ReplaceAllString(escaped, `[^/]+`)
+	return regexp.MustCompile("^" + pattern + "$")
+}
+
+// 响应观察器: 把每个响应归入对应的操作并累计统计
+func observeOpenAPIResponse(resp *http.Response, duration time.Duration) {
+	if resp.Request == nil {
+		return
+	}
+	path := resp.Request.URL.Path
+	method := resp.Request.Method
+	for _, op := range openapiOps {
+		if op.method != method || !op.pathRe.MatchString(path) {
+			continue
+		}
+		openapiOpsMu.Lock()
+		op.requests++
+		if resp.StatusCode >= 400 {
+			op.failed++
+		}
+		op.totalDur += duration
+		openapiOpsMu.Unlock()
+		return
+	}
+}
+
+// 打印按操作聚合的统计
+func printOpenAPIReport() {
+	if len(openapiOps) == 0 {
+		return
+	}
+
+	fmt.Printf("\n=== OpenAPI操作统计 ===\n")
+	openapiOpsMu.Lock()
+	defer openapiOpsMu.Unlock()
+	for _, op := range openapiOps {
+		if op.requests == 0 {
+			fmt.Printf("%-7s %s: 无流量\n", op.method, op.path)
+			continue
+		}
+		avgMs := float64(op.totalDur.Nanoseconds()) / float64(op.requests) / 1e6
+		fmt.Printf("%-7s %s: %d 次 | 4xx/5xx %.1f%% | 平均 %.1fms\n",
+			op.method, op.path, op.requests, float64(op.failed)/float64(op.requests)*100, avgMs)
+	}
+}
+
+// 供JSON报告使用的按操作统计
+func openapiAnalysis() []map[string]interface{} {
+	if len(openapiOps) == 0 {
+		return nil
+	}
+	openapiOpsMu.Lock()
+	defer openapiOpsMu.Unlock()
+	var rows []map[string]interface{}
+	for _, op := range openapiOps {
+		row := map[string]interface{}{
+			"method":   op.method,
+			"path":     op.path,
+			"requests": op.requests,
+			"failed":   op.failed,
+		}
+		if op.requests > 0 {
+			row["avg_ms"] = float64(op.totalDur.Nanoseconds()) / float64(op.requests) / 1e6
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// map键的确定序遍历 (相同种子下生成结果可复现)
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ===================================================================================
+// --- 开放模型恒定到达率 (Open-Model Constant Arrival Rate) ---
+// 闭环模式下每个协程发完即发下一个，延迟测量会被协程饱和度耦合；
+// 到达率模式改由节拍器按固定速率派发请求到协程池，更接近真实用户到达过程
+// ===================================================================================
+
+// 每秒到达的请求数，大于0时启用开放模型 (替代闭环工作协程)
+var ArrivalRate = 0
+
+// 节拍器粒度: 高到达率下每拍派发多个请求
+const arrivalTickInterval = 10 * time.Millisecond
+
+// 开放模型执行入口: 按恒定速率派发，池满时记录丢弃而不是阻塞节拍器
+func runOpenModelLoad(ctx context.Context, cache *RequestCache, stats *Stats, httpClient, http3Client *http.Client, wg *sync.WaitGroup) {
+	log.Printf("开放模型: 到达率 %d 请求/秒，执行池上限 %d", ArrivalRate, NumConcurrentWorkers)
+
+	sem := make(chan struct{}, NumConcurrentWorkers)
+	ticker := time.NewTicker(arrivalTickInterval)
+	defer ticker.Stop()
+
+	durationMode := TestDuration > 0
+	var issued int64
+	tokens := 0.0
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if durationMode && time.Now().After(testDeadline) {
+			return
+		}
+
+		// 暂停时不派发也不积累令牌
+		if isPaused() {
+			tokens = 0
+			continue
+		}
+		if !durationMode && atomic.LoadInt64(&issued) >= int64(TotalDownloads) {
+			return
+		}
+
+		// 按节拍积累应派发的请求数 (处理非整数每拍速率)
+		tokens += float64(ArrivalRate) * arrivalTickInterval.Seconds()
+		n := int(tokens)
+		tokens -= float64(n)
+
+		for k := 0; k < n; k++ {
+			if !agentBudgetAllows() {
+				return
+			}
+
+			select {
+			case sem <- struct{}{}:
+				atomic.AddInt64(&issued, 1)
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer func() { <-sem }()
+
+					url, payload, headers, method := getFromCache(cache)
+					dispatchRequest(ctx, httpClient, http3Client, method, url, payload, headers, stats)
+				}()
+			default:
+				// 执行池已饱和: 开放模型不排队等待，记录丢弃以暴露服务端吞吐不足
+				atomic.AddInt64(&stats.DroppedArrivals, 1)
+			}
+		}
+	}
+}
+
+// 打印到达率模式的补充信息
+func printOpenModelSummary(stats *StatsSnapshot) {
+	if ArrivalRate <= 0 {
+		return
+	}
+	fmt.Printf("\n=== 开放模型 (到达率 %d/s) ===\n", ArrivalRate)
+	fmt.Printf("池饱和丢弃: %d\n", stats.DroppedArrivals)
+	if stats.DroppedArrivals > 0 {
+		fmt.Printf("提示: 存在丢弃说明执行池被慢响应占满，实际施加速率低于目标到达率\n")
+	}
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ===================================================================================
+// --- 多区域编排 (Multi-Region Orchestration) ---
+// 通过SSH把二进制和配置分发到多台机器，远程启动代理并回收各区域的测试报告
+// 用法: load-tester orchestrate <hosts文件>
+// hosts文件每行: <区域名> <user@host>，#开头为注释
+// ===================================================================================
+
+const remoteWorkDir = "/tmp/load-tester-agent"
+
+// 单个远程代理的描述
+type OrchestratedAgent struct {
+	Region string
+	Host   string // user@host 形式，传给ssh/scp
+}
+
+// 单个区域的汇总结果 (从远端详细报告中提取)
+type RegionResult struct {
+	Region         string
+	Host           string
+	QPS            float64
+	SuccessRate    float64
+	AvgMs          float64
+	P50Ms          float64
+	P95Ms          float64
+	P99Ms          float64
+	TotalRequests  float64
+	FailedRequests float64
+	ErrorMix       map[string]interface{} // 错误类型 -> 次数
+	StartTime      string                 // 代理本地时钟下的开始时间
+	ClockOffset    time.Duration          // 代理时钟相对控制端的偏移 (正值表示代理时钟偏快)
+	Err            error
+}
+
+// 探测远程主机的时钟偏移 (NTP式: 用往返中点对齐远端时间戳)
+// 返回正值表示远端时钟比本地快
+func probeClockOffset(host string) (time.Duration, error) {
+	t0 := time.Now()
+	out, err := exec.Command("ssh", host, "date", "+%s.%N").Output()
+	t1 := time.Now()
+	if err != nil {
+		return 0, fmt.Errorf("时钟探测失败: %v", err)
+	}
+
+	remoteSec, err := strconv.ParseFloat(strings.TrimSpace(string(out)), 64)
+	if err != nil {
+		return 0, fmt.Errorf("无法解析远端时间戳: %v", err)
+	}
+	remoteTime := time.Unix(0, int64(remoteSec*1e9))
+
+	// 假设网络延迟对称，远端时间戳对应往返中点
+	midpoint := t0.Add(t1.Sub(t0) / 2)
+	return remoteTime.Sub(midpoint), nil
+}
+
+// 解析hosts文件
+func loadOrchestrationHosts(fileName string) ([]OrchestratedAgent, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("无法打开hosts文件: %v", err)
+	}
+	defer file.Close()
+
+	var agents []OrchestratedAgent
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			log.Printf("警告: 跳过格式错误的hosts行 (行 %d): %s", lineNum, line)
+			continue
+		}
+		agents = append(agents, OrchestratedAgent{Region: fields[0], Host: fields[1]})
+	}
+
+	if len(agents) == 0 {
+		return nil, fmt.Errorf("hosts文件 %s 中没有有效主机", fileName)
+	}
+	return agents, scanner.Err()
+}
+
+// 编排入口: 分发、远程执行、回收并汇总
+func runOrchestrator(hostsFile string) error {
+	agents, err := loadOrchestrationHosts(hostsFile)
+	if err != nil {
+		return err
+	}
+
+	selfPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("无法定位自身二进制: %v", err)
+	}
+
+	fmt.Printf("=== 多区域编排模式 ===\n")
+	fmt.Printf("代理数量: %d\n", len(agents))
+
+	results := make([]RegionResult, len(agents))
+	var wg sync.WaitGroup
+	for i, agent := range agents {
+		wg.Add(1)
+		go func(idx int, a OrchestratedAgent) {
+			defer wg.Done()
+			results[idx] = runRemoteAgent(a, selfPath)
+		}(i, agent)
+	}
+	wg.Wait()
+
+	printRegionComparison(results)
+	saveMergedReport(results)
+	return nil
+}
+
+// 在单台远程主机上执行一次完整测试并取回报告
+func runRemoteAgent(agent OrchestratedAgent, selfPath string) RegionResult {
+	result := RegionResult{Region: agent.Region, Host: agent.Host}
+
+	// 0. 开始前探测代理时钟偏移，用于之后对齐各代理的时间序列
+	if offset, err := probeClockOffset(agent.Host); err != nil {
+		log.Printf("警告: [%s] %v，时间戳将不做偏移校正", agent.Region, err)
+	} else {
+		result.ClockOffset = offset
+		if offset > time.Second || offset < -time.Second {
+			log.Printf("警告: [%s] 时钟偏移达 %v，合并报告中的时间戳将被校正", agent.Region, offset)
+		}
+	}
+
+	// 1. 准备远程目录并分发二进制和目标配置
+	if out, err := exec.Command("ssh", agent.Host, "mkdir", "-p", remoteWorkDir).CombinedOutput(); err != nil {
+		result.Err = fmt.Errorf("创建远程目录失败: %v (%s)", err, strings.TrimSpace(string(out)))
+		return result
+	}
+	for _, f := range []string{selfPath, "dependency.txt"} {
+		if out, err := exec.Command("scp", "-q", f, agent.Host+":"+remoteWorkDir+"/").CombinedOutput(); err != nil {
+			result.Err = fmt.Errorf("分发 %s 失败: %v (%s)", filepath.Base(f), err, strings.TrimSpace(string(out)))
+			return result
+		}
+	}
+
+	// 2. 远程启动代理，实时把它的统计输出打上区域前缀转发到本地
+	remoteBin := remoteWorkDir + "/" + filepath.Base(selfPath)
+	cmd := exec.Command("ssh", agent.Host,
+		fmt.Sprintf("cd %s && chmod +x %s && %s", remoteWorkDir, remoteBin, remoteBin))
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		result.Err = fmt.Errorf("创建输出管道失败: %v", err)
+		return result
+	}
+	cmd.Stderr = cmd.Stdout
+	if err := cmd.Start(); err != nil {
+		result.Err = fmt.Errorf("远程启动失败: %v", err)
+		return result
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line != "" {
+			fmt.Printf("[%s] %s\n", agent.Region, line)
+		}
+	}
+	if err := cmd.Wait(); err != nil {
+		result.Err = fmt.Errorf("远程执行失败: %v", err)
+		return result
+	}
+
+	// 3. 取回最新的详细报告并提取关键指标
+	localReport := fmt.Sprintf("report_%s_%s.json", agent.Region, time.Now().Format("20060102_150405"))
+	fetchCmd := fmt.Sprintf("cat $(ls -t %s/detailed_report_*.json | head -1)", remoteWorkDir)
+	reportData, err := exec.Command("ssh", agent.Host, fetchCmd).Output()
+	if err != nil {
+		result.Err = fmt.Errorf("取回报告失败: %v", err)
+		return result
+	}
+	if err := os.WriteFile(localReport, reportData, 0644); err != nil {
+		log.Printf("警告: 无法保存区域报告 %s: %v", localReport, err)
+	}
+
+	if err := extractRegionMetrics(reportData, &result); err != nil {
+		result.Err = err
+	}
+	return result
+}
+
+// 从详细报告JSON中提取区域关键指标
+func extractRegionMetrics(data []byte, result *RegionResult) error {
+	var report map[string]interface{}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return fmt.Errorf("解析远端报告失败: %v", err)
+	}
+
+	if perf, ok := report["performance_results"].(map[string]interface{}); ok {
+		result.QPS, _ = perf["requests_per_second"].(float64)
+		result.SuccessRate, _ = perf["success_rate_percent"].(float64)
+		result.TotalRequests, _ = perf["total_requests"].(float64)
+		result.FailedRequests, _ = perf["failed_requests"].(float64)
+	}
+	if rt, ok := report["response_time_analysis"].(map[string]interface{}); ok {
+		result.AvgMs, _ = rt["average_ms"].(float64)
+		result.P50Ms, _ = rt["p50_ms"].(float64)
+		result.P95Ms, _ = rt["p95_ms"].(float64)
+		result.P99Ms, _ = rt["p99_ms"].(float64)
+	}
+	if errs, ok := report["error_analysis"].(map[string]interface{}); ok {
+		result.ErrorMix = errs
+	}
+	if info, ok := report["test_info"].(map[string]interface{}); ok {
+		result.StartTime, _ = info["start_time"].(string)
+	}
+	return nil
+}
+
+// 把代理本地时钟下的时间戳换算到控制端时钟
+func normalizeAgentTimestamp(localStamp string, offset time.Duration) string {
+	t, err := time.Parse(time.RFC3339, localStamp)
+	if err != nil {
+		return localStamp
+	}
+	return t.Add(-offset).Format(time.RFC3339)
+}
+
+// 保存合并报告: 全局汇总之外保留每个代理的子报告，便于定位单个异常代理
+func saveMergedReport(results []RegionResult) {
+	var totalQPS, totalRequests, totalFailed float64
+	agents := make(map[string]interface{})
+
+	for _, r := range results {
+		if r.Err != nil {
+			agents[r.Region] = map[string]interface{}{
+				"host":  r.Host,
+				"error": r.Err.Error(),
+			}
+			continue
+		}
+		totalQPS += r.QPS
+		totalRequests += r.TotalRequests
+		totalFailed += r.FailedRequests
+		agents[r.Region] = map[string]interface{}{
+			"host":                r.Host,
+			"qps_contribution":    r.QPS,
+			"total_requests":      r.TotalRequests,
+			"failed_requests":     r.FailedRequests,
+			"success_rate":        r.SuccessRate,
+			"latency_average_ms":  r.AvgMs,
+			"latency_p50_ms":      r.P50Ms,
+			"latency_p95_ms":      r.P95Ms,
+			"latency_p99_ms":      r.P99Ms,
+			"error_mix":           r.ErrorMix,
+			"clock_offset_ms":     float64(r.ClockOffset.Nanoseconds()) / 1e6,
+			"start_time_local":    r.StartTime,
+			// 按探测到的偏移换算到控制端时钟，保证跨代理时间序列对齐
+			"start_time_normalized": normalizeAgentTimestamp(r.StartTime, r.ClockOffset),
+		}
+	}
+
+	merged := map[string]interface{}{
+		"merged": map[string]interface{}{
+			"agent_count":     len(results),
+			"total_qps":       totalQPS,
+			"total_requests":  totalRequests,
+			"failed_requests": totalFailed,
+		},
+		"agents":    agents,
+		"timestamp": time.Now().Format(time.RFC3339),
+	}
+
+	fileName := fmt.Sprintf("merged_report_%s.json", time.Now().Format("20060102_150405"))
+	file, err := os.Create(fileName)
+	if err != nil {
+		log.Printf("无法创建合并报告文件: %v", err)
+		return
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(merged); err != nil {
+		log.Printf("无法保存合并报告: %v", err)
+		return
+	}
+	log.Printf("合并报告已保存到: %s", fileName)
+}
+
+// 打印各区域延迟/吞吐对比
+func printRegionComparison(results []RegionResult) {
+	fmt.Printf("\n" + strings.Repeat("=", 60) + "\n")
+	fmt.Printf("                 各区域结果对比\n")
+	fmt.Printf(strings.Repeat("=", 60) + "\n")
+
+	sort.Slice(results, func(i, j int) bool { return results[i].AvgMs < results[j].AvgMs })
+
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Printf("%-12s %-24s 失败: %v\n", r.Region, r.Host, r.Err)
+			continue
+		}
+		fmt.Printf("%-12s QPS: %8.1f | 成功率: %5.1f%% | 平均: %7.1fms | P95: %7.1fms\n",
+			r.Region, r.QPS, r.SuccessRate, r.AvgMs, r.P95Ms)
+	}
+	fmt.Printf(strings.Repeat("=", 60) + "\n")
+}
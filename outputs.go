@@ -0,0 +1,261 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ===================================================================================
+// --- 兼容输出格式 (wrk / vegeta / k6 Output Adapters) ---
+// 很多团队的看板和解析脚本是围绕wrk的文本、vegeta和k6的JSON摘要搭建的；
+// 按这些工具的格式额外导出一份结果，迁移时现有工具链不用改
+// ===================================================================================
+
+// 需要额外导出的格式列表，支持 "wrk" / "vegeta" / "k6"
+var OutputFormats []string
+
+// 从延迟样本计算适配器需要的全套统计量 (毫秒)
+type latencySummary struct {
+	min, max, avg, stdev     float64
+	p50, p90, p95, p99       float64
+}
+
+func summarizeLatencies(samples []time.Duration) latencySummary {
+	var s latencySummary
+	if len(samples) == 0 {
+		return s
+	}
+
+	sorted := append([]time.Duration(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	toMs := func(d time.Duration) float64 { return float64(d.Nanoseconds()) / 1e6 }
+	at := func(q float64) float64 {
+		idx := int(q * float64(len(sorted)))
+		if idx >= len(sorted) {
+			idx = len(sorted) - 1
+		}
+		return toMs(sorted[idx])
+	}
+
+	var sum float64
+	for _, d := range sorted {
+		sum += toMs(d)
+	}
+	s.avg = sum / float64(len(sorted))
+	var variance float64
+	for _, d := range sorted {
+		diff := toMs(d) - s.avg
+		variance += diff * diff
+	}
+	s.stdev = math.Sqrt(variance / float64(len(sorted)))
+
+	s.min, s.max = toMs(sorted[0]), toMs(sorted[len(sorted)-1])
+	s.p50, s.p90, s.p95, s.p99 = at(0.50), at(0.90), at(0.95), at(0.99)
+	return s
+}
+
+// 状态码分布: 2xx合并记为200，其余从HTTP_xxx错误类型还原
+func statusCodeCounts(snap *StatsSnapshot) map[string]int64 {
+	codes := map[string]int64{}
+	if snap.TotalResponses > 0 {
+		codes["200"] = snap.TotalResponses
+	}
+	for errType, count := range snap.ErrorTypes {
+		if strings.HasPrefix(errType, "HTTP_") {
+			codes[strings.TrimPrefix(errType, "HTTP_")] = count
+		}
+	}
+	return codes
+}
+
+// 按配置导出各兼容格式
+func saveCompatOutputs(snap *StatsSnapshot, totalDuration time.Duration) {
+	if len(OutputFormats) == 0 {
+		return
+	}
+
+	lat := summarizeLatencies(snap.ResponseTimes)
+	ts := time.Now().Format("20060102_150405")
+	for _, format := range OutputFormats {
+		switch strings.ToLower(format) {
+		case "wrk":
+			saveWrkOutput(snap, totalDuration, lat, fmt.Sprintf("summary_wrk_%s.txt", ts))
+		case "vegeta":
+			saveVegetaOutput(snap, totalDuration, fmt.Sprintf("summary_vegeta_%s.json", ts))
+		case "k6":
+			saveK6Output(snap, totalDuration, lat, fmt.Sprintf("summary_k6_%s.json", ts))
+		default:
+			log.Printf("未知的输出格式: %s (支持 wrk/vegeta/k6)", format)
+		}
+	}
+}
+
+// wrk风格文本摘要
+func saveWrkOutput(snap *StatsSnapshot, totalDuration time.Duration, lat latencySummary, fileName string) {
+	target := "多目标"
+	if len(TargetURLs) == 1 {
+		target = TargetURLs[0]
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Running %.0fs test @ %s\n", totalDuration.Seconds(), target)
+	fmt.Fprintf(&b, "  %d threads and %d connections\n", NumConcurrentWorkers, NumConcurrentWorkers)
+	fmt.Fprintf(&b, "  Thread Stats   Avg      Stdev     Max   +/- Stdev\n")
+	fmt.Fprintf(&b, "    Latency   %8.2fms %8.2fms %8.2fms    -\n", lat.avg, lat.stdev, lat.max)
+	fmt.Fprintf(&b, "  Latency Distribution\n")
+	fmt.Fprintf(&b, "     50%%  %8.2fms\n", lat.p50)
+	fmt.Fprintf(&b, "     90%%  %8.2fms\n", lat.p90)
+	fmt.Fprintf(&b, "     99%%  %8.2fms\n", lat.p99)
+	fmt.Fprintf(&b, "  %d requests in %.2fs, %.2fMB read\n",
+		snap.TotalRequests, totalDuration.Seconds(), float64(snap.TotalResponseSize)/(1024*1024))
+	if snap.Non200Responses > 0 {
+		fmt.Fprintf(&b, "  Non-2xx or 3xx responses: %d\n", snap.Non200Responses)
+	}
+	if snap.FailedRequests > 0 {
+		fmt.Fprintf(&b, "  Socket errors: %d\n", snap.FailedRequests)
+	}
+	fmt.Fprintf(&b, "Requests/sec: %10.2f\n", float64(snap.TotalRequests)/totalDuration.Seconds())
+	fmt.Fprintf(&b, "Transfer/sec: %9.2fMB\n", float64(snap.TotalResponseSize)/(1024*1024)/totalDuration.Seconds())
+
+	if err := os.WriteFile(fileName, []byte(b.String()), 0644); err != nil {
+		log.Printf("无法写入wrk格式摘要: %v", err)
+		return
+	}
+	log.Printf("wrk格式摘要已保存到: %s", fileName)
+}
+
+// vegeta `report -type=json` 风格摘要 (延迟单位为纳秒)
+func saveVegetaOutput(snap *StatsSnapshot, totalDuration time.Duration, fileName string) {
+	sorted := append([]time.Duration(nil), snap.ResponseTimes...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	atNs := func(q float64) int64 {
+		if len(sorted) == 0 {
+			return 0
+		}
+		idx := int(q * float64(len(sorted)))
+		if idx >= len(sorted) {
+			idx = len(sorted) - 1
+		}
+		return sorted[idx].Nanoseconds()
+	}
+	var totalNs, minNs, maxNs int64
+	for _, d := range sorted {
+		totalNs += d.Nanoseconds()
+	}
+	if len(sorted) > 0 {
+		minNs, maxNs = sorted[0].Nanoseconds(), sorted[len(sorted)-1].Nanoseconds()
+	}
+	var meanNs int64
+	if len(sorted) > 0 {
+		meanNs = totalNs / int64(len(sorted))
+	}
+
+	attempts := snap.TotalRequests + snap.FailedRequests
+	success := 0.0
+	if attempts > 0 {
+		success = float64(snap.TotalResponses) / float64(attempts)
+	}
+
+	var errors []string
+	for errType, count := range snap.ErrorTypes {
+		if !strings.HasPrefix(errType, "HTTP_") {
+			errors = append(errors, fmt.Sprintf("%s (%d)", errType, count))
+		}
+	}
+	sort.Strings(errors)
+
+	summary := map[string]interface{}{
+		"latencies": map[string]int64{
+			"total": totalNs,
+			"mean":  meanNs,
+			"50th":  atNs(0.50),
+			"90th":  atNs(0.90),
+			"95th":  atNs(0.95),
+			"99th":  atNs(0.99),
+			"min":   minNs,
+			"max":   maxNs,
+		},
+		"bytes_in": map[string]interface{}{
+			"total": snap.TotalResponseSize,
+			"mean":  float64(snap.TotalResponseSize) / math.Max(float64(snap.TotalRequests), 1),
+		},
+		"bytes_out":    map[string]interface{}{"total": 0, "mean": 0},
+		"earliest":     snap.StartTime.Format(time.RFC3339Nano),
+		"latest":       time.Now().Format(time.RFC3339Nano),
+		"end":          time.Now().Format(time.RFC3339Nano),
+		"duration":     totalDuration.Nanoseconds(),
+		"wait":         0,
+		"requests":     attempts,
+		"rate":         float64(attempts) / totalDuration.Seconds(),
+		"throughput":   float64(snap.TotalResponses) / totalDuration.Seconds(),
+		"success":      success,
+		"status_codes": statusCodeCounts(snap),
+		"errors":       errors,
+	}
+	writeJSONFile(fileName, summary, "vegeta格式摘要")
+}
+
+// k6 `--summary-export` 风格摘要 (延迟单位为毫秒)
+func saveK6Output(snap *StatsSnapshot, totalDuration time.Duration, lat latencySummary, fileName string) {
+	attempts := snap.TotalRequests + snap.FailedRequests
+	failedRatio := 0.0
+	if attempts > 0 {
+		failedRatio = float64(attempts-snap.TotalResponses) / float64(attempts)
+	}
+
+	summary := map[string]interface{}{
+		"metrics": map[string]interface{}{
+			"http_reqs": map[string]interface{}{
+				"count": attempts,
+				"rate":  float64(attempts) / totalDuration.Seconds(),
+			},
+			"http_req_duration": map[string]interface{}{
+				"avg":    lat.avg,
+				"min":    lat.min,
+				"med":    lat.p50,
+				"max":    lat.max,
+				"p(90)":  lat.p90,
+				"p(95)":  lat.p95,
+			},
+			"http_req_failed": map[string]interface{}{
+				"value": failedRatio,
+				"fails": attempts - snap.TotalResponses,
+			},
+			"data_received": map[string]interface{}{
+				"count": snap.TotalResponseSize,
+				"rate":  float64(snap.TotalResponseSize) / totalDuration.Seconds(),
+			},
+			"vus_max": map[string]interface{}{"value": NumConcurrentWorkers},
+		},
+		"root_group": map[string]interface{}{
+			"name": "", "path": "", "id": "d41d8cd98f00b204e9800998ecf8427e",
+			"groups": map[string]interface{}{}, "checks": map[string]interface{}{},
+		},
+	}
+	writeJSONFile(fileName, summary, "k6格式摘要")
+}
+
+// 写JSON文件的公共路径
+func writeJSONFile(fileName string, v interface{}, label string) {
+	file, err := os.Create(fileName)
+	if err != nil {
+		log.Printf("无法创建%s文件: %v", label, err)
+		return
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		log.Printf("无法写入%s: %v", label, err)
+		return
+	}
+	log.Printf("%s已保存到: %s", label, fileName)
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// ===================================================================================
+// --- 页面加载模式 (Browser-Like Page Load) ---
+// 浏览器拿到HTML后还会并行拉CSS/JS/图片，单请求延迟反映不了用户看到的"页面打开时间"；
+// 开启后对HTML响应解析内嵌资源引用并以浏览器风格的并行度抓取，
+// 在单请求延迟之外额外报告"页面加载"复合耗时 (HTML+资源全部到齐)
+// ===================================================================================
+
+var (
+	EnablePageAssets = false
+	// 每页资源抓取并行度 (浏览器对单主机的典型并发)
+	PageAssetParallelism = 6
+	// 每页最多抓取的资源数，防止资源爆炸的页面拖垮测试
+	PageAssetMax = 30
+)
+
+var (
+	pageLoads     int64
+	pageAssetReqs int64
+	pageAssetFail int64
+
+	pageLoadMu  sync.Mutex
+	pageLoadDur []time.Duration // 复合页面加载耗时样本
+)
+
+// 响应是否为HTML页面
+func isHTMLResponse(resp *http.Response) bool {
+	return strings.Contains(resp.Header.Get("Content-Type"), "text/html")
+}
+
+// 从HTML中提取资源引用: <script src> <link href> <img src>，相对地址按页面解析
+func extractAssetURLs(pageURL string, body []byte) []string {
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil
+	}
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var assets []string
+	add := func(ref string) {
+		if ref == "" || len(assets) >= PageAssetMax {
+			return
+		}
+		u, err := base.Parse(ref)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return
+		}
+		abs := u.String()
+		if !seen[abs] {
+			seen[abs] = true
+			assets = append(assets, abs)
+		}
+	}
+
+	walkHTML(doc, func(n *html.Node) {
+		if n.Type != html.ElementNode {
+			return
+		}
+		switch n.Data {
+		case "script", "img":
+			add(htmlAttr(n, "src"))
+		case "link":
+			rel := strings.ToLower(htmlAttr(n, "rel"))
+			if rel == "stylesheet" || strings.Contains(rel, "icon") {
+				add(htmlAttr(n, "href"))
+			}
+		}
+	})
+	return assets
+}
+
+// 抓取页面的全部内嵌资源并记录复合加载耗时
+// htmlDuration为HTML本身的耗时，复合耗时 = HTML + 资源阶段
+func fetchPageAssets(ctx context.Context, client *http.Client, pageURL string, htmlDuration time.Duration, body []byte, stats *Stats) {
+	assets := extractAssetURLs(pageURL, body)
+	if len(assets) == 0 {
+		return
+	}
+
+	assetStart := time.Now()
+	sem := make(chan struct{}, PageAssetParallelism)
+	var wg sync.WaitGroup
+	for _, asset := range assets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(assetURL string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fetchOneAsset(ctx, client, assetURL, stats)
+		}(asset)
+	}
+	wg.Wait()
+
+	composite := htmlDuration + time.Since(assetStart)
+	atomic.AddInt64(&pageLoads, 1)
+	pageLoadMu.Lock()
+	if len(pageLoadDur) < ScenarioSampleCap {
+		pageLoadDur = append(pageLoadDur, composite)
+	}
+	pageLoadMu.Unlock()
+}
+
+// 抓取单个资源 (独立计数，不经过makeHTTPRequest避免递归解析)
+func fetchOneAsset(ctx context.Context, client *http.Client, assetURL string, stats *Stats) {
+	atomic.AddInt64(&pageAssetReqs, 1)
+	atomic.AddInt64(&stats.TotalRequests, 1)
+	start := time.Now()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", assetURL, nil)
+	if err != nil {
+		atomic.AddInt64(&pageAssetFail, 1)
+		atomic.AddInt64(&stats.FailedRequests, 1)
+		return
+	}
+	req.Header.Set("User-Agent", generateRandomUserAgent())
+	req.Header.Set(MarkerHeaderName, MarkerHeaderValue)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		atomic.AddInt64(&pageAssetFail, 1)
+		atomic.AddInt64(&stats.FailedRequests, 1)
+		recordError(stats, "资源抓取失败")
+		return
+	}
+	defer resp.Body.Close()
+	n, _ := io.Copy(io.Discard, resp.Body)
+	atomic.AddInt64(&stats.TotalResponseSize, n)
+	atomic.AddInt64(&stats.TotalResponses, 1)
+	recordResponseTime(stats, time.Since(start))
+	if resp.StatusCode >= 400 {
+		atomic.AddInt64(&pageAssetFail, 1)
+		recordError(stats, fmt.Sprintf("资源HTTP_%d", resp.StatusCode))
+	}
+}
+
+// 供JSON报告使用
+func pageLoadAnalysis() map[string]interface{} {
+	if !EnablePageAssets || atomic.LoadInt64(&pageLoads) == 0 {
+		return nil
+	}
+	row := map[string]interface{}{
+		"page_loads":     atomic.LoadInt64(&pageLoads),
+		"asset_requests": atomic.LoadInt64(&pageAssetReqs),
+		"asset_failed":   atomic.LoadInt64(&pageAssetFail),
+		"parallelism":    PageAssetParallelism,
+	}
+	pageLoadMu.Lock()
+	if len(pageLoadDur) > 0 {
+		avg, p50, p95, p99 := durationPercentiles(pageLoadDur)
+		row["composite_avg_ms"], row["composite_p50_ms"] = avg, p50
+		row["composite_p95_ms"], row["composite_p99_ms"] = p95, p99
+	}
+	pageLoadMu.Unlock()
+	return row
+}
+
+// 打印页面加载报告
+func printPageLoadReport() {
+	if !EnablePageAssets || atomic.LoadInt64(&pageLoads) == 0 {
+		return
+	}
+	fmt.Printf("\n=== 页面加载分析 ===\n")
+	fmt.Printf("完整页面: %d  资源请求: %d  资源失败: %d (并行度 %d)\n",
+		atomic.LoadInt64(&pageLoads), atomic.LoadInt64(&pageAssetReqs),
+		atomic.LoadInt64(&pageAssetFail), PageAssetParallelism)
+	pageLoadMu.Lock()
+	if len(pageLoadDur) > 0 {
+		avg, p50, p95, p99 := durationPercentiles(pageLoadDur)
+		fmt.Printf("页面复合耗时: 平均=%.1fms P50=%.1fms P95=%.1fms P99=%.1fms\n", avg, p50, p95, p99)
+	}
+	pageLoadMu.Unlock()
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ===================================================================================
+// --- 暂停/恢复 (Pause / Resume) ---
+// 通过SIGUSR2或仪表盘接口暂停全部协程: 不再发起新请求但保持连接温热，
+// 恢复后继续；暂停时长从QPS和评分的有效时长中剔除
+// ===================================================================================
+
+type pauseController struct {
+	paused     int32 // atomic
+	mu         sync.Mutex
+	pauseStart time.Time
+	totalPause time.Duration
+}
+
+var pauseCtl = &pauseController{}
+
+// 当前是否处于暂停状态
+func isPaused() bool {
+	return atomic.LoadInt32(&pauseCtl.paused) == 1
+}
+
+// 暂停所有协程的新请求发起
+func pauseLoad() {
+	if !atomic.CompareAndSwapInt32(&pauseCtl.paused, 0, 1) {
+		return
+	}
+	pauseCtl.mu.Lock()
+	pauseCtl.pauseStart = time.Now()
+	pauseCtl.mu.Unlock()
+	fmt.Printf("\n已暂停: 协程停止发起新请求，连接保持温热 (SIGUSR2或/resume恢复)\n")
+}
+
+// 恢复负载
+func resumeLoad() {
+	if !atomic.CompareAndSwapInt32(&pauseCtl.paused, 1, 0) {
+		return
+	}
+	pauseCtl.mu.Lock()
+	pauseCtl.totalPause += time.Since(pauseCtl.pauseStart)
+	pauseCtl.mu.Unlock()
+	fmt.Printf("\n已恢复负载\n")
+}
+
+// 暂停/恢复切换
+func togglePause() {
+	if isPaused() {
+		resumeLoad()
+	} else {
+		pauseLoad()
+	}
+}
+
+// 累计暂停时长 (含进行中的暂停)，评分和QPS按有效时长计算
+func totalPausedTime() time.Duration {
+	pauseCtl.mu.Lock()
+	defer pauseCtl.mu.Unlock()
+	total := pauseCtl.totalPause
+	if isPaused() {
+		total += time.Since(pauseCtl.pauseStart)
+	}
+	return total
+}
+
+// 协程在暂停期间阻塞于此，直到恢复或ctx取消
+func waitWhilePaused(ctx context.Context) {
+	for isPaused() {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
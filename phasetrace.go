@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// ===================================================================================
+// --- 请求阶段耗时分解 (DNS / TCP连接 / TTFB) ---
+// 总耗时无法区分是解析慢、建连慢还是服务端处理慢；
+// 通过httptrace把每个请求拆成独立阶段分别统计百分位 (TLS握手见tlstrace.go)
+// ===================================================================================
+
+var EnablePhaseTiming = true
+
+type phaseCollector struct {
+	mu      sync.Mutex
+	dns     []time.Duration
+	connect []time.Duration
+	ttfb    []time.Duration
+}
+
+var phaseTimes = &phaseCollector{}
+
+const maxPhaseSamples = 50000
+
+func (c *phaseCollector) record(dst *[]time.Duration, d time.Duration) {
+	c.mu.Lock()
+	if len(*dst) < maxPhaseSamples {
+		*dst = append(*dst, d)
+	}
+	c.mu.Unlock()
+}
+
+// 为一次请求注册阶段计时hook (与TLS/连接跟踪trace自动组合)
+func withPhaseTrace(ctx context.Context) context.Context {
+	if !EnablePhaseTiming {
+		return ctx
+	}
+
+	requestStart := time.Now()
+	var dnsStart, connectStart time.Time
+
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			dnsStart = time.Now()
+		},
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			if info.Err == nil && !dnsStart.IsZero() {
+				phaseTimes.record(&phaseTimes.dns, time.Since(dnsStart))
+			}
+		},
+		ConnectStart: func(network, addr string) {
+			// 并行建连(happy eyeballs)时只取首次
+			if connectStart.IsZero() {
+				connectStart = time.Now()
+			}
+		},
+		ConnectDone: func(network, addr string, err error) {
+			if err == nil && !connectStart.IsZero() {
+				phaseTimes.record(&phaseTimes.connect, time.Since(connectStart))
+			}
+		},
+		GotFirstResponseByte: func() {
+			phaseTimes.record(&phaseTimes.ttfb, time.Since(requestStart))
+		},
+	}
+	return httptrace.WithClientTrace(ctx, trace)
+}
+
+// 打印各阶段耗时百分位
+func printPhaseReport() {
+	phaseTimes.mu.Lock()
+	dns := append([]time.Duration(nil), phaseTimes.dns...)
+	connect := append([]time.Duration(nil), phaseTimes.connect...)
+	ttfb := append([]time.Duration(nil), phaseTimes.ttfb...)
+	phaseTimes.mu.Unlock()
+
+	if len(dns) == 0 && len(connect) == 0 && len(ttfb) == 0 {
+		return
+	}
+
+	fmt.Printf("\n=== 请求阶段耗时分解 ===\n")
+	printPhaseLine("DNS解析", dns)
+	printPhaseLine("TCP建连", connect)
+	printPhaseLine("首字节(TTFB)", ttfb)
+}
+
+func printPhaseLine(name string, samples []time.Duration) {
+	if len(samples) == 0 {
+		return
+	}
+	avg, p50, p95, p99 := durationPercentiles(samples)
+	fmt.Printf("%s: %d 次 | 平均: %.1fms | P50/P95/P99: %.1f/%.1f/%.1fms\n",
+		name, len(samples), avg, p50, p95, p99)
+}
+
+// 阶段耗时的JSON报告片段
+func phaseAnalysis() map[string]interface{} {
+	phaseTimes.mu.Lock()
+	defer phaseTimes.mu.Unlock()
+
+	analysis := make(map[string]interface{})
+	for name, samples := range map[string][]time.Duration{
+		"dns":  phaseTimes.dns,
+		"tcp_connect": phaseTimes.connect,
+		"ttfb": phaseTimes.ttfb,
+	} {
+		if len(samples) == 0 {
+			continue
+		}
+		avg, p50, p95, p99 := durationPercentiles(samples)
+		analysis[name] = map[string]interface{}{
+			"count": len(samples), "avg_ms": avg, "p50_ms": p50, "p95_ms": p95, "p99_ms": p99,
+		}
+	}
+	if len(analysis) == 0 {
+		return nil
+	}
+	return analysis
+}
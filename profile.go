@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// ===================================================================================
+// --- 资源档位预设 (Resource Profile Presets) ---
+// 内置默认值按大内存服务器调校，在树莓派/低配VPS上直接用会先把压测机自己压垮；
+// 档位一次性重置并发数、请求缓存、连接池与延迟采样规模到匹配的量级，
+// 配置文件和命令行参数仍可逐项覆盖 (档位只替换默认值，不锁定)
+// 用法: -profile small|medium|large|raspberry-pi
+// ===================================================================================
+
+// 当前生效的档位名，为空沿用内置默认值 (等价large)
+var LoadProfile = ""
+
+// 一个档位缩放的全部旋钮
+type resourceProfile struct {
+	workers        int // 并发协程数
+	cacheSize      int // 预生成请求缓存条数
+	maxIdleConns   int // 连接池总量
+	maxIdlePerHost int // 每主机连接池
+	sampleCap      int // 场景步骤延迟样本上限
+	targetSketches int // 分目标延迟草图上限
+}
+
+var resourceProfiles = map[string]resourceProfile{
+	// 树莓派/单板机 (~1GB内存): 缓存和采样是主要内存开销，压到最低
+	"raspberry-pi": {workers: 200, cacheSize: 1000, maxIdleConns: 400, maxIdlePerHost: 200, sampleCap: 5000, targetSketches: 10},
+	// 低配VPS/笔记本 (~2核/2GB)
+	"small": {workers: 500, cacheSize: 2000, maxIdleConns: 1000, maxIdlePerHost: 500, sampleCap: 10000, targetSketches: 25},
+	// 普通工作站 (~8核/16GB)
+	"medium": {workers: 2000, cacheSize: 5000, maxIdleConns: 4000, maxIdlePerHost: 2000, sampleCap: 20000, targetSketches: 50},
+	// 大内存服务器: 与内置默认值一致
+	"large": {workers: 10000, cacheSize: 20000, maxIdleConns: 20000, maxIdlePerHost: 20000, sampleCap: 50000, targetSketches: 100},
+}
+
+// 应用档位: 整体替换相关默认值并报告生效参数
+func applyResourceProfile(name string) error {
+	p, ok := resourceProfiles[name]
+	if !ok {
+		return fmt.Errorf("未知资源档位 %q，可选: %s", name, strings.Join(profileNames(), "/"))
+	}
+	LoadProfile = name
+	NumConcurrentWorkers = p.workers
+	CacheSize = p.cacheSize
+	MaxIdleConns = p.maxIdleConns
+	MaxIdleConnsPerHost = p.maxIdlePerHost
+	ScenarioSampleCap = p.sampleCap
+	TargetSketchLimit = p.targetSketches
+	log.Printf("已应用资源档位 %s: 并发=%d 缓存=%d 连接池=%d/%d 采样上限=%d",
+		name, p.workers, p.cacheSize, p.maxIdleConns, p.maxIdlePerHost, p.sampleCap)
+	return nil
+}
+
+// 档位名列表 (排序后用于错误提示)
+func profileNames() []string {
+	names := make([]string, 0, len(resourceProfiles))
+	for name := range resourceProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
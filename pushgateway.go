@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ===================================================================================
+// --- Pushgateway 指标推送 (Pushgateway Summary Push) ---
+// 运行结束后把汇总指标推送到 Prometheus Pushgateway，让CI中的短时任务也能进入看板
+// ===================================================================================
+
+var (
+	PushgatewayURL      = ""            // 为空则不推送，如 http://pushgateway:9091
+	PushgatewayJob      = "load-tester" // job标签
+	PushgatewayInstance = ""            // instance标签，为空则使用主机名
+)
+
+// 推送最终汇总指标 (Prometheus文本格式，PUT覆盖同标签组的旧值)
+func pushSummaryMetrics(stats *StatsSnapshot, totalDuration time.Duration, scoreCard *ScoreCard) {
+	if PushgatewayURL == "" {
+		return
+	}
+
+	totalReq := stats.TotalRequests
+	totalResp := stats.TotalResponses
+	totalFailed := stats.FailedRequests
+	qps := float64(totalReq) / totalDuration.Seconds()
+	successRate := float64(totalResp) / math.Max(float64(totalReq), 1) * 100
+
+	var sb strings.Builder
+	writeMetric := func(name, help string, value float64) {
+		fmt.Fprintf(&sb, "# HELP %s %s\n# TYPE %s gauge\n%s %g\n", name, help, name, name, value)
+	}
+
+	writeMetric("loadtest_requests_total", "Total requests issued", float64(totalReq))
+	writeMetric("loadtest_responses_total", "Total successful responses", float64(totalResp))
+	writeMetric("loadtest_failed_total", "Total failed requests", float64(totalFailed))
+	writeMetric("loadtest_timeout_total", "Total timed-out requests", float64(stats.TimeoutCount))
+	writeMetric("loadtest_qps", "Average requests per second", qps)
+	writeMetric("loadtest_success_rate_percent", "Success rate percentage", successRate)
+	writeMetric("loadtest_duration_seconds", "Total test duration", totalDuration.Seconds())
+	writeMetric("loadtest_response_bytes_total", "Total response bytes", float64(stats.TotalResponseSize))
+
+	if len(stats.ResponseTimes) > 0 {
+		times := make([]time.Duration, len(stats.ResponseTimes))
+		copy(times, stats.ResponseTimes)
+		sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+		writeMetric("loadtest_latency_p50_ms", "P50 latency", float64(times[len(times)/2].Nanoseconds())/1e6)
+		writeMetric("loadtest_latency_p95_ms", "P95 latency", float64(times[int(float64(len(times))*0.95)].Nanoseconds())/1e6)
+		writeMetric("loadtest_latency_p99_ms", "P99 latency", float64(times[int(float64(len(times))*0.99)].Nanoseconds())/1e6)
+	}
+
+	if scoreCard != nil {
+		writeMetric("loadtest_score", "Overall score (0-100)", scoreCard.TotalScore)
+	}
+
+	instance := PushgatewayInstance
+	if instance == "" {
+		instance, _ = os.Hostname()
+	}
+	pushURL := fmt.Sprintf("%s/metrics/job/%s/instance/%s",
+		strings.TrimSuffix(PushgatewayURL, "/"),
+		url.PathEscape(PushgatewayJob), url.PathEscape(instance))
+
+	req, err := http.NewRequest("PUT", pushURL, strings.NewReader(sb.String()))
+	if err != nil {
+		log.Printf("警告: 构造Pushgateway请求失败: %v", err)
+		return
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		log.Printf("警告: 推送指标到Pushgateway失败: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		log.Printf("警告: Pushgateway返回 HTTP %d", resp.StatusCode)
+		return
+	}
+	log.Printf("汇总指标已推送到: %s", pushURL)
+}
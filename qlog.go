@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	mathrand "math/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/logging"
+)
+
+// ===================================================================================
+// --- QUIC qlog导出 (QUIC qlog Export) ---
+// 对采样的HTTP/3连接输出qlog (JSON-SEQ格式) 到目录，qvis可直接加载，
+// 深挖QUIC层的丢包与拥塞控制行为。quic-go自带的qlog包要额外引入gojay，
+// 这里手写覆盖恢复层关键事件 (丢包/拥塞状态/RTT与窗口) 的精简实现
+// ===================================================================================
+
+var (
+	// qlog输出目录，为空不导出
+	QlogDir = ""
+	// 连接采样百分比
+	QlogSamplePct = 10
+)
+
+// 文件数硬上限，防止长压测写爆目录
+const maxQlogFiles = 100
+
+var (
+	qlogDirOnce sync.Once
+	qlogDirOK   bool
+	qlogFileSeq int64
+)
+
+// 单条连接的qlog写入器，事件时间为相对连接建立的毫秒数
+type qlogWriter struct {
+	mu       sync.Mutex
+	f        *os.File
+	start    time.Time
+	lastCwnd logging.ByteCount
+}
+
+// 追加一条JSON-SEQ记录 (RS分隔符 + JSON + 换行)
+func (w *qlogWriter) record(v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.f == nil {
+		return
+	}
+	w.f.Write([]byte{0x1e})
+	w.f.Write(data)
+	w.f.Write([]byte{'\n'})
+}
+
+func (w *qlogWriter) event(name string, data map[string]interface{}) {
+	w.record(map[string]interface{}{
+		"time": float64(time.Since(w.start).Nanoseconds()) / 1e6,
+		"name": name,
+		"data": data,
+	})
+}
+
+func (w *qlogWriter) close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.f != nil {
+		w.f.Close()
+		w.f = nil
+	}
+}
+
+// 构造quic.Config的Tracer: 按采样比例为连接创建qlog文件，未启用时返回nil
+func qlogTracer() func(context.Context, logging.Perspective, quic.ConnectionID) *logging.ConnectionTracer {
+	if QlogDir == "" {
+		return nil
+	}
+	return func(ctx context.Context, p logging.Perspective, connID quic.ConnectionID) *logging.ConnectionTracer {
+		if QlogSamplePct < 100 && mathrand.Intn(100) >= QlogSamplePct {
+			return nil
+		}
+		if atomic.AddInt64(&qlogFileSeq, 1) > maxQlogFiles {
+			return nil
+		}
+
+		qlogDirOnce.Do(func() {
+			if err := os.MkdirAll(QlogDir, 0755); err != nil {
+				log.Printf("警告: 无法创建qlog目录: %v", err)
+				return
+			}
+			fmt.Printf("QUIC qlog已启用: 目录 %s，采样 %d%%，最多 %d 条连接\n", QlogDir, QlogSamplePct, maxQlogFiles)
+			qlogDirOK = true
+		})
+		if !qlogDirOK {
+			return nil
+		}
+
+		name := fmt.Sprintf("%s_%x.sqlog", time.Now().Format("20060102_150405"), connID)
+		f, err := os.Create(filepath.Join(QlogDir, name))
+		if err != nil {
+			log.Printf("警告: 无法创建qlog文件: %v", err)
+			return nil
+		}
+
+		w := &qlogWriter{f: f, start: time.Now()}
+		// 文件头记录 (qlog JSON-SEQ)
+		w.record(map[string]interface{}{
+			"qlog_version": "0.3",
+			"qlog_format":  "JSON-SEQ",
+			"title":        "load-tester HTTP/3 trace",
+			"trace": map[string]interface{}{
+				"vantage_point": map[string]interface{}{"type": "client"},
+				"common_fields": map[string]interface{}{"ODCID": fmt.Sprintf("%x", connID)},
+			},
+		})
+
+		return &logging.ConnectionTracer{
+			StartedConnection: func(local, remote net.Addr, srcConnID, destConnID logging.ConnectionID) {
+				w.event("transport:connection_started", map[string]interface{}{
+					"src_ip": local.String(), "dst_ip": remote.String(),
+					"src_cid": fmt.Sprintf("%x", srcConnID), "dst_cid": fmt.Sprintf("%x", destConnID),
+				})
+			},
+			UpdatedMetrics: func(rttStats *logging.RTTStats, cwnd, bytesInFlight logging.ByteCount, packetsInFlight int) {
+				// 该回调每个包都会触发，仅在窗口变化时记录，控制文件体积
+				if cwnd == w.lastCwnd {
+					return
+				}
+				w.lastCwnd = cwnd
+				w.event("recovery:metrics_updated", map[string]interface{}{
+					"smoothed_rtt":      float64(rttStats.SmoothedRTT().Nanoseconds()) / 1e6,
+					"latest_rtt":        float64(rttStats.LatestRTT().Nanoseconds()) / 1e6,
+					"min_rtt":           float64(rttStats.MinRTT().Nanoseconds()) / 1e6,
+					"congestion_window": int64(cwnd),
+					"bytes_in_flight":   int64(bytesInFlight),
+					"packets_in_flight": packetsInFlight,
+				})
+			},
+			LostPacket: func(level logging.EncryptionLevel, pn logging.PacketNumber, reason logging.PacketLossReason) {
+				trigger := "reordering_threshold"
+				if reason == logging.PacketLossTimeThreshold {
+					trigger = "time_threshold"
+				}
+				w.event("recovery:packet_lost", map[string]interface{}{
+					"packet_number":    int64(pn),
+					"encryption_level": level.String(),
+					"trigger":          trigger,
+				})
+			},
+			UpdatedCongestionState: func(state logging.CongestionState) {
+				w.event("recovery:congestion_state_updated", map[string]interface{}{
+					"new": congestionStateName(state),
+				})
+			},
+			ClosedConnection: func(err error) {
+				data := map[string]interface{}{}
+				if err != nil {
+					data["trigger"] = err.Error()
+				}
+				w.event("transport:connection_closed", data)
+			},
+			Close: func() {
+				w.close()
+			},
+		}
+	}
+}
+
+func congestionStateName(state logging.CongestionState) string {
+	switch state {
+	case logging.CongestionStateSlowStart:
+		return "slow_start"
+	case logging.CongestionStateCongestionAvoidance:
+		return "congestion_avoidance"
+	case logging.CongestionStateRecovery:
+		return "recovery"
+	case logging.CongestionStateApplicationLimited:
+		return "application_limited"
+	}
+	return "unknown"
+}
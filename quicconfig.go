@@ -0,0 +1,62 @@
+package main
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go"
+)
+
+// ===================================================================================
+// --- QUIC调优参数 (QUIC Tuning Knobs) ---
+// 把quic-go的流控窗口、空闲超时和保活暴露到配置，
+// HTTP/3吞吐测试不再被库默认值卡住
+// ===================================================================================
+
+var (
+	// 流/连接级初始流控窗口 (字节)，0沿用库默认
+	QUICInitialStreamWindow     int64 = 0
+	QUICInitialConnectionWindow int64 = 0
+	// 流/连接级流控窗口上限 (字节)，0沿用库默认
+	QUICMaxStreamWindow     int64 = 0
+	QUICMaxConnectionWindow int64 = 0
+	// 连接最大空闲时长，0沿用库默认
+	QUICMaxIdleTimeout time.Duration = 0
+	// 保活间隔，0不保活
+	QUICKeepAlivePeriod time.Duration = 0
+)
+
+// 组装HTTP/3客户端的quic.Config；无任何定制项时返回nil，沿用库默认值
+func buildQuicConfig() *quic.Config {
+	cfg := &quic.Config{Tracer: qlogTracer()}
+	custom := cfg.Tracer != nil
+
+	if QUICInitialStreamWindow > 0 {
+		cfg.InitialStreamReceiveWindow = uint64(QUICInitialStreamWindow)
+		custom = true
+	}
+	if QUICInitialConnectionWindow > 0 {
+		cfg.InitialConnectionReceiveWindow = uint64(QUICInitialConnectionWindow)
+		custom = true
+	}
+	if QUICMaxStreamWindow > 0 {
+		cfg.MaxStreamReceiveWindow = uint64(QUICMaxStreamWindow)
+		custom = true
+	}
+	if QUICMaxConnectionWindow > 0 {
+		cfg.MaxConnectionReceiveWindow = uint64(QUICMaxConnectionWindow)
+		custom = true
+	}
+	if QUICMaxIdleTimeout > 0 {
+		cfg.MaxIdleTimeout = QUICMaxIdleTimeout
+		custom = true
+	}
+	if QUICKeepAlivePeriod > 0 {
+		cfg.KeepAlivePeriod = QUICKeepAlivePeriod
+		custom = true
+	}
+
+	if !custom {
+		return nil
+	}
+	return cfg
+}
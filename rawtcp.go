@@ -0,0 +1,291 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	mathrand "math/rand"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ===================================================================================
+// --- 二进制协议模板模式 (Raw TCP Hex Templates) ---
+// 私有二进制协议不用写Go代码也能压: 载荷用hex/base64模板定义
+// (支持 {{seq:N}} 递增序号和 {{rand:N}} 随机字节占位符)，
+// 响应按前缀字节或定长规则判定成功，明文TCP或TLS皆可
+// ===================================================================================
+
+// 模板中的一段: 字面字节或占位符
+type rawSegment struct {
+	literal []byte
+	kind    string // "" / "seq" / "rand"
+	size    int
+}
+
+// 一个原始TCP目标
+type RawTCPTarget struct {
+	Name    string
+	Address string // host:port
+	UseTLS  bool
+	// 响应判定: 前缀字节和/或期望读取的总长度，都为空则只写不读
+	RespPrefix []byte
+	RespLength int
+	Timeout    time.Duration
+
+	segments []rawSegment
+	seq      int64
+
+	requests int64
+	failed   int64
+	mu       sync.Mutex
+	dur      []time.Duration
+}
+
+// 配置的原始TCP目标，非空启用该模式
+var RawTCPTargets []*RawTCPTarget
+
+// 解析hex模板为段序列: 占位符为 {{seq:N}} / {{rand:N}} (N为字节数)
+func parseHexTemplate(s string) ([]rawSegment, error) {
+	var segments []rawSegment
+	for len(s) > 0 {
+		start := indexOfPlaceholder(s)
+		if start < 0 {
+			literal, err := hex.DecodeString(s)
+			if err != nil {
+				return nil, fmt.Errorf("无效的hex字面量 %q: %v", s, err)
+			}
+			segments = append(segments, rawSegment{literal: literal})
+			break
+		}
+		if start > 0 {
+			literal, err := hex.DecodeString(s[:start])
+			if err != nil {
+				return nil, fmt.Errorf("无效的hex字面量 %q: %v", s[:start], err)
+			}
+			segments = append(segments, rawSegment{literal: literal})
+			s = s[start:]
+		}
+		end := bytes.Index([]byte(s), []byte("}}"))
+		if end < 0 {
+			return nil, fmt.Errorf("占位符未闭合: %q", s)
+		}
+		token := s[2:end]
+		s = s[end+2:]
+
+		var kind string
+		var size int
+		if n, err := fmt.Sscanf(token, "seq:%d", &size); n == 1 && err == nil {
+			kind = "seq"
+		} else if n, err := fmt.Sscanf(token, "rand:%d", &size); n == 1 && err == nil {
+			kind = "rand"
+		} else {
+			return nil, fmt.Errorf("无效占位符 {{%s}} (支持 seq:N / rand:N)", token)
+		}
+		if size < 1 || size > 8 {
+			return nil, fmt.Errorf("占位符 {{%s}} 的字节数需在1-8之间", token)
+		}
+		segments = append(segments, rawSegment{kind: kind, size: size})
+	}
+	return segments, nil
+}
+
+func indexOfPlaceholder(s string) int {
+	return bytes.Index([]byte(s), []byte("{{"))
+}
+
+// 解析base64载荷 (无占位符的备选形式)
+func parseBase64Payload(s string) ([]rawSegment, error) {
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("无效的base64载荷: %v", err)
+	}
+	return []rawSegment{{literal: data}}, nil
+}
+
+// 按模板生成一次载荷
+func (t *RawTCPTarget) buildPayload() []byte {
+	var buf bytes.Buffer
+	for _, seg := range t.segments {
+		switch seg.kind {
+		case "seq":
+			var full [8]byte
+			binary.BigEndian.PutUint64(full[:], uint64(atomic.AddInt64(&t.seq, 1)))
+			buf.Write(full[8-seg.size:])
+		case "rand":
+			random := make([]byte, seg.size)
+			mathrand.Read(random)
+			buf.Write(random)
+		default:
+			buf.Write(seg.literal)
+		}
+	}
+	return buf.Bytes()
+}
+
+// 原始TCP压测入口: 协程轮转目标循环发送
+func runRawTCPLoad(ctx context.Context, stats *Stats, wg *sync.WaitGroup) {
+	fmt.Printf("二进制协议模式: %d 个目标 x %d 并发\n", len(RawTCPTargets), NumConcurrentWorkers)
+	var dispatched int64
+
+	for i := 0; i < NumConcurrentWorkers; i++ {
+		wg.Add(1)
+		go func(workerID int) {
+			defer wg.Done()
+			for iter := 0; ; iter++ {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+				}
+				if TestDuration <= 0 && atomic.AddInt64(&dispatched, 1) > int64(TotalDownloads) {
+					return
+				}
+				if !testDeadline.IsZero() && time.Now().After(testDeadline) {
+					return
+				}
+				waitWhilePaused(ctx)
+
+				target := RawTCPTargets[(workerID+iter)%len(RawTCPTargets)]
+				executeRawTCP(target, stats)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+// 单次发送: 拨号 → 写载荷 → 按规则读响应
+func executeRawTCP(t *RawTCPTarget, stats *Stats) {
+	atomic.AddInt64(&t.requests, 1)
+	atomic.AddInt64(&stats.TotalRequests, 1)
+	start := time.Now()
+
+	timeout := t.Timeout
+	if timeout <= 0 {
+		timeout = RequestTimeout
+	}
+	conn, err := net.DialTimeout("tcp", t.Address, timeout)
+	if err != nil {
+		rawTCPFailed(t, stats, "RawTCP_连接失败")
+		return
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	if t.UseTLS {
+		rawTLSConfig := &tls.Config{
+			InsecureSkipVerify: IgnoreSSLErrors,
+			MinVersion:         uint16(MinTLSVersion),
+			MaxVersion:         uint16(MaxTLSVersion),
+			KeyLogWriter:       tlsKeyLogWriter(),
+			ServerName:         rawTCPServerName(t.Address),
+		}
+		attachClientCertPool(rawTLSConfig)
+		tlsConn := tls.Client(conn, rawTLSConfig)
+		if err := tlsConn.Handshake(); err != nil {
+			rawTCPFailed(t, stats, "RawTCP_TLS握手失败")
+			return
+		}
+		conn = tlsConn
+	}
+
+	if _, err := conn.Write(t.buildPayload()); err != nil {
+		rawTCPFailed(t, stats, "RawTCP_发送失败")
+		return
+	}
+
+	// 读响应: 定长优先，其次按前缀长度读
+	want := t.RespLength
+	if want == 0 {
+		want = len(t.RespPrefix)
+	}
+	if want > 0 {
+		resp := make([]byte, want)
+		if _, err := io.ReadFull(conn, resp); err != nil {
+			rawTCPFailed(t, stats, "RawTCP_读取失败")
+			return
+		}
+		if len(t.RespPrefix) > 0 && !bytes.HasPrefix(resp, t.RespPrefix) {
+			rawTCPFailed(t, stats, "RawTCP_响应不匹配")
+			return
+		}
+		atomic.AddInt64(&stats.TotalResponseSize, int64(want))
+	}
+
+	duration := time.Since(start)
+	atomic.AddInt64(&stats.TotalResponses, 1)
+	recordResponseTime(stats, duration)
+	t.mu.Lock()
+	if len(t.dur) < 50000 {
+		t.dur = append(t.dur, duration)
+	}
+	t.mu.Unlock()
+}
+
+func rawTCPFailed(t *RawTCPTarget, stats *Stats, errType string) {
+	atomic.AddInt64(&t.failed, 1)
+	atomic.AddInt64(&stats.FailedRequests, 1)
+	recordError(stats, errType)
+}
+
+func rawTCPServerName(address string) string {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+	return host
+}
+
+// 供JSON报告使用的按目标统计
+func rawTCPAnalysis() []map[string]interface{} {
+	if len(RawTCPTargets) == 0 {
+		return nil
+	}
+	var rows []map[string]interface{}
+	for _, t := range RawTCPTargets {
+		row := map[string]interface{}{
+			"name":     t.Name,
+			"address":  t.Address,
+			"tls":      t.UseTLS,
+			"requests": atomic.LoadInt64(&t.requests),
+			"failed":   atomic.LoadInt64(&t.failed),
+		}
+		t.mu.Lock()
+		if len(t.dur) > 0 {
+			_, p50, p95, p99 := durationPercentiles(t.dur)
+			row["p50_ms"], row["p95_ms"], row["p99_ms"] = p50, p95, p99
+		}
+		t.mu.Unlock()
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// 打印各目标的独立统计
+func printRawTCPReport() {
+	if len(RawTCPTargets) == 0 {
+		return
+	}
+
+	fmt.Printf("\n=== 二进制协议统计 ===\n")
+	for _, t := range RawTCPTargets {
+		requests := atomic.LoadInt64(&t.requests)
+		failed := atomic.LoadInt64(&t.failed)
+		fmt.Printf("[%s] %s: %d 次 | 失败 %.1f%%", t.Name, t.Address, requests,
+			float64(failed)/float64(max64(requests, 1))*100)
+		t.mu.Lock()
+		if len(t.dur) > 0 {
+			_, p50, p95, p99 := durationPercentiles(t.dur)
+			fmt.Printf(" | P50/P95/P99: %.1f/%.1f/%.1fms", p50, p95, p99)
+		}
+		t.mu.Unlock()
+		fmt.Printf("\n")
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// ===================================================================================
+// --- 录制代理 (Recording Proxy) ---
+// `record` 子命令启动本地正向代理，浏览器/应用把流量指过来，
+// 捕获的请求以扩展dependency格式落盘，之后可直接按规模回放；
+// 相同的 方法+URL 聚合为一行并把出现次数记为weight
+// ===================================================================================
+
+// 录制请求体的大小上限，超过的只记录URL
+const recordMaxBodySize = 1 << 20
+
+// 一条聚合后的录制记录
+type recordedRequest struct {
+	method   string
+	url      string
+	headers  map[string]string
+	bodyFile string
+	count    int
+}
+
+type trafficRecorder struct {
+	mu       sync.Mutex
+	requests map[string]*recordedRequest // key: 方法+空格+URL
+	order    []string
+	bodyDir  string
+	bodySeq  int
+}
+
+// 录制代理入口: 监听addr，SIGINT/SIGTERM时把录制结果写入outPath
+func runRecordProxy(addr, outPath string) error {
+	rec := &trafficRecorder{
+		requests: make(map[string]*recordedRequest),
+		bodyDir:  outPath + ".bodies",
+	}
+
+	server := &http.Server{Addr: addr, Handler: rec}
+	go func() {
+		log.Printf("录制代理监听于 %s，把浏览器/应用的HTTP代理指向它; Ctrl-C结束录制", addr)
+		log.Printf("HTTPS流量经CONNECT隧道透传，只能记录目标主机")
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("录制代理启动失败: %v", err)
+		}
+	}()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	<-sigChan
+
+	if err := rec.flush(outPath); err != nil {
+		return err
+	}
+	fmt.Printf("录制完成: %d 条目标写入 %s\n", len(rec.order), outPath)
+	return nil
+}
+
+// 代理处理: 明文HTTP完整记录后转发，CONNECT只记主机并透传字节流
+func (rec *trafficRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodConnect {
+		rec.add(&recordedRequest{method: "GET", url: "https://" + r.Host + "/"})
+		rec.tunnel(w, r)
+		return
+	}
+	if !r.URL.IsAbs() {
+		http.Error(w, "仅支持代理请求 (绝对URI)", http.StatusBadRequest)
+		return
+	}
+
+	entry := &recordedRequest{method: r.Method, url: r.URL.String()}
+	for name := range r.Header {
+		// 代理和逐跳头不属于场景本身
+		switch http.CanonicalHeaderKey(name) {
+		case "Proxy-Connection", "Proxy-Authorization", "Connection", "Keep-Alive", "Te", "Trailer", "Transfer-Encoding", "Upgrade":
+			continue
+		}
+		if entry.headers == nil {
+			entry.headers = make(map[string]string)
+		}
+		entry.headers[name] = r.Header.Get(name)
+	}
+
+	var body []byte
+	if r.Body != nil {
+		body, _ = io.ReadAll(io.LimitReader(r.Body, recordMaxBodySize))
+		r.Body.Close()
+	}
+	rec.addWithBody(entry, body)
+
+	// 转发并回传响应
+	outReq, err := http.NewRequest(r.Method, r.URL.String(), strings.NewReader(string(body)))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	outReq.Header = r.Header.Clone()
+	resp, err := http.DefaultTransport.RoundTrip(outReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+	for name, values := range resp.Header {
+		for _, v := range values {
+			w.Header().Add(name, v)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
+}
+
+// CONNECT隧道透传
+func (rec *trafficRecorder) tunnel(w http.ResponseWriter, r *http.Request) {
+	upstream, err := net.Dial("tcp", r.Host)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		upstream.Close()
+		http.Error(w, "不支持连接劫持", http.StatusInternalServerError)
+		return
+	}
+	client, _, err := hijacker.Hijack()
+	if err != nil {
+		upstream.Close()
+		return
+	}
+	client.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n"))
+	go func() {
+		io.Copy(upstream, client)
+		upstream.Close()
+	}()
+	io.Copy(client, upstream)
+	client.Close()
+}
+
+// 聚合一条记录 (相同 方法+URL 只加权重)
+func (rec *trafficRecorder) add(entry *recordedRequest) {
+	key := entry.method + " " + entry.url
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if existing, ok := rec.requests[key]; ok {
+		existing.count++
+		return
+	}
+	entry.count = 1
+	rec.requests[key] = entry
+	rec.order = append(rec.order, key)
+}
+
+// 带请求体的记录: 体落盘到bodyDir并以body=@文件引用
+func (rec *trafficRecorder) addWithBody(entry *recordedRequest, body []byte) {
+	if len(body) > 0 {
+		rec.mu.Lock()
+		key := entry.method + " " + entry.url
+		_, exists := rec.requests[key]
+		if !exists {
+			rec.bodySeq++
+			entry.bodyFile = filepath.Join(rec.bodyDir, fmt.Sprintf("body_%04d.bin", rec.bodySeq))
+		}
+		rec.mu.Unlock()
+		if entry.bodyFile != "" {
+			os.MkdirAll(rec.bodyDir, 0755)
+			if err := os.WriteFile(entry.bodyFile, body, 0644); err != nil {
+				log.Printf("警告: 写入请求体文件失败: %v", err)
+				entry.bodyFile = ""
+			}
+		}
+	}
+	rec.add(entry)
+}
+
+// 把录制结果写成扩展dependency格式
+func (rec *trafficRecorder) flush(outPath string) error {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	var b strings.Builder
+	b.WriteString("# 由 record 子命令录制\n")
+	for _, key := range rec.order {
+		entry := rec.requests[key]
+		line := []string{}
+		if entry.method != "GET" {
+			line = append(line, entry.method)
+		}
+		line = append(line, entry.url)
+		if entry.count > 1 {
+			weight := entry.count
+			if weight > maxWeightPerTarget {
+				weight = maxWeightPerTarget
+			}
+			line = append(line, fmt.Sprintf("weight=%d", weight))
+		}
+		if entry.bodyFile != "" {
+			line = append(line, "body=@"+entry.bodyFile)
+		}
+		names := make([]string, 0, len(entry.headers))
+		for name := range entry.headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			line = append(line, fmt.Sprintf("header=%s:%s", name, entry.headers[name]))
+		}
+		b.WriteString(strings.Join(line, " "))
+		b.WriteString("\n")
+	}
+	return os.WriteFile(outPath, []byte(b.String()), 0644)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync/atomic"
+)
+
+// ===================================================================================
+// --- 重定向策略 (Redirect Policy) ---
+// http.Client默认静默跟随最多10次重定向，压测中这会掩盖目标的3xx行为
+// (登录跳转、CDN回源、http到https)，也让单个"请求"实际打出多次往返；
+// 策略可配: follow按上限跟随 / deny一律不跟随 / same-host只跟随同主机内跳转，
+// 被策略截停的链以终态3xx响应呈现，与错误分开归类统计
+// ===================================================================================
+
+var (
+	// follow / deny / same-host
+	RedirectPolicy = "follow"
+	// 单链最多跟随的跳转次数 (follow与same-host策略下生效)
+	MaxRedirects = 10
+)
+
+// 被策略或跳数上限截停的重定向链数
+var redirectStops int64
+
+func validateRedirectPolicy() error {
+	switch RedirectPolicy {
+	case "follow", "deny", "same-host":
+		return nil
+	}
+	return fmt.Errorf("无效的redirect_policy: %s (可选 follow/deny/same-host)", RedirectPolicy)
+}
+
+// 供http.Client使用的CheckRedirect: 返回ErrUseLastResponse时客户端停止跟随，
+// 调用方拿到的是3xx响应本身而不是错误
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if RedirectPolicy == "deny" {
+		atomic.AddInt64(&redirectStops, 1)
+		return http.ErrUseLastResponse
+	}
+	if RedirectPolicy == "same-host" && req.URL.Host != via[0].URL.Host {
+		atomic.AddInt64(&redirectStops, 1)
+		return http.ErrUseLastResponse
+	}
+	if len(via) >= MaxRedirects {
+		atomic.AddInt64(&redirectStops, 1)
+		return http.ErrUseLastResponse
+	}
+	return nil
+}
+
+// 响应到手后回看本链实际跟随了几跳: 每个被跟随的重定向都会把前一个响应
+// 挂在resp.Request.Response上，沿链回溯即得跳数
+func recordRedirectChain(stats *Stats, resp *http.Response) {
+	hops := 0
+	for r := resp.Request.Response; r != nil; r = r.Request.Response {
+		hops++
+	}
+	if hops == 0 {
+		return
+	}
+	atomic.AddInt64(&stats.RedirectsFollowed, int64(hops))
+	key := fmt.Sprintf("%d跳", hops)
+	stats.mu.Lock()
+	if stats.RedirectChains == nil {
+		stats.RedirectChains = make(map[string]int64)
+	}
+	stats.RedirectChains[key]++
+	stats.mu.Unlock()
+}
+
+// 供JSON报告使用
+func redirectAnalysis(snap *StatsSnapshot) map[string]interface{} {
+	stops := atomic.LoadInt64(&redirectStops)
+	if snap.RedirectsFollowed == 0 && snap.Redirect3xxResponses == 0 && stops == 0 {
+		return nil
+	}
+	return map[string]interface{}{
+		"policy":             RedirectPolicy,
+		"max_redirects":      MaxRedirects,
+		"redirects_followed": snap.RedirectsFollowed,
+		"terminal_3xx":       snap.Redirect3xxResponses,
+		"chains_stopped":     stops,
+		"chain_distribution": snap.RedirectChains,
+	}
+}
+
+// 打印重定向统计
+func printRedirectReport(snap *StatsSnapshot) {
+	stops := atomic.LoadInt64(&redirectStops)
+	if snap.RedirectsFollowed == 0 && snap.Redirect3xxResponses == 0 && stops == 0 {
+		return
+	}
+	fmt.Printf("\n=== 重定向统计 (策略: %s, 上限: %d) ===\n", RedirectPolicy, MaxRedirects)
+	fmt.Printf("跟随跳转: %d  终态3xx响应: %d  被策略截停的链: %d\n",
+		snap.RedirectsFollowed, snap.Redirect3xxResponses, stops)
+	if len(snap.RedirectChains) > 0 {
+		keys := make([]string, 0, len(snap.RedirectChains))
+		for k := range snap.RedirectChains {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fmt.Printf("链长分布:")
+		for _, k := range keys {
+			fmt.Printf("  %s x%d", k, snap.RedirectChains[k])
+		}
+		fmt.Println()
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ===================================================================================
+// --- 远程目标列表 (Remote Target List) ---
+// 目标列表除本地dependency.txt外还可以从HTTP(S)地址获取并周期刷新，
+// 一组压测机共享中心维护的同一份列表，行格式与本地文件完全相同
+// ===================================================================================
+
+var (
+	// 远程目标列表地址，为空使用本地dependency.txt
+	TargetListURL = ""
+	// 拉取列表时附带的认证头，格式 "名称: 值"
+	TargetListAuthHeader = ""
+	// 刷新间隔，0为只在启动时拉取一次
+	TargetListRefresh time.Duration = 0
+)
+
+// 拉取并解析远程目标列表
+func fetchRemoteTargetList() ([]TargetSpec, []string, error) {
+	req, err := http.NewRequest("GET", TargetListURL, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("无效的目标列表地址: %v", err)
+	}
+	if TargetListAuthHeader != "" {
+		name, value, ok := strings.Cut(TargetListAuthHeader, ":")
+		if !ok || name == "" {
+			return nil, nil, fmt.Errorf("无效的目标列表认证头 (需为 名称: 值): %s", TargetListAuthHeader)
+		}
+		req.Header.Set(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("拉取目标列表失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return nil, nil, fmt.Errorf("拉取目标列表失败: HTTP %d", resp.StatusCode)
+	}
+
+	specs, urls, err := parseTargetList(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("解析目标列表失败: %v", err)
+	}
+	return specs, urls, nil
+}
+
+// 启动时加载远程目标列表，并按需启动刷新协程
+func loadRemoteTargetList() error {
+	specs, urls, err := fetchRemoteTargetList()
+	if err != nil {
+		return err
+	}
+	if len(urls) == 0 {
+		return fmt.Errorf("远程目标列表中没有找到有效的URL")
+	}
+
+	targetMu.Lock()
+	TargetURLs = urls
+	targetMu.Unlock()
+	registerTargetSpecs(specs)
+	log.Printf("成功从 %s 加载 %d 个目标URL", TargetListURL, len(urls))
+
+	if TargetListRefresh > 0 {
+		go refreshTargetListLoop()
+	}
+	return nil
+}
+
+// 周期刷新远程目标列表；拉取失败或列表为空时保留当前列表继续压测
+func refreshTargetListLoop() {
+	ticker := time.NewTicker(TargetListRefresh)
+	defer ticker.Stop()
+	for range ticker.C {
+		specs, urls, err := fetchRemoteTargetList()
+		if err != nil {
+			log.Printf("警告: 刷新目标列表失败，沿用当前列表: %v", err)
+			continue
+		}
+		if len(urls) == 0 {
+			log.Printf("警告: 刷新到的目标列表为空，沿用当前列表")
+			continue
+		}
+
+		targetMu.Lock()
+		changed := len(urls) != len(TargetURLs)
+		TargetURLs = urls
+		targetMu.Unlock()
+		registerTargetSpecs(specs)
+		if changed {
+			log.Printf("目标列表已刷新: %d 个目标URL", len(urls))
+		}
+	}
+}
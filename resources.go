@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ===================================================================================
+// --- 系统资源采样接口 (OS Resource Sampling) ---
+// FD/套接字/CPU采样按操作系统拆分实现 (resources_linux/darwin/windows.go)，
+// 报告的资源部分不再只有Linux /proc解析能填充；
+// 某平台取不到的指标返回零值，各报告段自行降级
+// ===================================================================================
+
+// 平台相关的资源采样器
+type resourceSampler interface {
+	// 当前打开的FD/句柄数，不可用返回0
+	openFDs() int
+	// TCP连接状态分布 (ESTABLISHED/TIME_WAIT等)，不可用返回nil
+	tcpStates() map[string]int
+	// 各核累计时钟数，不可用返回nil
+	cpuTicks() map[string]cpuTicks
+	// 本进程累计消耗的CPU秒 (用户态+内核态)
+	processCPUSeconds() float64
+}
+
+// 当前平台的采样器，由对应的resources_*.go提供构造
+var osResources resourceSampler = newResourceSampler()
+
+// 单个核的累计时钟数
+type cpuTicks struct {
+	busy  uint64
+	total uint64
+}
+
+var cpuTicksBaseline map[string]cpuTicks
+
+// 进程调优开关 (实现按平台拆分: cputune.go / cputune_other.go)
+var (
+	// 进程nice值 (-20..19)，0不调整
+	ProcessNice = 0
+	// CPU亲和集，如 "0-3,8"，为空不绑定
+	WorkerCPUSet = ""
+)
+
+// 负载启动前记录基线
+func captureCPUBaseline() {
+	cpuTicksBaseline = osResources.cpuTicks()
+}
+
+// 打印测试区间内各核的利用率
+func printCPUReport() {
+	if cpuTicksBaseline == nil {
+		return
+	}
+	cur := osResources.cpuTicks()
+	if cur == nil {
+		return
+	}
+
+	var names []string
+	for name := range cpuTicksBaseline {
+		if _, ok := cur[name]; ok {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+	// cpu10要排在cpu9后面，按编号排序
+	sortCPUNames(names)
+
+	fmt.Printf("\n=== 各核利用率 ===\n")
+	for i, name := range names {
+		base, now := cpuTicksBaseline[name], cur[name]
+		total := now.total - base.total
+		util := 0.0
+		if total > 0 {
+			util = float64(now.busy-base.busy) / float64(total) * 100
+		}
+		fmt.Printf("%s: %5.1f%%  ", name, util)
+		if (i+1)%4 == 0 {
+			fmt.Printf("\n")
+		}
+	}
+	if len(names)%4 != 0 {
+		fmt.Printf("\n")
+	}
+}
+
+func sortCPUNames(names []string) {
+	num := func(name string) int {
+		n, _ := strconv.Atoi(strings.TrimPrefix(name, "cpu"))
+		return n
+	}
+	for i := 1; i < len(names); i++ {
+		for j := i; j > 0 && num(names[j]) < num(names[j-1]); j-- {
+			names[j], names[j-1] = names[j-1], names[j]
+		}
+	}
+}
@@ -0,0 +1,42 @@
+//go:build darwin
+
+package main
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// macOS实现: FD数经/dev/fd；分核时钟和TCP状态需要sysctl/libproc，
+// 纯Go拿不到，返回nil让对应报告段降级
+type darwinResources struct{}
+
+func newResourceSampler() resourceSampler {
+	return darwinResources{}
+}
+
+func (darwinResources) openFDs() int {
+	entries, err := os.ReadDir("/dev/fd")
+	if err != nil {
+		return 0
+	}
+	return len(entries)
+}
+
+func (darwinResources) tcpStates() map[string]int {
+	return nil
+}
+
+func (darwinResources) cpuTicks() map[string]cpuTicks {
+	return nil
+}
+
+func (darwinResources) processCPUSeconds() float64 {
+	var r unix.Rusage
+	if err := unix.Getrusage(unix.RUSAGE_SELF, &r); err != nil {
+		return 0
+	}
+	return float64(r.Utime.Sec) + float64(r.Utime.Usec)/1e6 +
+		float64(r.Stime.Sec) + float64(r.Stime.Usec)/1e6
+}
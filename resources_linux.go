@@ -0,0 +1,111 @@
+//go:build linux
+
+package main
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// Linux实现: /proc解析 + getrusage
+type linuxResources struct{}
+
+func newResourceSampler() resourceSampler {
+	return linuxResources{}
+}
+
+// 统计当前打开的FD数量
+func (linuxResources) openFDs() int {
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return 0
+	}
+	return len(entries)
+}
+
+// /proc/net/tcp中st列的十六进制状态码
+var tcpStateNames = map[string]string{
+	"01": "ESTABLISHED",
+	"02": "SYN_SENT",
+	"03": "SYN_RECV",
+	"04": "FIN_WAIT1",
+	"05": "FIN_WAIT2",
+	"06": "TIME_WAIT",
+	"07": "CLOSE",
+	"08": "CLOSE_WAIT",
+	"09": "LAST_ACK",
+	"0A": "LISTEN",
+	"0B": "CLOSING",
+}
+
+// 解析/proc/self/net/tcp{,6}，按状态聚合连接数
+func (linuxResources) tcpStates() map[string]int {
+	states := make(map[string]int)
+	for _, path := range []string{"/proc/self/net/tcp", "/proc/self/net/tcp6"} {
+		file, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(file)
+		scanner.Scan() // 跳过表头
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) < 4 {
+				continue
+			}
+			if name, ok := tcpStateNames[fields[3]]; ok {
+				states[name]++
+			}
+		}
+		file.Close()
+	}
+	if len(states) == 0 {
+		return nil
+	}
+	return states
+}
+
+// 读取各核的累计时钟数 (busy = 总数 - idle - iowait)
+func (linuxResources) cpuTicks() map[string]cpuTicks {
+	f, err := os.Open("/proc/stat")
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	ticks := make(map[string]cpuTicks)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 8 || !strings.HasPrefix(fields[0], "cpu") || fields[0] == "cpu" {
+			continue
+		}
+		var t cpuTicks
+		for i, field := range fields[1:] {
+			v, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				break
+			}
+			t.total += v
+			// 下标3为idle、4为iowait
+			if i != 3 && i != 4 {
+				t.busy += v
+			}
+		}
+		ticks[fields[0]] = t
+	}
+	return ticks
+}
+
+func (linuxResources) processCPUSeconds() float64 {
+	var r unix.Rusage
+	if err := unix.Getrusage(unix.RUSAGE_SELF, &r); err != nil {
+		return 0
+	}
+	return float64(r.Utime.Sec) + float64(r.Utime.Usec)/1e6 +
+		float64(r.Stime.Sec) + float64(r.Stime.Usec)/1e6
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"fmt"
+	"log"
+	mathrand "math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ===================================================================================
+// --- 原始测量采样导出 (Sampled Raw Measurements) ---
+// 聚合报告定型后就回答不了新问题，而3000万条原始记录又存不起；
+// 按1/N均匀采样把延迟/状态码/URL原始行写成gzip压缩CSV，
+// 之后任何统计都能从采样子集重新推导 (乘回N即是总体估计)
+// ===================================================================================
+
+var (
+	// 非空启用，建议以.csv.gz结尾
+	SampleExportFile = ""
+	// 采样率: 每N条记一条
+	SampleExportRate = 1000
+)
+
+var (
+	sampleMu   sync.Mutex
+	sampleFile *os.File
+	sampleGz   *gzip.Writer
+	sampleCSV  *csv.Writer
+	sampleRows int64
+)
+
+// 打开导出文件并挂上响应观察者
+func initSampleExport() error {
+	if SampleExportFile == "" {
+		return nil
+	}
+	f, err := os.Create(SampleExportFile)
+	if err != nil {
+		return fmt.Errorf("无法创建采样导出文件: %v", err)
+	}
+	sampleFile = f
+	sampleGz = gzip.NewWriter(f)
+	sampleCSV = csv.NewWriter(sampleGz)
+	sampleCSV.Write([]string{"timestamp_ms", "method", "url", "status", "duration_ms", "bytes"})
+
+	RegisterResponseObserver(func(resp *http.Response, duration time.Duration) {
+		if mathrand.Intn(SampleExportRate) != 0 {
+			return
+		}
+		writeSampleRow(resp, duration)
+	})
+	fmt.Printf("测量采样导出已启用: %s (每 %d 条记1条)\n", SampleExportFile, SampleExportRate)
+	return nil
+}
+
+// 写一行采样记录
+func writeSampleRow(resp *http.Response, duration time.Duration) {
+	method, url := "", ""
+	if resp.Request != nil {
+		method = resp.Request.Method
+		url = resp.Request.URL.String()
+	}
+	row := []string{
+		strconv.FormatInt(time.Now().UnixMilli(), 10),
+		method,
+		url,
+		strconv.Itoa(resp.StatusCode),
+		strconv.FormatFloat(float64(duration.Nanoseconds())/1e6, 'f', 3, 64),
+		strconv.FormatInt(resp.ContentLength, 10),
+	}
+
+	sampleMu.Lock()
+	defer sampleMu.Unlock()
+	if sampleCSV == nil {
+		return
+	}
+	sampleCSV.Write(row)
+	atomic.AddInt64(&sampleRows, 1)
+}
+
+// 收尾: 刷出缓冲并关闭gzip流 (不关闭gzip文件会损坏)
+func closeSampleExport() {
+	if SampleExportFile == "" {
+		return
+	}
+	sampleMu.Lock()
+	defer sampleMu.Unlock()
+	sampleCSV.Flush()
+	if err := sampleGz.Close(); err != nil {
+		log.Printf("无法关闭采样导出文件: %v", err)
+	}
+	sampleFile.Close()
+	sampleCSV = nil
+	log.Printf("测量采样已导出到: %s (%d 行，采样率 1/%d)", SampleExportFile,
+		atomic.LoadInt64(&sampleRows), SampleExportRate)
+}
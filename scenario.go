@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ===================================================================================
+// --- 场景引擎 (Scenario Engine) ---
+// 多步用户旅程 (登录 → 列表 → 详情 → 提交): 每个虚拟用户顺序执行步骤，
+// 上一步响应中提取的值 (JSON路径/正则/响应头) 经 ${变量} 注入后续请求；
+// 按步骤聚合独立统计
+// ===================================================================================
+
+// 从响应中提取变量的规则
+type ScenarioExtract struct {
+	From    string // "json" / "regex" / "header"
+	Path    string // json: 点分路径
+	Pattern string // regex: 取第一个捕获组 (无捕获组取整体匹配)
+	Header  string // header: 头名称
+	re      *regexp.Regexp
+}
+
+// 旅程中的一个步骤
+type ScenarioStep struct {
+	Name      string
+	Method    string
+	URL       string
+	Body      string
+	Headers   map[string]string
+	Extracts  map[string]*ScenarioExtract // 变量名 -> 提取规则
+	ThinkTime time.Duration
+
+	requests int64
+	failed   int64
+	mu       sync.Mutex
+	dur      []time.Duration
+}
+
+// 配置的场景步骤，非空启用场景模式
+var ScenarioSteps []*ScenarioStep
+
+// 单步时长样本上限 (资源档位预设会按机器规格缩放)
+var ScenarioSampleCap = 50000
+
+// 校验场景配置并预编译正则
+func validateScenario() error {
+	for i, step := range ScenarioSteps {
+		if step.URL == "" {
+			return fmt.Errorf("场景步骤 %d (%s) 缺少url", i, step.Name)
+		}
+		if step.Method == "" {
+			step.Method = "GET"
+		}
+		for name, ex := range step.Extracts {
+			switch ex.From {
+			case "json":
+				if ex.Path == "" {
+					return fmt.Errorf("场景步骤 %d (%s) 的提取 %s 缺少path", i, step.Name, name)
+				}
+			case "regex":
+				re, err := regexp.Compile(ex.Pattern)
+				if err != nil {
+					return fmt.Errorf("场景步骤 %d (%s) 的提取 %s 正则无效: %v", i, step.Name, name, err)
+				}
+				ex.re = re
+			case "header":
+				if ex.Header == "" {
+					return fmt.Errorf("场景步骤 %d (%s) 的提取 %s 缺少header", i, step.Name, name)
+				}
+			default:
+				return fmt.Errorf("场景步骤 %d (%s) 的提取 %s 来源无效 (支持 json/regex/header)", i, step.Name, name)
+			}
+		}
+	}
+	return nil
+}
+
+// 场景执行入口: 每个虚拟用户独立循环整个旅程
+func runScenarioLoad(ctx context.Context, stats *Stats, httpClient *http.Client, wg *sync.WaitGroup) {
+	fmt.Printf("场景模式: %d 个步骤 x %d 虚拟用户\n", len(ScenarioSteps), NumConcurrentWorkers)
+	var journeys int64
+	journeyQuota := int64(TotalDownloads / len(ScenarioSteps))
+	if journeyQuota < 1 {
+		journeyQuota = 1
+	}
+
+	for i := 0; i < NumConcurrentWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+				}
+				if TestDuration <= 0 && atomic.AddInt64(&journeys, 1) > journeyQuota {
+					return
+				}
+				if !testDeadline.IsZero() && time.Now().After(testDeadline) {
+					return
+				}
+				waitWhilePaused(ctx)
+				runScenarioJourney(ctx, stats, httpClient)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// 执行一次完整旅程；任一步失败即中断 (后续步骤依赖其提取值)
+func runScenarioJourney(ctx context.Context, stats *Stats, httpClient *http.Client) {
+	vars := make(map[string]string)
+	for _, step := range ScenarioSteps {
+		if !runScenarioStep(ctx, step, vars, stats, httpClient) {
+			return
+		}
+		if step.ThinkTime > 0 {
+			select {
+			case <-time.After(step.ThinkTime):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// 执行单个步骤: 注入变量、发请求、提取变量、记录统计
+func runScenarioStep(ctx context.Context, step *ScenarioStep, vars map[string]string, stats *Stats, httpClient *http.Client) bool {
+	atomic.AddInt64(&step.requests, 1)
+	atomic.AddInt64(&stats.TotalRequests, 1)
+
+	url := expandScenarioVars(step.URL, vars)
+	var bodyReader io.Reader
+	if step.Body != "" {
+		bodyReader = strings.NewReader(expandScenarioVars(step.Body, vars))
+	}
+	req, err := http.NewRequestWithContext(ctx, step.Method, url, bodyReader)
+	if err != nil {
+		scenarioStepFailed(step, stats, "请求构建失败: "+err.Error())
+		return false
+	}
+	for name, value := range step.Headers {
+		req.Header.Set(name, expandScenarioVars(value, vars))
+	}
+
+	start := time.Now()
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		errType := "场景请求失败"
+		if strings.Contains(err.Error(), "timeout") {
+			atomic.AddInt64(&stats.TimeoutCount, 1)
+			errType = "场景请求超时"
+		}
+		scenarioStepFailed(step, stats, errType)
+		return false
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	resp.Body.Close()
+	duration := time.Since(start)
+
+	if resp.StatusCode >= 400 {
+		scenarioStepFailed(step, stats, fmt.Sprintf("HTTP_%d", resp.StatusCode))
+		return false
+	}
+
+	// 步骤成功: 记入全局统计和本步骤的时长样本
+	atomic.AddInt64(&stats.TotalResponses, 1)
+	recordResponseTime(stats, duration)
+	recordLatencySketch(ProtocolHTTP, duration)
+	recordTargetSketch(url, duration)
+	step.mu.Lock()
+	if len(step.dur) < ScenarioSampleCap {
+		step.dur = append(step.dur, duration)
+	}
+	step.mu.Unlock()
+
+	// 提取变量供后续步骤使用，提取不到视为本步失败
+	for name, ex := range step.Extracts {
+		value := extractScenarioValue(ex, resp, body)
+		if value == "" {
+			scenarioStepFailed(step, stats, "变量提取失败: "+name)
+			return false
+		}
+		vars[name] = value
+	}
+	return true
+}
+
+func scenarioStepFailed(step *ScenarioStep, stats *Stats, errType string) {
+	atomic.AddInt64(&step.failed, 1)
+	atomic.AddInt64(&stats.FailedRequests, 1)
+	recordError(stats, errType)
+}
+
+// 按规则从响应中提取值
+func extractScenarioValue(ex *ScenarioExtract, resp *http.Response, body []byte) string {
+	switch ex.From {
+	case "json":
+		return jsonPathString(body, ex.Path)
+	case "regex":
+		m := ex.re.FindSubmatch(body)
+		if m == nil {
+			return ""
+		}
+		if len(m) > 1 {
+			return string(m[1])
+		}
+		return string(m[0])
+	case "header":
+		return resp.Header.Get(ex.Header)
+	}
+	return ""
+}
+
+// 替换 ${变量} 占位符
+func expandScenarioVars(s string, vars map[string]string) string {
+	if !strings.Contains(s, "${") {
+		return s
+	}
+	for name, value := range vars {
+		s = strings.ReplaceAll(s, "${"+name+"}", value)
+	}
+	return s
+}
+
+// 打印按步骤聚合的统计
+func printScenarioReport() {
+	if len(ScenarioSteps) == 0 {
+		return
+	}
+
+	fmt.Printf("\n=== 场景步骤统计 ===\n")
+	for i, step := range ScenarioSteps {
+		requests := atomic.LoadInt64(&step.requests)
+		failed := atomic.LoadInt64(&step.failed)
+		fmt.Printf("%d. [%s] %s %s: %d 次 | 失败 %.1f%%", i+1, step.Name, step.Method, step.URL, requests,
+			float64(failed)/float64(max64(requests, 1))*100)
+		step.mu.Lock()
+		if len(step.dur) > 0 {
+			_, p50, p95, p99 := durationPercentiles(step.dur)
+			fmt.Printf(" | P50/P95/P99: %.1f/%.1f/%.1fms", p50, p95, p99)
+		}
+		step.mu.Unlock()
+		fmt.Printf("\n")
+	}
+}
+
+// 供JSON报告使用的按步骤统计
+func scenarioAnalysis() []map[string]interface{} {
+	if len(ScenarioSteps) == 0 {
+		return nil
+	}
+	var rows []map[string]interface{}
+	for _, step := range ScenarioSteps {
+		row := map[string]interface{}{
+			"name":     step.Name,
+			"method":   step.Method,
+			"url":      step.URL,
+			"requests": atomic.LoadInt64(&step.requests),
+			"failed":   atomic.LoadInt64(&step.failed),
+		}
+		step.mu.Lock()
+		if len(step.dur) > 0 {
+			_, p50, p95, p99 := durationPercentiles(step.dur)
+			row["p50_ms"], row["p95_ms"], row["p99_ms"] = p50, p95, p99
+		}
+		step.mu.Unlock()
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// ===================================================================================
+// --- 脚本钩子 (Scripting Hook) ---
+// 每次迭代调用用户脚本构建URL/方法/头/体，并回调检查响应，
+// 复杂行为不用改Go代码。JS引擎(goja)是较重的依赖，默认构建不包含:
+// 用 go build -tags scripting 编译才启用，本文件只含与引擎无关的接驳层
+// ===================================================================================
+
+// 脚本文件路径，非空启用脚本钩子 (需要scripting构建标签)
+var ScriptFile string
+
+// 脚本返回的请求描述
+type scriptRequest struct {
+	Method  string
+	URL     string
+	Body    string
+	Headers map[string]string
+}
+
+// 由具体引擎实现 (script_goja.go) 填充的钩子；nil表示未启用
+var scriptBuildRequest func(iter int64) (*scriptRequest, error)
+var scriptOnResponse func(url string, status int, durationMs float64)
+
+var scriptIter int64
+var scriptBuilt int64
+var scriptErrors int64
+
+// 初始化脚本引擎并挂接响应观察者
+func initScripting() error {
+	if ScriptFile == "" {
+		return nil
+	}
+	if err := loadScriptEngine(); err != nil {
+		return err
+	}
+	if scriptOnResponse != nil {
+		RegisterResponseObserver(func(resp *http.Response, duration time.Duration) {
+			scriptOnResponse(resp.Request.URL.String(), resp.StatusCode, float64(duration.Microseconds())/1000)
+		})
+	}
+	fmt.Printf("脚本钩子已启用: %s\n", ScriptFile)
+	return nil
+}
+
+// 调用脚本构建一次请求；脚本出错时返回 ok=false 回落到默认生成逻辑
+func scriptNextRequest() (url string, payload []byte, headers map[string]string, method string, ok bool) {
+	req, err := scriptBuildRequest(atomic.AddInt64(&scriptIter, 1))
+	if err != nil || req == nil || req.URL == "" {
+		atomic.AddInt64(&scriptErrors, 1)
+		return "", nil, nil, "", false
+	}
+	atomic.AddInt64(&scriptBuilt, 1)
+	method = req.Method
+	if method == "" {
+		method = "GET"
+	}
+	headers = req.Headers
+	if headers == nil {
+		headers = make(map[string]string)
+	}
+	return req.URL, []byte(req.Body), headers, method, true
+}
+
+// 打印脚本钩子统计
+func printScriptReport() {
+	if ScriptFile == "" || scriptBuildRequest == nil {
+		return
+	}
+
+	fmt.Printf("\n=== 脚本钩子统计 ===\n")
+	fmt.Printf("脚本构建请求: %d 次 | 脚本出错回落: %d 次\n",
+		atomic.LoadInt64(&scriptBuilt), atomic.LoadInt64(&scriptErrors))
+}
@@ -0,0 +1,79 @@
+//go:build scripting
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/dop251/goja"
+)
+
+// goja虚拟机不支持并发调用，所有钩子串行化在同一把锁内执行；
+// 脚本逻辑重时会成为吞吐瓶颈，这是脚本模式的已知取舍
+var scriptVMMu sync.Mutex
+var scriptVM *goja.Runtime
+
+// 加载脚本并绑定钩子。脚本需定义:
+//
+//	function buildRequest(iter) { return {method, url, headers, body}; }
+//	function onResponse(url, status, durationMs) {}  // 可选
+func loadScriptEngine() error {
+	src, err := os.ReadFile(ScriptFile)
+	if err != nil {
+		return fmt.Errorf("无法读取脚本文件 %s: %v", ScriptFile, err)
+	}
+
+	vm := goja.New()
+	if _, err := vm.RunString(string(src)); err != nil {
+		return fmt.Errorf("脚本执行失败: %v", err)
+	}
+
+	buildFn, ok := goja.AssertFunction(vm.Get("buildRequest"))
+	if !ok {
+		return fmt.Errorf("脚本 %s 未定义 buildRequest(iter) 函数", ScriptFile)
+	}
+	scriptVM = vm
+
+	scriptBuildRequest = func(iter int64) (*scriptRequest, error) {
+		scriptVMMu.Lock()
+		defer scriptVMMu.Unlock()
+		result, err := buildFn(goja.Undefined(), vm.ToValue(iter))
+		if err != nil {
+			return nil, err
+		}
+		obj, ok := result.Export().(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("buildRequest 需返回对象")
+		}
+		req := &scriptRequest{}
+		if v, ok := obj["method"].(string); ok {
+			req.Method = v
+		}
+		if v, ok := obj["url"].(string); ok {
+			req.URL = v
+		}
+		if v, ok := obj["body"].(string); ok {
+			req.Body = v
+		}
+		if hs, ok := obj["headers"].(map[string]interface{}); ok {
+			req.Headers = make(map[string]string, len(hs))
+			for name, value := range hs {
+				if s, ok := value.(string); ok {
+					req.Headers[name] = s
+				}
+			}
+		}
+		return req, nil
+	}
+
+	if respFn, ok := goja.AssertFunction(vm.Get("onResponse")); ok {
+		scriptOnResponse = func(url string, status int, durationMs float64) {
+			scriptVMMu.Lock()
+			defer scriptVMMu.Unlock()
+			respFn(goja.Undefined(), vm.ToValue(url), vm.ToValue(status), vm.ToValue(durationMs))
+		}
+	}
+	return nil
+}
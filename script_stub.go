@@ -0,0 +1,10 @@
+//go:build !scripting
+
+package main
+
+import "fmt"
+
+// 默认构建不包含JS引擎，配置了脚本时给出明确指引
+func loadScriptEngine() error {
+	return fmt.Errorf("当前二进制未编译脚本支持，请使用 go build -tags scripting 重新构建")
+}
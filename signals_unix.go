@@ -0,0 +1,27 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// SIGUSR1/SIGUSR2是Unix专有信号，订阅逻辑按平台拆分；
+// Windows上这两个控制通道不可用，对应函数为空实现
+
+// 订阅暂停/恢复切换信号 (SIGUSR2)
+func notifyPauseSignal(ch chan os.Signal) {
+	signal.Notify(ch, syscall.SIGUSR2)
+}
+
+// 订阅统计快照信号 (SIGUSR1)
+func notifyStatsSignal(ch chan os.Signal) {
+	signal.Notify(ch, syscall.SIGUSR1)
+}
+
+// 向子进程发统计快照信号 (守护进程模式的实时统计接口使用)
+func signalStatsDump(p *os.Process) {
+	p.Signal(syscall.SIGUSR1)
+}
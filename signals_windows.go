@@ -0,0 +1,13 @@
+//go:build windows
+
+package main
+
+import "os"
+
+// Windows没有SIGUSR1/SIGUSR2，暂停切换与快照信号通道不可用
+
+func notifyPauseSignal(ch chan os.Signal) {}
+
+func notifyStatsSignal(ch chan os.Signal) {}
+
+func signalStatsDump(p *os.Process) {}
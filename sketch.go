@@ -0,0 +1,282 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ===================================================================================
+// --- 可合并延迟草图 (Mergeable Latency Sketches) ---
+// 各报告板块各自维护延迟切片会导致同一次运行算出不一致的百分位；
+// 这里用对数分桶直方图作为统一数据管道: 协程分片 → 协议分组 → 全局，
+// 桶计数可合并也可相减 (阶段差值)，全局/分协议/分目标/分阶段共用同一份数据
+// ===================================================================================
+
+const (
+	// 相邻桶上界比值，决定百分位的相对误差 (~3.5%)
+	sketchGamma = 1.07
+	// 最小可分辨延迟 (ms)，低于此值落入第0桶
+	sketchMinMs = 0.05
+	// 1.07^280 * 0.05ms ≈ 8分钟，覆盖所有超时场景
+	sketchBuckets = 280
+	// 每协议的写入分片数，分散原子递增的缓存行竞争
+	sketchShardCount = 8
+)
+
+// 分目标草图的上限，防止URL基数失控 (资源档位预设会按机器规格缩放)
+var TargetSketchLimit = 100
+
+// 对数分桶延迟直方图，全部字段原子操作，天然支持并发写入与合并
+type latencySketch struct {
+	counts [sketchBuckets]int64
+	total  int64
+	sumNs  int64
+}
+
+// 延迟值到桶下标
+func sketchBucketFor(ms float64) int {
+	if ms <= sketchMinMs {
+		return 0
+	}
+	idx := int(math.Log(ms/sketchMinMs)/math.Log(sketchGamma)) + 1
+	if idx >= sketchBuckets {
+		idx = sketchBuckets - 1
+	}
+	return idx
+}
+
+// 桶下标到代表延迟 (几何中点)
+func sketchBucketMidMs(idx int) float64 {
+	if idx == 0 {
+		return sketchMinMs / 2
+	}
+	return sketchMinMs * math.Pow(sketchGamma, float64(idx)-0.5)
+}
+
+func (s *latencySketch) add(d time.Duration) {
+	idx := sketchBucketFor(float64(d.Nanoseconds()) / 1e6)
+	atomic.AddInt64(&s.counts[idx], 1)
+	atomic.AddInt64(&s.total, 1)
+	atomic.AddInt64(&s.sumNs, d.Nanoseconds())
+}
+
+// 把o的计数合并进s (合并管道的基本操作)
+func (s *latencySketch) merge(o *latencySketch) {
+	for i := range o.counts {
+		s.counts[i] += atomic.LoadInt64(&o.counts[i])
+	}
+	s.total += atomic.LoadInt64(&o.total)
+	s.sumNs += atomic.LoadInt64(&o.sumNs)
+}
+
+// 桶计数相减，得到两个时刻之间新增样本的草图 (阶段统计用)
+func (s *latencySketch) diff(prev *latencySketch) *latencySketch {
+	out := &latencySketch{}
+	for i := range s.counts {
+		out.counts[i] = s.counts[i] - prev.counts[i]
+	}
+	out.total = s.total - prev.total
+	out.sumNs = s.sumNs - prev.sumNs
+	return out
+}
+
+func (s *latencySketch) count() int64 {
+	return atomic.LoadInt64(&s.total)
+}
+
+func (s *latencySketch) avgMs() float64 {
+	total := atomic.LoadInt64(&s.total)
+	if total == 0 {
+		return 0
+	}
+	return float64(atomic.LoadInt64(&s.sumNs)) / float64(total) / 1e6
+}
+
+// 分位数估计: 返回累计计数跨过q*total的桶的代表延迟
+func (s *latencySketch) quantileMs(q float64) float64 {
+	total := atomic.LoadInt64(&s.total)
+	if total == 0 {
+		return 0
+	}
+	rank := int64(math.Ceil(q * float64(total)))
+	var cum int64
+	for i := range s.counts {
+		cum += atomic.LoadInt64(&s.counts[i])
+		if cum >= rank {
+			return sketchBucketMidMs(i)
+		}
+	}
+	return sketchBucketMidMs(sketchBuckets - 1)
+}
+
+// === 合并管道: 协程分片 → 协议分组 → 全局 ===
+
+var (
+	// [协议][分片]，写入时轮转分片以分散竞争
+	sketchShards      [4][sketchShardCount]latencySketch
+	sketchShardCursor int64
+
+	// 分目标 (主机) 草图
+	targetSketchMu sync.RWMutex
+	targetSketches = make(map[string]*latencySketch)
+)
+
+// 记录一次请求延迟到对应协议的某个分片
+func recordLatencySketch(proto ProtocolType, d time.Duration) {
+	shard := atomic.AddInt64(&sketchShardCursor, 1) % sketchShardCount
+	sketchShards[proto][shard].add(d)
+}
+
+// 记录一次请求延迟到其目标主机的草图
+func recordTargetSketch(rawURL string, d time.Duration) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return
+	}
+
+	targetSketchMu.RLock()
+	sketch, ok := targetSketches[u.Host]
+	targetSketchMu.RUnlock()
+
+	if !ok {
+		targetSketchMu.Lock()
+		sketch, ok = targetSketches[u.Host]
+		if !ok {
+			if len(targetSketches) >= TargetSketchLimit {
+				targetSketchMu.Unlock()
+				return
+			}
+			sketch = &latencySketch{}
+			targetSketches[u.Host] = sketch
+		}
+		targetSketchMu.Unlock()
+	}
+	sketch.add(d)
+}
+
+// 协议分组: 合并该协议的全部协程分片
+func mergedProtocolSketch(proto ProtocolType) *latencySketch {
+	merged := &latencySketch{}
+	for i := range sketchShards[proto] {
+		merged.merge(&sketchShards[proto][i])
+	}
+	return merged
+}
+
+// 全局: 合并所有协议分组
+func mergedGlobalSketch() *latencySketch {
+	merged := &latencySketch{}
+	for proto := range sketchShards {
+		merged.merge(mergedProtocolSketch(ProtocolType(proto)))
+	}
+	return merged
+}
+
+func protocolSketchName(proto ProtocolType) string {
+	switch proto {
+	case ProtocolHTTP:
+		return "HTTP"
+	case ProtocolGRPC:
+		return "gRPC"
+	case ProtocolWebSocket:
+		return "WebSocket"
+	case ProtocolHTTP3:
+		return "HTTP3"
+	}
+	return "未知"
+}
+
+// 单个草图的统计行
+func sketchStatsLine(s *latencySketch) string {
+	return fmt.Sprintf("%d 次 | 平均: %.1fms | P50/P95/P99/P99.9: %.1f/%.1f/%.1f/%.1fms",
+		s.count(), s.avgMs(), s.quantileMs(0.50), s.quantileMs(0.95), s.quantileMs(0.99), s.quantileMs(0.999))
+}
+
+// 打印统一合并管道的延迟报告: 全局、分协议、分目标均出自同一份桶计数
+func printSketchReport() {
+	global := mergedGlobalSketch()
+	if global.count() == 0 {
+		return
+	}
+
+	fmt.Printf("\n=== 延迟草图 (统一合并管道) ===\n")
+	fmt.Printf("全局: %s\n", sketchStatsLine(global))
+
+	for proto := 0; proto < 4; proto++ {
+		merged := mergedProtocolSketch(ProtocolType(proto))
+		if merged.count() == 0 {
+			continue
+		}
+		fmt.Printf("%s: %s\n", protocolSketchName(ProtocolType(proto)), sketchStatsLine(merged))
+	}
+
+	targetSketchMu.RLock()
+	hosts := make([]string, 0, len(targetSketches))
+	for host := range targetSketches {
+		hosts = append(hosts, host)
+	}
+	targetSketchMu.RUnlock()
+
+	if len(hosts) > 1 {
+		sort.Slice(hosts, func(i, j int) bool {
+			return targetSketches[hosts[i]].count() > targetSketches[hosts[j]].count()
+		})
+		if len(hosts) > 10 {
+			hosts = hosts[:10]
+		}
+		fmt.Printf("--- 分目标 (Top %d) ---\n", len(hosts))
+		for _, host := range hosts {
+			fmt.Printf("%s: %s\n", host, sketchStatsLine(targetSketches[host]))
+		}
+	}
+}
+
+// 草图统计的JSON片段
+func sketchJSON(s *latencySketch) map[string]interface{} {
+	return map[string]interface{}{
+		"count":    s.count(),
+		"avg_ms":   s.avgMs(),
+		"p50_ms":   s.quantileMs(0.50),
+		"p95_ms":   s.quantileMs(0.95),
+		"p99_ms":   s.quantileMs(0.99),
+		"p999_ms":  s.quantileMs(0.999),
+	}
+}
+
+// 统一管道的JSON报告片段: global / by_protocol / by_target
+func sketchAnalysis() map[string]interface{} {
+	global := mergedGlobalSketch()
+	if global.count() == 0 {
+		return nil
+	}
+
+	byProtocol := make(map[string]interface{})
+	for proto := 0; proto < 4; proto++ {
+		merged := mergedProtocolSketch(ProtocolType(proto))
+		if merged.count() == 0 {
+			continue
+		}
+		byProtocol[protocolSketchName(ProtocolType(proto))] = sketchJSON(merged)
+	}
+
+	byTarget := make(map[string]interface{})
+	targetSketchMu.RLock()
+	for host, sketch := range targetSketches {
+		byTarget[host] = sketchJSON(sketch)
+	}
+	targetSketchMu.RUnlock()
+
+	analysis := map[string]interface{}{
+		"global":      sketchJSON(global),
+		"by_protocol": byProtocol,
+	}
+	if len(byTarget) > 0 {
+		analysis["by_target"] = byTarget
+	}
+	return analysis
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===================================================================================
+// --- SLA阈值门禁 (SLA Thresholds) ---
+// 运行结束后按配置的阈值表达式检查结果 (如 "p95 < 500ms"、"error_rate < 1%")，
+// 任一条被突破时进程以非零码退出，供CI等自动化性能门禁使用
+// ===================================================================================
+
+// 阈值表达式列表，来自配置文件 sla_thresholds
+var SLAThresholds []string
+
+// SLA被突破时的退出码
+const slaExitCode = 2
+
+// 单条解析后的阈值规则
+type slaRule struct {
+	raw    string
+	metric string
+	op     string
+	value  float64
+}
+
+// 解析 "metric op value" 形式的表达式，值支持 ms/% 后缀
+func parseSLARule(expr string) (*slaRule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("SLA表达式 %q 格式错误，应为: 指标 运算符 阈值", expr)
+	}
+
+	metric := strings.ToLower(fields[0])
+	switch metric {
+	case "p50", "p95", "p99", "avg", "error_rate", "success_rate", "qps", "score":
+	default:
+		return nil, fmt.Errorf("SLA表达式 %q 中的指标 %q 不支持", expr, fields[0])
+	}
+
+	op := fields[1]
+	switch op {
+	case "<", "<=", ">", ">=":
+	default:
+		return nil, fmt.Errorf("SLA表达式 %q 中的运算符 %q 不支持", expr, op)
+	}
+
+	valueStr := strings.TrimSuffix(strings.TrimSuffix(fields[2], "ms"), "%")
+	value, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil {
+		return nil, fmt.Errorf("SLA表达式 %q 中的阈值 %q 无法解析: %v", expr, fields[2], err)
+	}
+
+	return &slaRule{raw: expr, metric: metric, op: op, value: value}, nil
+}
+
+// 从最终结果中取出指标实际值
+func (r *slaRule) actual(stats *StatsSnapshot, totalDuration time.Duration, scoreCard *ScoreCard) (float64, bool) {
+	switch r.metric {
+	case "qps":
+		if totalDuration.Seconds() <= 0 {
+			return 0, false
+		}
+		return float64(stats.TotalRequests) / totalDuration.Seconds(), true
+	case "error_rate":
+		if stats.TotalRequests == 0 {
+			return 0, false
+		}
+		return float64(stats.FailedRequests) / float64(stats.TotalRequests) * 100, true
+	case "success_rate":
+		if stats.TotalRequests == 0 {
+			return 0, false
+		}
+		return float64(stats.TotalResponses) / float64(stats.TotalRequests) * 100, true
+	case "score":
+		if scoreCard == nil {
+			return 0, false
+		}
+		return scoreCard.TotalScore, true
+	}
+
+	// 延迟类指标
+	if len(stats.ResponseTimes) == 0 {
+		return 0, false
+	}
+	times := make([]time.Duration, len(stats.ResponseTimes))
+	copy(times, stats.ResponseTimes)
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	toMs := func(d time.Duration) float64 { return float64(d.Nanoseconds()) / 1e6 }
+
+	switch r.metric {
+	case "p50":
+		return toMs(times[len(times)/2]), true
+	case "p95":
+		return toMs(times[int(float64(len(times))*0.95)]), true
+	case "p99":
+		return toMs(times[int(float64(len(times))*0.99)]), true
+	case "avg":
+		var total time.Duration
+		for _, t := range times {
+			total += t
+		}
+		return toMs(total / time.Duration(len(times))), true
+	}
+	return 0, false
+}
+
+func (r *slaRule) holds(actual float64) bool {
+	switch r.op {
+	case "<":
+		return actual < r.value
+	case "<=":
+		return actual <= r.value
+	case ">":
+		return actual > r.value
+	case ">=":
+		return actual >= r.value
+	}
+	return false
+}
+
+// 评估全部SLA阈值并打印结果，返回被突破的条数
+func evaluateSLA(stats *StatsSnapshot, totalDuration time.Duration, scoreCard *ScoreCard) int {
+	if len(SLAThresholds) == 0 {
+		return 0
+	}
+
+	fmt.Printf("\n=== SLA阈值检查 ===\n")
+	breached := 0
+	for _, expr := range SLAThresholds {
+		rule, err := parseSLARule(expr)
+		if err != nil {
+			// 解析失败按突破处理，避免写错的门禁静默放行
+			fmt.Printf("✗ %s (%v)\n", expr, err)
+			breached++
+			continue
+		}
+
+		actual, ok := rule.actual(stats, totalDuration, scoreCard)
+		if !ok {
+			fmt.Printf("✗ %s (无数据可评估)\n", expr)
+			breached++
+			continue
+		}
+
+		if rule.holds(actual) {
+			fmt.Printf("✓ %s (实际: %.2f)\n", expr, actual)
+		} else {
+			fmt.Printf("✗ %s (实际: %.2f)\n", expr, actual)
+			breached++
+		}
+	}
+
+	if breached > 0 {
+		fmt.Printf("SLA检查未通过: %d/%d 条被突破，退出码 %d\n", breached, len(SLAThresholds), slaExitCode)
+	} else {
+		fmt.Printf("SLA检查全部通过 (%d 条)\n", len(SLAThresholds))
+	}
+	return breached
+}
@@ -0,0 +1,25 @@
+package main
+
+import "time"
+
+// ===================================================================================
+// --- TCP套接字选项 (TCP Socket Options) ---
+// 通过拨号器的Control钩子按连接设置TCP_NODELAY、收发缓冲区和TCP_USER_TIMEOUT，
+// 这些选项会实质性改变压测机自身的吞吐/延迟特征。
+// setsockopt实现按平台拆分: sockopts_linux.go / sockopts_other.go
+// ===================================================================================
+
+var (
+	// Nagle算法开关 (Go默认启用TCP_NODELAY，设为false恢复Nagle合并小包)
+	TCPNoDelay = true
+	// 发送/接收缓冲区字节数，0沿用内核默认
+	TCPSendBuffer = 0
+	TCPRecvBuffer = 0
+	// TCP_USER_TIMEOUT: 数据未被确认的最长时间，0沿用内核默认
+	TCPUserTimeout time.Duration = 0
+)
+
+// 是否有任何套接字定制项
+func socketOptionsActive() bool {
+	return !TCPNoDelay || TCPSendBuffer > 0 || TCPRecvBuffer > 0 || TCPUserTimeout > 0
+}
@@ -0,0 +1,71 @@
+//go:build linux
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// 带SO_REUSEPORT的TCP监听: 同一地址可开多个监听器，
+// 内核把新连接分摊到各自独立的accept循环
+func reuseportListen(addr string) (net.Listener, error) {
+	lc := net.ListenConfig{
+		Control: func(network, address string, c syscall.RawConn) error {
+			var optErr error
+			if err := c.Control(func(fd uintptr) {
+				optErr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1)
+			}); err != nil {
+				return err
+			}
+			return optErr
+		},
+	}
+	return lc.Listen(context.Background(), "tcp", addr)
+}
+
+// 返回net.Dialer的Control钩子，无定制项时返回nil
+func dialerControl() func(network, address string, c syscall.RawConn) error {
+	if !socketOptionsActive() {
+		return nil
+	}
+	return func(network, address string, c syscall.RawConn) error {
+		var optErr error
+		err := c.Control(func(fd uintptr) {
+			s := int(fd)
+			if !TCPNoDelay {
+				if err := unix.SetsockoptInt(s, unix.IPPROTO_TCP, unix.TCP_NODELAY, 0); err != nil {
+					optErr = fmt.Errorf("关闭TCP_NODELAY失败: %v", err)
+					return
+				}
+			}
+			if TCPSendBuffer > 0 {
+				if err := unix.SetsockoptInt(s, unix.SOL_SOCKET, unix.SO_SNDBUF, TCPSendBuffer); err != nil {
+					optErr = fmt.Errorf("设置SO_SNDBUF失败: %v", err)
+					return
+				}
+			}
+			if TCPRecvBuffer > 0 {
+				if err := unix.SetsockoptInt(s, unix.SOL_SOCKET, unix.SO_RCVBUF, TCPRecvBuffer); err != nil {
+					optErr = fmt.Errorf("设置SO_RCVBUF失败: %v", err)
+					return
+				}
+			}
+			if TCPUserTimeout > 0 {
+				ms := int(TCPUserTimeout.Milliseconds())
+				if err := unix.SetsockoptInt(s, unix.IPPROTO_TCP, unix.TCP_USER_TIMEOUT, ms); err != nil {
+					optErr = fmt.Errorf("设置TCP_USER_TIMEOUT失败: %v", err)
+					return
+				}
+			}
+		})
+		if err != nil {
+			return err
+		}
+		return optErr
+	}
+}
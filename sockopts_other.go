@@ -0,0 +1,22 @@
+//go:build !linux
+
+package main
+
+import (
+	"log"
+	"net"
+	"syscall"
+)
+
+// 其他平台没有SO_REUSEPORT分流，退化为普通监听
+func reuseportListen(addr string) (net.Listener, error) {
+	return net.Listen("tcp", addr)
+}
+
+// 套接字定制项依赖Linux的setsockopt常量，其他平台忽略配置并提示
+func dialerControl() func(network, address string, c syscall.RawConn) error {
+	if socketOptionsActive() {
+		log.Printf("警告: 本平台不支持TCP套接字选项定制，已忽略")
+	}
+	return nil
+}
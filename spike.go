@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ===================================================================================
+// --- 尖峰测试模式 (Spike Test Mode) ---
+// 在基线并发与突发倍数并发之间按计划交替，重点不是尖峰期间的表现，
+// 而是回落后P95和错误率恢复到基线水平需要多久 (恢复时间)，
+// 它暴露的是服务端队列排空、连接池回收等弹性问题
+// ===================================================================================
+
+var (
+	// 尖峰倍数，>=2 启用尖峰模式 (尖峰并发 = 基线并发 × 倍数)
+	SpikeMultiplier = 0
+	// 两次尖峰之间的基线段时长
+	SpikeInterval = 60 * time.Second
+	// 单次尖峰持续时长
+	SpikeDuration = 10 * time.Second
+)
+
+// 恢复测量的放弃阈值
+const spikeRecoveryTimeout = 2 * time.Minute
+
+// 单次尖峰的测量结果
+type SpikeResult struct {
+	Index          int     `json:"index"`
+	StartOffsetS   float64 `json:"start_offset_s"`
+	PeakWorkers    int     `json:"peak_workers"`
+	BaselineP95Ms  float64 `json:"baseline_p95_ms"`
+	PeakP95Ms      float64 `json:"peak_p95_ms"`
+	BaselineErrPct float64 `json:"baseline_error_rate"`
+	PeakErrPct     float64 `json:"peak_error_rate"`
+	RecoveryS      float64 `json:"recovery_s"`
+	Recovered      bool    `json:"recovered"`
+}
+
+var spikeResults []SpikeResult
+
+// 尖峰模式执行入口: 基线段 → 尖峰段 → 恢复测量，循环直到截止时间
+func runSpikeLoad(ctx context.Context, cache *RequestCache, stats *Stats, httpClient, http3Client *http.Client, wg *sync.WaitGroup) {
+	pool := &workerPool{
+		ctx:         ctx,
+		cache:       cache,
+		stats:       stats,
+		httpClient:  httpClient,
+		http3Client: http3Client,
+		wg:          wg,
+	}
+
+	baseline := NumConcurrentWorkers
+	peak := baseline * SpikeMultiplier
+	log.Printf("尖峰模式: 基线 %d 协程，每 %v 突增至 %d 协程持续 %v", baseline, SpikeInterval, peak, SpikeDuration)
+
+	pool.scaleTo(baseline)
+	runStart := time.Now()
+
+	for i := 1; ; i++ {
+		// 基线段: 让系统稳定下来，段尾取基线指标
+		if !spikeWait(ctx, SpikeInterval) {
+			break
+		}
+		_, baseP95, _, _ := rollingLatency.Percentiles(10)
+		baseErr := spikeErrorRate(stats, time.Second)
+
+		result := SpikeResult{
+			Index:          i,
+			StartOffsetS:   time.Since(runStart).Seconds(),
+			PeakWorkers:    peak,
+			BaselineP95Ms:  baseP95,
+			BaselineErrPct: baseErr,
+		}
+
+		// 尖峰段: 突增并发，逐秒记录最差的P95和错误率
+		log.Printf("尖峰 #%d: %d -> %d 协程", i, baseline, peak)
+		pool.scaleTo(peak)
+		spikeEnd := time.Now().Add(SpikeDuration)
+		for time.Now().Before(spikeEnd) {
+			if !spikeWait(ctx, time.Second) {
+				pool.scaleTo(0)
+				return
+			}
+			_, p95, _, _ := rollingLatency.Percentiles(3)
+			if p95 > result.PeakP95Ms {
+				result.PeakP95Ms = p95
+			}
+			if errRate := spikeErrorRate(stats, 0); errRate > result.PeakErrPct {
+				result.PeakErrPct = errRate
+			}
+		}
+
+		// 回落并测量恢复时间: P95和错误率都回到基线附近才算恢复
+		pool.scaleTo(baseline)
+		recoveryStart := time.Now()
+		for {
+			if !spikeWait(ctx, time.Second) {
+				pool.scaleTo(0)
+				return
+			}
+			_, p95, _, _ := rollingLatency.Percentiles(3)
+			errRate := spikeErrorRate(stats, 0)
+			if p95 <= baseP95*1.2+1 && errRate <= baseErr+1.0 {
+				result.RecoveryS = time.Since(recoveryStart).Seconds()
+				result.Recovered = true
+				break
+			}
+			if time.Since(recoveryStart) > spikeRecoveryTimeout {
+				result.RecoveryS = spikeRecoveryTimeout.Seconds()
+				break
+			}
+		}
+		if result.Recovered {
+			log.Printf("尖峰 #%d 恢复耗时 %.1fs", i, result.RecoveryS)
+		} else {
+			log.Printf("尖峰 #%d 在 %v 内未恢复到基线", i, spikeRecoveryTimeout)
+		}
+		spikeResults = append(spikeResults, result)
+	}
+
+	log.Printf("尖峰模式结束，正在回收 %d 个协程", pool.size())
+	pool.scaleTo(0)
+}
+
+// 等待指定时长，ctx取消或到达截止时间返回false
+func spikeWait(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+	}
+	return !time.Now().After(testDeadline)
+}
+
+// 最近一段时间的错误率: settle>0时先等待让计数器累积一个完整窗口
+var (
+	spikeLastTotal  int64
+	spikeLastFailed int64
+)
+
+func spikeErrorRate(stats *Stats, settle time.Duration) float64 {
+	if settle > 0 {
+		time.Sleep(settle)
+	}
+	total := atomic.LoadInt64(&stats.TotalRequests) + atomic.LoadInt64(&stats.FailedRequests)
+	failed := atomic.LoadInt64(&stats.FailedRequests)
+	dTotal := total - spikeLastTotal
+	dFailed := failed - spikeLastFailed
+	spikeLastTotal, spikeLastFailed = total, failed
+
+	if dTotal <= 0 {
+		return 0
+	}
+	return float64(dFailed) / float64(dTotal) * 100
+}
+
+// 打印各次尖峰的恢复情况
+func printSpikeReport() {
+	if len(spikeResults) == 0 {
+		return
+	}
+
+	fmt.Printf("\n=== 尖峰测试 (×%d) ===\n", SpikeMultiplier)
+	for _, r := range spikeResults {
+		status := fmt.Sprintf("恢复耗时 %.1fs", r.RecoveryS)
+		if !r.Recovered {
+			status = fmt.Sprintf("超过 %.0fs 未恢复", r.RecoveryS)
+		}
+		fmt.Printf("#%d (+%.0fs): P95 %.1f -> %.1fms | 错误率 %.2f%% -> %.2f%% | %s\n",
+			r.Index, r.StartOffsetS, r.BaselineP95Ms, r.PeakP95Ms, r.BaselineErrPct, r.PeakErrPct, status)
+	}
+}
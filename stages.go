@@ -0,0 +1,349 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ===================================================================================
+// --- 阶段化负载曲线 (Staged Load Profiles) ---
+// 按配置的阶段序列随时间增减活跃协程数 (如 2分钟爬升到1万 → 稳定5分钟 → 1分钟回落)，
+// 替代main()里一次性批量启动全部协程的方式
+// ===================================================================================
+
+// 单个负载阶段: 在Duration内把活跃协程数线性过渡到Workers；
+// 可选字段未设置时沿用全局配置，使阶段序列成为完整的声明式测试计划
+type LoadStage struct {
+	Name     string
+	Duration time.Duration
+	Workers  int
+
+	// >0 时本阶段改用开放模型按恒定到达率派发，Workers作为执行池上限
+	Rate int
+	// 本阶段覆盖全局测试模式
+	Mode *TestMode
+	// 本阶段的协议占比覆盖 (百分比)，三者需一起设置
+	HTTP3Pct *int
+	WSPct    *int
+	GRPCPct  *int
+}
+
+// 阶段执行期间对派发逻辑的全局覆盖，dispatchRequest原子读取
+type stageOverrides struct {
+	mode     *TestMode
+	mixSet   bool
+	http3Pct int
+	wsPct    int
+	grpcPct  int
+}
+
+var activeStageOverrides atomic.Value // *stageOverrides
+
+// 进入阶段时装载该阶段的覆盖项
+func applyStageOverrides(stage LoadStage) {
+	o := &stageOverrides{mode: stage.Mode}
+	if stage.HTTP3Pct != nil && stage.WSPct != nil && stage.GRPCPct != nil {
+		o.mixSet = true
+		o.http3Pct, o.wsPct, o.grpcPct = *stage.HTTP3Pct, *stage.WSPct, *stage.GRPCPct
+	}
+	activeStageOverrides.Store(o)
+}
+
+// 阶段序列结束后清除覆盖
+func clearStageOverrides() {
+	activeStageOverrides.Store((*stageOverrides)(nil))
+}
+
+// 当前生效的测试模式 (阶段覆盖优先)
+func activeTestMode() TestMode {
+	if o, _ := activeStageOverrides.Load().(*stageOverrides); o != nil && o.mode != nil {
+		return *o.mode
+	}
+	return SelectedTestMode
+}
+
+// 协议占比覆盖由执行器注册表消费，见executors.go的executorWeight
+
+// 配置的阶段序列，为空则使用传统的固定并发启动方式
+var LoadStages []LoadStage
+
+// 阶段化调度的调整粒度
+const stageAdjustInterval = time.Second
+
+// 可伸缩的工作协程池: 每个协程持有独立的cancel以支持缩容
+type workerPool struct {
+	ctx         context.Context
+	cache       *RequestCache
+	stats       *Stats
+	httpClient  *http.Client
+	http3Client *http.Client
+	wg          *sync.WaitGroup
+
+	cancels []context.CancelFunc
+	nextID  int
+}
+
+// 把活跃协程数调整到target
+func (p *workerPool) scaleTo(target int) {
+	if target < 0 {
+		target = 0
+	}
+
+	// 扩容: 启动新协程
+	for len(p.cancels) < target {
+		workerCtx, cancel := context.WithCancel(p.ctx)
+		p.cancels = append(p.cancels, cancel)
+		p.wg.Add(1)
+		go worker(workerCtx, p.nextID, p.cache, p.stats, p.httpClient, p.http3Client, p.wg)
+		p.nextID++
+	}
+
+	// 缩容: 撤销最后启动的协程
+	for len(p.cancels) > target {
+		last := len(p.cancels) - 1
+		p.cancels[last]()
+		p.cancels = p.cancels[:last]
+	}
+}
+
+// 活跃协程数
+func (p *workerPool) size() int {
+	return len(p.cancels)
+}
+
+// 阶段化负载执行入口: 依次执行各阶段并线性过渡协程数，结束后等待全部协程退出
+func runStagedLoad(ctx context.Context, cache *RequestCache, stats *Stats, httpClient, http3Client *http.Client, wg *sync.WaitGroup) {
+	pool := &workerPool{
+		ctx:         ctx,
+		cache:       cache,
+		stats:       stats,
+		httpClient:  httpClient,
+		http3Client: http3Client,
+		wg:          wg,
+	}
+
+	prevWorkers := 0
+	prevSnap := stats.Snapshot()
+	prevSketch := mergedGlobalSketch()
+	for _, stage := range LoadStages {
+		applyStageOverrides(stage)
+		stageStart := time.Now()
+
+		// 空闲阶段 (无协程也无到达率) 可选发低速率保活探测，维持连接池温热
+		var stopProbes context.CancelFunc
+		if stage.Workers == 0 && stage.Rate == 0 {
+			stopProbes = startIdleProbes(ctx, httpClient)
+		}
+
+		if stage.Rate > 0 {
+			// 到达率阶段: 停掉闭环协程池，按恒定速率派发
+			log.Printf("进入阶段 [%s]: 到达率 %d/s，历时 %v", stage.Name, stage.Rate, stage.Duration)
+			pool.scaleTo(0)
+			prevWorkers = 0
+			if !runStageArrival(ctx, stage, pool) {
+				clearStageOverrides()
+				return
+			}
+		} else {
+			log.Printf("进入阶段 [%s]: %d -> %d 协程，历时 %v", stage.Name, prevWorkers, stage.Workers, stage.Duration)
+
+			ticker := time.NewTicker(stageAdjustInterval)
+			for {
+				elapsed := time.Since(stageStart)
+				if elapsed >= stage.Duration {
+					break
+				}
+				// 线性插值当前应有的协程数
+				progress := float64(elapsed) / float64(stage.Duration)
+				desired := prevWorkers + int(float64(stage.Workers-prevWorkers)*progress)
+				pool.scaleTo(desired)
+
+				select {
+				case <-ticker.C:
+				case <-ctx.Done():
+					ticker.Stop()
+					pool.scaleTo(0)
+					clearStageOverrides()
+					return
+				}
+			}
+			ticker.Stop()
+
+			pool.scaleTo(stage.Workers)
+			prevWorkers = stage.Workers
+		}
+
+		if stopProbes != nil {
+			stopProbes()
+		}
+
+		// 阶段边界: 用快照差值记录本阶段独立的统计块
+		curSnap := stats.Snapshot()
+		curSketch := mergedGlobalSketch()
+		recordStageResult(stage, time.Since(stageStart), prevSnap, curSnap, prevSketch, curSketch)
+		prevSnap = curSnap
+		prevSketch = curSketch
+	}
+
+	log.Printf("全部阶段执行完毕，正在回收 %d 个协程", pool.size())
+	pool.scaleTo(0)
+	clearStageOverrides()
+}
+
+// 到达率阶段的派发循环 (与runOpenModelLoad同构，但以阶段时长为界)；
+// ctx取消返回false
+func runStageArrival(ctx context.Context, stage LoadStage, p *workerPool) bool {
+	limit := stage.Workers
+	if limit <= 0 {
+		limit = NumConcurrentWorkers
+	}
+	sem := make(chan struct{}, limit)
+
+	ticker := time.NewTicker(arrivalTickInterval)
+	defer ticker.Stop()
+	deadline := time.Now().Add(stage.Duration)
+	tokens := 0.0
+
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+		if isPaused() {
+			tokens = 0
+			continue
+		}
+
+		tokens += float64(stage.Rate) * arrivalTickInterval.Seconds()
+		n := int(tokens)
+		tokens -= float64(n)
+
+		for k := 0; k < n; k++ {
+			select {
+			case sem <- struct{}{}:
+				p.wg.Add(1)
+				go func() {
+					defer p.wg.Done()
+					defer func() { <-sem }()
+
+					url, payload, headers, method := getFromCache(p.cache)
+					dispatchRequest(p.ctx, p.httpClient, p.http3Client, method, url, payload, headers, p.stats)
+				}()
+			default:
+				atomic.AddInt64(&p.stats.DroppedArrivals, 1)
+			}
+		}
+	}
+	return true
+}
+
+// 单阶段的独立统计块 (由相邻两次快照差值得出)
+type StageResult struct {
+	Name      string  `json:"name"`
+	Workers   int     `json:"workers"`
+	DurationS float64 `json:"duration_s"`
+	Requests  int64   `json:"requests"`
+	Failed    int64   `json:"failed"`
+	QPS       float64 `json:"qps"`
+	ErrorRate float64 `json:"error_rate"`
+	P50Ms     float64 `json:"p50_ms"`
+	P95Ms     float64 `json:"p95_ms"`
+	P99Ms     float64 `json:"p99_ms"`
+}
+
+var stageResults []StageResult
+
+// 由阶段前后的快照差值计算本阶段的QPS、错误率和延迟百分位
+func recordStageResult(stage LoadStage, elapsed time.Duration, prev, cur *StatsSnapshot, prevSketch, curSketch *latencySketch) {
+	result := StageResult{
+		Name:      stage.Name,
+		Workers:   stage.Workers,
+		DurationS: elapsed.Seconds(),
+		Requests:  cur.TotalRequests - prev.TotalRequests,
+		Failed:    cur.FailedRequests - prev.FailedRequests,
+	}
+	if elapsed.Seconds() > 0 {
+		result.QPS = float64(result.Requests) / elapsed.Seconds()
+	}
+	if result.Requests > 0 {
+		result.ErrorRate = float64(result.Failed) / float64(result.Requests) * 100
+	}
+
+	// 本阶段延迟取自全局草图在阶段边界的桶计数差值，
+	// 与全局/分协议/分目标报告共用同一条合并管道 (ResponseTimes切片有截断，差值不可靠)
+	if stageSketch := curSketch.diff(prevSketch); stageSketch.count() > 0 {
+		result.P50Ms = stageSketch.quantileMs(0.50)
+		result.P95Ms = stageSketch.quantileMs(0.95)
+		result.P99Ms = stageSketch.quantileMs(0.99)
+	}
+
+	stageResults = append(stageResults, result)
+}
+
+// 打印各阶段的独立统计与阶段间变化，暴露拐点而不是被总体均值掩盖
+func printStageReport() {
+	if len(stageResults) == 0 {
+		return
+	}
+
+	fmt.Printf("\n=== 分阶段统计 ===\n")
+	for i, r := range stageResults {
+		fmt.Printf("[%s] %d 协程 | %.0fs | QPS: %.1f | 错误率: %.2f%% | P50/P95/P99: %.1f/%.1f/%.1fms\n",
+			r.Name, r.Workers, r.DurationS, r.QPS, r.ErrorRate, r.P50Ms, r.P95Ms, r.P99Ms)
+
+		// 阶段间变化: QPS与P99的环比
+		if i > 0 {
+			prev := stageResults[i-1]
+			if prev.QPS > 0 && prev.P99Ms > 0 {
+				fmt.Printf("    较上阶段: QPS %+.1f%% | P99 %+.1f%%\n",
+					(r.QPS-prev.QPS)/prev.QPS*100, (r.P99Ms-prev.P99Ms)/prev.P99Ms*100)
+			}
+		}
+	}
+}
+
+// 阶段序列总时长
+func totalStageDuration() time.Duration {
+	var total time.Duration
+	for _, stage := range LoadStages {
+		total += stage.Duration
+	}
+	return total
+}
+
+// 校验阶段配置
+func validateStages() error {
+	for i, stage := range LoadStages {
+		if stage.Duration <= 0 {
+			return fmt.Errorf("阶段 %d (%s) 的时长必须大于0", i, stage.Name)
+		}
+		if stage.Workers < 0 {
+			return fmt.Errorf("阶段 %d (%s) 的协程数不能为负", i, stage.Name)
+		}
+		if stage.Rate < 0 {
+			return fmt.Errorf("阶段 %d (%s) 的到达率不能为负", i, stage.Name)
+		}
+		mixFields := 0
+		for _, p := range []*int{stage.HTTP3Pct, stage.WSPct, stage.GRPCPct} {
+			if p != nil {
+				mixFields++
+			}
+		}
+		if mixFields != 0 && mixFields != 3 {
+			return fmt.Errorf("阶段 %d (%s) 的协议占比需同时设置http3_pct/websocket_pct/grpc_pct", i, stage.Name)
+		}
+		if mixFields == 3 {
+			sum := *stage.HTTP3Pct + *stage.WSPct + *stage.GRPCPct
+			if *stage.HTTP3Pct < 0 || *stage.WSPct < 0 || *stage.GRPCPct < 0 || sum > 100 {
+				return fmt.Errorf("阶段 %d (%s) 的协议占比需为非负且合计不超过100", i, stage.Name)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ===================================================================================
+// --- 阶梯加压找容量拐点 (Step-Load Breaking Point) ---
+// 与自适应并发(AIMD)的在线调节不同，阶梯模式按固定步长逐级加压并在每级
+// 停留完整的观察窗口，越过错误率或P99阈值即停止，最后一个达标的台阶
+// 就是容量估计；阈值沿用自适应并发的 AutoTuneMaxErrorRate / AutoTuneMaxP99Ms
+// ===================================================================================
+
+var (
+	// 每级增加的协程数，>0 启用阶梯模式
+	StepLoadIncrement = 0
+	// 每级的观察时长
+	StepDuration = 60 * time.Second
+)
+
+// 单级台阶的测量结果
+type StepLoadResult struct {
+	Step        int     `json:"step"`
+	Workers     int     `json:"workers"`
+	QPS         float64 `json:"qps"`
+	ErrorRate   float64 `json:"error_rate"`
+	P99Ms       float64 `json:"p99_ms"`
+	Sustainable bool    `json:"sustainable"`
+}
+
+var stepLoadResults []StepLoadResult
+
+// 阶梯模式执行入口: 逐级加压直到越过阈值或到达截止时间
+func runStepLoad(ctx context.Context, cache *RequestCache, stats *Stats, httpClient, http3Client *http.Client, wg *sync.WaitGroup) {
+	pool := &workerPool{
+		ctx:         ctx,
+		cache:       cache,
+		stats:       stats,
+		httpClient:  httpClient,
+		http3Client: http3Client,
+		wg:          wg,
+	}
+
+	log.Printf("阶梯加压: 每 %v 增加 %d 协程，阈值: 错误率 %.1f%% / P99 %.0fms",
+		StepDuration, StepLoadIncrement, AutoTuneMaxErrorRate, AutoTuneMaxP99Ms)
+
+	workers := StepLoadIncrement
+	for step := 1; ; step++ {
+		pool.scaleTo(workers)
+		log.Printf("台阶 #%d: %d 协程", step, workers)
+
+		prevSnap := stats.Snapshot()
+		prevSketch := mergedGlobalSketch()
+		stepStart := time.Now()
+
+		select {
+		case <-ctx.Done():
+			pool.scaleTo(0)
+			return
+		case <-time.After(StepDuration):
+		}
+
+		curSnap := stats.Snapshot()
+		elapsed := time.Since(stepStart)
+
+		result := StepLoadResult{
+			Step:    step,
+			Workers: workers,
+		}
+		requests := curSnap.TotalRequests - prevSnap.TotalRequests
+		failed := curSnap.FailedRequests - prevSnap.FailedRequests
+		attempts := requests + failed
+		if elapsed.Seconds() > 0 {
+			result.QPS = float64(requests) / elapsed.Seconds()
+		}
+		if attempts > 0 {
+			result.ErrorRate = float64(failed) / float64(attempts) * 100
+		}
+		if stepSketch := mergedGlobalSketch().diff(prevSketch); stepSketch.count() > 0 {
+			result.P99Ms = stepSketch.quantileMs(0.99)
+		}
+		result.Sustainable = result.ErrorRate <= AutoTuneMaxErrorRate && result.P99Ms <= AutoTuneMaxP99Ms
+
+		stepLoadResults = append(stepLoadResults, result)
+		if !result.Sustainable {
+			log.Printf("台阶 #%d 越过阈值 (错误率 %.2f%% / P99 %.0fms)，停止加压",
+				step, result.ErrorRate, result.P99Ms)
+			break
+		}
+		if time.Now().After(testDeadline) {
+			log.Printf("到达最长运行时间，停止加压")
+			break
+		}
+		workers += StepLoadIncrement
+	}
+
+	pool.scaleTo(0)
+}
+
+// 最后一个达标的台阶，即容量估计 (没有达标台阶时返回nil)
+func stepLoadCapacity() *StepLoadResult {
+	for i := len(stepLoadResults) - 1; i >= 0; i-- {
+		if stepLoadResults[i].Sustainable {
+			return &stepLoadResults[i]
+		}
+	}
+	return nil
+}
+
+// 打印各台阶结果和容量估计
+func printStepLoadReport() {
+	if len(stepLoadResults) == 0 {
+		return
+	}
+
+	fmt.Printf("\n=== 阶梯加压 (每级 +%d 协程 / %v) ===\n", StepLoadIncrement, StepDuration)
+	for _, r := range stepLoadResults {
+		status := "达标"
+		if !r.Sustainable {
+			status = "越过阈值"
+		}
+		fmt.Printf("#%d: %d 协程 | QPS: %.1f | 错误率: %.2f%% | P99: %.1fms | %s\n",
+			r.Step, r.Workers, r.QPS, r.ErrorRate, r.P99Ms, status)
+	}
+
+	if capacity := stepLoadCapacity(); capacity != nil {
+		fmt.Printf("容量估计: %d 协程可持续 (QPS %.1f，错误率 %.2f%%，P99 %.1fms)\n",
+			capacity.Workers, capacity.QPS, capacity.ErrorRate, capacity.P99Ms)
+	} else {
+		fmt.Printf("容量估计: 第一级台阶即越过阈值，请降低步长后重试\n")
+	}
+}
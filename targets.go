@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ===================================================================================
+// --- 扩展的dependency.txt行格式 (Extended Target Lines) ---
+// 每行除URL外可选指定方法、权重、协议、请求体文件和附加头，如:
+//   POST https://x/api weight=5 proto=h2 body=@payload.json header=X-Tenant:abc
+// 解析结果由缓存生成器按权重采样并原样使用，不再全部随机生成
+// ===================================================================================
+
+// 一行dependency.txt解析出的完整目标定义
+type TargetSpec struct {
+	URL     string
+	Method  string            // 为空时沿用全局的随机方法/GET逻辑
+	Headers map[string]string // 附加头，叠加在随机头之上
+	Body    []byte            // body=@file 的内容，nil时随机生成
+	Weight  int               // 加权采样权重，默认1
+	Proto   string            // h1/h2/h3/ws/grpc，为空不强制协议
+}
+
+var (
+	TargetSpecs []TargetSpec
+	// 按权重展开的下标表，等概率取下标即完成加权采样
+	weightedTargetIndex []int
+	// 显式指定协议的URL -> 协议，派发时跳过随机协议选择
+	forcedProtocols = make(map[string]ProtocolType)
+	// 保护上述目标数据和TargetURLs: 远程目标列表会在运行中整体替换
+	targetMu sync.RWMutex
+)
+
+// 权重展开表的规模上限，防止超大权重撑爆内存
+const maxWeightPerTarget = 1000
+
+// 解析一行目标定义；返回nil表示该行应跳过 (已打印警告)
+func parseTargetLine(line string, lineNum int) *TargetSpec {
+	fields := strings.Fields(line)
+	spec := &TargetSpec{Weight: 1}
+
+	// 首字段可以是HTTP方法，其后必须跟URL
+	idx := 0
+	if len(fields) > 1 && isHTTPMethod(fields[0]) {
+		spec.Method = fields[0]
+		idx = 1
+	}
+	if idx >= len(fields) {
+		logTargetWarn(lineNum, line, "缺少URL")
+		return nil
+	}
+
+	rawURL := fields[idx]
+	if !strings.HasPrefix(rawURL, "http://") && !strings.HasPrefix(rawURL, "https://") {
+		logTargetWarn(lineNum, line, "无效URL格式")
+		return nil
+	}
+	if _, err := url.ParseRequestURI(rawURL); err != nil {
+		logTargetWarn(lineNum, line, "无效URL")
+		return nil
+	}
+	spec.URL = rawURL
+
+	// 其余字段为 key=value 指令
+	for _, field := range fields[idx+1:] {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			logTargetWarn(lineNum, line, fmt.Sprintf("无法解析指令 %q", field))
+			return nil
+		}
+		switch key {
+		case "weight":
+			w, err := strconv.Atoi(value)
+			if err != nil || w < 1 {
+				logTargetWarn(lineNum, line, fmt.Sprintf("无效权重 %q", value))
+				return nil
+			}
+			if w > maxWeightPerTarget {
+				w = maxWeightPerTarget
+			}
+			spec.Weight = w
+		case "proto":
+			switch value {
+			case "h1", "h2", "h3", "ws", "grpc":
+				spec.Proto = value
+			default:
+				logTargetWarn(lineNum, line, fmt.Sprintf("无效协议 %q (支持 h1/h2/h3/ws/grpc)", value))
+				return nil
+			}
+		case "body":
+			if !strings.HasPrefix(value, "@") {
+				logTargetWarn(lineNum, line, "body需为 @文件路径 形式")
+				return nil
+			}
+			data, err := os.ReadFile(strings.TrimPrefix(value, "@"))
+			if err != nil {
+				logTargetWarn(lineNum, line, fmt.Sprintf("无法读取请求体文件: %v", err))
+				return nil
+			}
+			spec.Body = data
+		case "header":
+			name, headerValue, ok := strings.Cut(value, ":")
+			if !ok || name == "" {
+				logTargetWarn(lineNum, line, fmt.Sprintf("无效头部 %q (需为 名称:值)", value))
+				return nil
+			}
+			if spec.Headers == nil {
+				spec.Headers = make(map[string]string)
+			}
+			spec.Headers[name] = headerValue
+		default:
+			logTargetWarn(lineNum, line, fmt.Sprintf("未知指令 %q", key))
+			return nil
+		}
+	}
+	return spec
+}
+
+func isHTTPMethod(s string) bool {
+	switch s {
+	case "GET", "POST", "PUT", "DELETE", "PATCH", "HEAD", "OPTIONS":
+		return true
+	}
+	return false
+}
+
+func logTargetWarn(lineNum int, line, reason string) {
+	log.Printf("警告: 跳过目标行 %d (%s): %s", lineNum, reason, line)
+}
+
+// 该目标是否带有显式定义 (缓存生成时原样使用而非随机化)
+func (t *TargetSpec) explicit() bool {
+	return t.Method != "" || t.Body != nil || len(t.Headers) > 0 || t.Proto != ""
+}
+
+// 逐行解析一个目标列表 (本地文件或远程响应体共用)，返回有效的定义和URL
+func parseTargetList(r io.Reader) ([]TargetSpec, []string, error) {
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	var specs []TargetSpec
+	var urls []string
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// 每行支持扩展格式: [方法] URL [weight=N] [proto=...] [body=@文件] [header=名:值]
+		if spec := parseTargetLine(line, lineNum); spec != nil {
+			specs = append(specs, *spec)
+			urls = append(urls, spec.URL)
+		}
+	}
+	return specs, urls, scanner.Err()
+}
+
+// 注册解析完成的目标集: 构建权重表和强制协议表
+func registerTargetSpecs(specs []TargetSpec) {
+	weighted := make([]int, 0, len(specs))
+	forced := make(map[string]ProtocolType)
+	for i, spec := range specs {
+		for k := 0; k < spec.Weight; k++ {
+			weighted = append(weighted, i)
+		}
+		switch spec.Proto {
+		case "h3":
+			forced[spec.URL] = ProtocolHTTP3
+		case "ws":
+			forced[spec.URL] = ProtocolWebSocket
+		case "grpc":
+			forced[spec.URL] = ProtocolGRPC
+		}
+	}
+
+	targetMu.Lock()
+	TargetSpecs = specs
+	weightedTargetIndex = weighted
+	forcedProtocols = forced
+	targetMu.Unlock()
+}
+
+// 按权重采样一个目标定义
+func pickTargetSpec(intn func(int) int) *TargetSpec {
+	targetMu.RLock()
+	defer targetMu.RUnlock()
+	if len(weightedTargetIndex) == 0 {
+		return nil
+	}
+	return &TargetSpecs[weightedTargetIndex[intn(len(weightedTargetIndex))]]
+}
+
+// 该URL是否被目标行强制了协议
+func forcedProtocolFor(url string) (ProtocolType, bool) {
+	targetMu.RLock()
+	defer targetMu.RUnlock()
+	proto, ok := forcedProtocols[url]
+	return proto, ok
+}
+
+// 当前目标URL列表 (刷新时会整体替换，读取需在锁内取出切片)
+func snapshotTargetURLs() []string {
+	targetMu.RLock()
+	defer targetMu.RUnlock()
+	return TargetURLs
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ===================================================================================
+// --- 每秒时间序列导出 (Per-Second Time Series CSV) ---
+// 每秒记录一次 请求数/错误数/字节数/滚动延迟百分位，运行结束后导出CSV，
+// 方便把性能画成时间曲线而不是只看最终聚合值
+// ===================================================================================
+
+var EnableTimeSeriesCSV = true
+
+// 单秒采样行
+type timeSeriesRow struct {
+	elapsed  int
+	requests int64
+	errors   int64
+	bytes    int64
+	p50      float64
+	p95      float64
+	p99      float64
+}
+
+type timeSeriesRecorder struct {
+	mu   sync.Mutex
+	rows []timeSeriesRow
+}
+
+var timeSeries = &timeSeriesRecorder{}
+
+const maxTimeSeriesRows = 24 * 3600
+
+// 启动每秒采样协程，记录各计数器的秒级增量
+func startTimeSeriesRecorder(ctx context.Context, stats *Stats) {
+	if !EnableTimeSeriesCSV {
+		return
+	}
+
+	go func() {
+		start := time.Now()
+		lastTotal := atomic.LoadInt64(&stats.TotalRequests)
+		lastFailed := atomic.LoadInt64(&stats.FailedRequests)
+		lastBytes := atomic.LoadInt64(&stats.TotalResponseSize)
+
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			total := atomic.LoadInt64(&stats.TotalRequests)
+			failed := atomic.LoadInt64(&stats.FailedRequests)
+			bytes := atomic.LoadInt64(&stats.TotalResponseSize)
+			p50, p95, p99, _ := rollingLatency.Percentiles(1)
+
+			timeSeries.mu.Lock()
+			if len(timeSeries.rows) < maxTimeSeriesRows {
+				timeSeries.rows = append(timeSeries.rows, timeSeriesRow{
+					elapsed:  int(time.Since(start).Seconds()),
+					requests: total - lastTotal,
+					errors:   failed - lastFailed,
+					bytes:    bytes - lastBytes,
+					p50:      p50,
+					p95:      p95,
+					p99:      p99,
+				})
+			}
+			timeSeries.mu.Unlock()
+
+			lastTotal, lastFailed, lastBytes = total, failed, bytes
+		}
+	}()
+}
+
+// 当前已记录行的副本，供运行后自动分析使用
+func timeSeriesRowsCopy() []timeSeriesRow {
+	timeSeries.mu.Lock()
+	defer timeSeries.mu.Unlock()
+	return append([]timeSeriesRow(nil), timeSeries.rows...)
+}
+
+// 导出采样序列为CSV文件
+func saveTimeSeriesCSV() {
+	timeSeries.mu.Lock()
+	rows := append([]timeSeriesRow(nil), timeSeries.rows...)
+	timeSeries.mu.Unlock()
+
+	if len(rows) == 0 {
+		return
+	}
+
+	fileName := fmt.Sprintf("timeseries_%s.csv", time.Now().Format("20060102_150405"))
+	file, err := os.Create(fileName)
+	if err != nil {
+		log.Printf("无法创建时间序列文件: %v", err)
+		return
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	w.Write([]string{"elapsed_s", "requests_per_s", "errors_per_s", "bytes_per_s", "p50_ms", "p95_ms", "p99_ms"})
+	for _, r := range rows {
+		w.Write([]string{
+			fmt.Sprintf("%d", r.elapsed),
+			fmt.Sprintf("%d", r.requests),
+			fmt.Sprintf("%d", r.errors),
+			fmt.Sprintf("%d", r.bytes),
+			fmt.Sprintf("%.2f", r.p50),
+			fmt.Sprintf("%.2f", r.p95),
+			fmt.Sprintf("%.2f", r.p99),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Printf("无法写入时间序列文件: %v", err)
+		return
+	}
+
+	log.Printf("每秒时间序列已导出到: %s (%d 行)", fileName, len(rows))
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http/httptrace"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ===================================================================================
+// --- TLS握手延迟统计 (TLS Handshake Latency) ---
+// 通过httptrace单独记录TLS握手耗时，区分完整握手与会话复用握手，
+// 补充TLSConnections只有总数的不足
+// ===================================================================================
+
+var EnableTLSHandshakeStats = true
+
+// 握手样本收集器: 完整握手与复用握手分开存放
+type tlsHandshakeCollector struct {
+	mu      sync.Mutex
+	full    []time.Duration
+	resumed []time.Duration
+}
+
+var tlsHandshakes = &tlsHandshakeCollector{}
+
+const maxTLSHandshakeSamples = 50000
+
+func (c *tlsHandshakeCollector) record(d time.Duration, resumed bool) {
+	c.mu.Lock()
+	if resumed {
+		if len(c.resumed) < maxTLSHandshakeSamples {
+			c.resumed = append(c.resumed, d)
+		}
+	} else {
+		if len(c.full) < maxTLSHandshakeSamples {
+			c.full = append(c.full, d)
+		}
+	}
+	c.mu.Unlock()
+}
+
+// 为一次请求构造带TLS握手计时的context
+func withTLSHandshakeTrace(ctx context.Context) context.Context {
+	if !EnableTLSHandshakeStats {
+		return ctx
+	}
+
+	var handshakeStart time.Time
+	trace := &httptrace.ClientTrace{
+		TLSHandshakeStart: func() {
+			handshakeStart = time.Now()
+		},
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			if err == nil && !handshakeStart.IsZero() {
+				tlsHandshakes.record(time.Since(handshakeStart), state.DidResume)
+			}
+		},
+	}
+	return httptrace.WithClientTrace(ctx, trace)
+}
+
+// 计算一组时长样本的 平均/P50/P95/P99 (毫秒)
+func durationPercentiles(samples []time.Duration) (avg, p50, p95, p99 float64) {
+	if len(samples) == 0 {
+		return
+	}
+	sorted := make([]time.Duration, len(samples))
+	copy(sorted, samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, s := range sorted {
+		total += s
+	}
+	toMs := func(d time.Duration) float64 { return float64(d.Nanoseconds()) / 1e6 }
+	avg = toMs(total / time.Duration(len(sorted)))
+	p50 = toMs(sorted[len(sorted)/2])
+	p95 = toMs(sorted[int(float64(len(sorted))*0.95)])
+	p99 = toMs(sorted[int(float64(len(sorted))*0.99)])
+	return
+}
+
+// 打印TLS握手延迟分析
+func printTLSHandshakeStats() {
+	tlsHandshakes.mu.Lock()
+	full := append([]time.Duration(nil), tlsHandshakes.full...)
+	resumed := append([]time.Duration(nil), tlsHandshakes.resumed...)
+	tlsHandshakes.mu.Unlock()
+
+	if len(full) == 0 && len(resumed) == 0 {
+		return
+	}
+
+	fmt.Printf("\n=== TLS握手延迟 ===\n")
+	if len(full) > 0 {
+		avg, p50, p95, p99 := durationPercentiles(full)
+		fmt.Printf("完整握手: %d 次 | 平均: %.1fms | P50/P95/P99: %.1f/%.1f/%.1fms\n",
+			len(full), avg, p50, p95, p99)
+	}
+	if len(resumed) > 0 {
+		avg, p50, p95, p99 := durationPercentiles(resumed)
+		fmt.Printf("复用握手: %d 次 | 平均: %.1fms | P50/P95/P99: %.1f/%.1f/%.1fms\n",
+			len(resumed), avg, p50, p95, p99)
+	}
+	if len(full) > 0 && len(resumed) > 0 {
+		fullAvg, _, _, _ := durationPercentiles(full)
+		resumedAvg, _, _, _ := durationPercentiles(resumed)
+		fmt.Printf("复用率: %.1f%% | 复用比完整快: %.1fms\n",
+			float64(len(resumed))/float64(len(full)+len(resumed))*100, fullAvg-resumedAvg)
+	}
+}
+
+// TLS握手分析的JSON报告片段
+func tlsHandshakeAnalysis() map[string]interface{} {
+	tlsHandshakes.mu.Lock()
+	full := append([]time.Duration(nil), tlsHandshakes.full...)
+	resumed := append([]time.Duration(nil), tlsHandshakes.resumed...)
+	tlsHandshakes.mu.Unlock()
+
+	analysis := make(map[string]interface{})
+	if len(full) > 0 {
+		avg, p50, p95, p99 := durationPercentiles(full)
+		analysis["full"] = map[string]interface{}{
+			"count": len(full), "avg_ms": avg, "p50_ms": p50, "p95_ms": p95, "p99_ms": p99,
+		}
+	}
+	if len(resumed) > 0 {
+		avg, p50, p95, p99 := durationPercentiles(resumed)
+		analysis["resumed"] = map[string]interface{}{
+			"count": len(resumed), "avg_ms": avg, "p50_ms": p50, "p95_ms": p95, "p99_ms": p99,
+		}
+	}
+	return analysis
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ===================================================================================
+// --- 终端TUI仪表盘 (Terminal UI Dashboard) ---
+// 可选的全屏终端视图: RPS/延迟走势图、滚动错误列表、各协议计数器，
+// 按StatsUpdateInterval刷新；stdout不是终端时自动退回普通进度条
+// ===================================================================================
+
+var EnableTUI = false
+
+// 走势图保留的历史点数
+const tuiHistoryLen = 60
+
+var sparkRunes = []rune("▁▂▃▄▅▆▇█")
+
+// stdout是否连接到终端
+func stdoutIsTTY() bool {
+	fi, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// 把数值序列渲染成单行走势图
+func sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	maxV := 0.0
+	for _, v := range values {
+		if v > maxV {
+			maxV = v
+		}
+	}
+	var sb strings.Builder
+	for _, v := range values {
+		idx := 0
+		if maxV > 0 {
+			idx = int(v / maxV * float64(len(sparkRunes)-1))
+		}
+		sb.WriteRune(sparkRunes[idx])
+	}
+	return sb.String()
+}
+
+// TUI主循环: 替代progressMonitor，收到done信号后退出
+func runTUI(stats *Stats, done chan bool) {
+	ticker := time.NewTicker(StatsUpdateInterval)
+	defer ticker.Stop()
+
+	var rpsHistory, p99History []float64
+	lastRequests := int64(0)
+	lastTime := time.Now()
+
+	for {
+		select {
+		case <-ticker.C:
+			current := atomic.LoadInt64(&stats.TotalRequests)
+			responses := atomic.LoadInt64(&stats.TotalResponses)
+			failed := atomic.LoadInt64(&stats.FailedRequests)
+
+			now := time.Now()
+			rps := float64(current-lastRequests) / now.Sub(lastTime).Seconds()
+			lastRequests, lastTime = current, now
+
+			p50, p95, p99, _ := rollingLatency.Percentiles(RollingWindowSec)
+			rpsHistory = appendCapped(rpsHistory, rps)
+			p99History = appendCapped(p99History, p99)
+
+			successRate := float64(responses) / math.Max(float64(current), 1) * 100
+
+			// 全屏重绘
+			fmt.Print("\033[H\033[2J")
+			fmt.Printf("═══ 负载测试 TUI ═══  已运行: %v\n\n", time.Since(stats.StartTime).Round(time.Second))
+			fmt.Printf("RPS  %8.1f  %s\n", rps, sparkline(rpsHistory))
+			fmt.Printf("P99  %6.0fms  %s\n", p99, sparkline(p99History))
+			fmt.Printf("\n请求: %d | 成功率: %.2f%% | 失败: %d | P50/P95: %.0f/%.0fms\n",
+				current, successRate, failed, p50, p95)
+
+			fmt.Printf("\n协议分布:  HTTP %d | WS %d | gRPC %d | HTTP3 %d\n",
+				current-atomic.LoadInt64(&stats.WSRequests)-atomic.LoadInt64(&stats.GRPCRequests)-atomic.LoadInt64(&stats.HTTP3Requests),
+				atomic.LoadInt64(&stats.WSRequests),
+				atomic.LoadInt64(&stats.GRPCRequests),
+				atomic.LoadInt64(&stats.HTTP3Requests))
+
+			printTUIErrors(stats)
+		case <-done:
+			fmt.Print("\033[H\033[2J")
+			return
+		}
+	}
+}
+
+func appendCapped(history []float64, v float64) []float64 {
+	history = append(history, v)
+	if len(history) > tuiHistoryLen {
+		history = history[1:]
+	}
+	return history
+}
+
+// 错误类型Top5
+func printTUIErrors(stats *Stats) {
+	type errCount struct {
+		name  string
+		count int64
+	}
+	var errs []errCount
+	stats.mu.RLock()
+	for k, v := range stats.ErrorTypes {
+		errs = append(errs, errCount{k, v})
+	}
+	stats.mu.RUnlock()
+
+	if len(errs) == 0 {
+		return
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].count > errs[j].count })
+	if len(errs) > 5 {
+		errs = errs[:5]
+	}
+
+	fmt.Printf("\n错误Top5:\n")
+	for _, e := range errs {
+		fmt.Printf("  %-40s %d\n", e.name, e.count)
+	}
+}
@@ -0,0 +1,65 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// ===================================================================================
+// --- 文件描述符上限自动调整 (NOFILE ulimit) ---
+// 高并发下每个协程至少占用一个套接字，软限制不足时先尝试提升到硬限制，
+// 避免测试中途才因EMFILE静默失败。Windows没有NOFILE限制，见ulimit_windows.go
+// ===================================================================================
+
+// 根据并发配置估算需要的FD数: 每协程一个连接 + 日志/报告/缓存等固定开销
+func requiredFDs() uint64 {
+	return uint64(NumConcurrentWorkers) + 256
+}
+
+// 启动时检查并尝试提升NOFILE软限制，无论成败都明确报告最终生效值
+func raiseFDLimit() {
+	var limit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &limit); err != nil {
+		fmt.Printf("警告: 无法读取FD限制: %v\n", err)
+		return
+	}
+
+	need := requiredFDs()
+	if limit.Cur >= need {
+		fmt.Printf("FD限制: 软限制 %d / 硬限制 %d (满足需求 %d)\n", limit.Cur, limit.Max, need)
+		return
+	}
+
+	// 软限制不足: 提升到硬限制允许的最大值
+	target := limit.Max
+	if need < target {
+		target = need
+	}
+	old := limit.Cur
+	limit.Cur = target
+	if err := syscall.Setrlimit(syscall.RLIMIT_NOFILE, &limit); err != nil {
+		fmt.Printf("警告: 提升FD软限制失败 (%d -> %d): %v\n", old, target, err)
+	} else {
+		fmt.Printf("FD软限制已提升: %d -> %d (硬限制 %d)\n", old, target, limit.Max)
+	}
+
+	// 重新读取确认最终生效值
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &limit); err == nil && limit.Cur < need {
+		fmt.Printf("警告: 最终FD软限制 %d 仍低于需求 %d，高并发下可能出现EMFILE，建议降低并发或调高硬限制\n",
+			limit.Cur, need)
+	}
+}
+
+// 当前NOFILE软/硬限制，供环境清单记录；读取失败返回nil
+func fdLimitInfo() map[string]interface{} {
+	var limit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &limit); err != nil {
+		return nil
+	}
+	return map[string]interface{}{
+		"soft": limit.Cur,
+		"hard": limit.Max,
+	}
+}
@@ -0,0 +1,12 @@
+//go:build windows
+
+package main
+
+// Windows没有NOFILE rlimit概念，句柄上限由系统管理，无需调整
+
+func raiseFDLimit() {}
+
+// 环境清单中不记录该项
+func fdLimitInfo() map[string]interface{} {
+	return nil
+}
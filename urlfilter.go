@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ===================================================================================
+// --- URL过滤与robots.txt遵从 (URL Filters / robots.txt) ---
+// 随机路径生成和目标发现都可能拼出危险端点 (如 /admin/delete)；
+// 支持允许/拒绝正则过滤，以及可选的robots.txt遵从，把这类流量拦在发出之前
+// ===================================================================================
+
+var (
+	// 允许列表非空时，URL必须匹配其中之一才会被访问
+	URLAllowPatterns []*regexp.Regexp
+	// 匹配任一拒绝规则的URL直接拦截
+	URLDenyPatterns []*regexp.Regexp
+	// 启用后按各主机robots.txt的Disallow规则过滤
+	EnableRobotsCompliance = false
+)
+
+// 每个主机的robots.txt解析结果缓存
+type robotsCache struct {
+	mu    sync.Mutex
+	rules map[string][]string // scheme://host -> Disallow路径前缀列表
+}
+
+var robots = &robotsCache{rules: make(map[string][]string)}
+
+var robotsFetchClient = &http.Client{Timeout: 10 * time.Second}
+
+// URL是否允许访问; 拦截时返回false和原因
+func urlAllowed(rawURL string) (bool, string) {
+	if len(URLDenyPatterns) > 0 {
+		for _, re := range URLDenyPatterns {
+			if re.MatchString(rawURL) {
+				return false, fmt.Sprintf("匹配拒绝规则 %q", re.String())
+			}
+		}
+	}
+
+	if len(URLAllowPatterns) > 0 {
+		matched := false
+		for _, re := range URLAllowPatterns {
+			if re.MatchString(rawURL) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false, "不匹配任何允许规则"
+		}
+	}
+
+	if EnableRobotsCompliance {
+		parsed, err := url.Parse(rawURL)
+		if err == nil {
+			for _, prefix := range robots.disallowsFor(parsed.Scheme + "://" + parsed.Host) {
+				if strings.HasPrefix(parsed.Path, prefix) {
+					return false, fmt.Sprintf("robots.txt禁止路径 %q", prefix)
+				}
+			}
+		}
+	}
+
+	return true, ""
+}
+
+// 获取主机的Disallow规则，首次访问时抓取并解析robots.txt
+func (c *robotsCache) disallowsFor(origin string) []string {
+	c.mu.Lock()
+	rules, ok := c.rules[origin]
+	if ok {
+		c.mu.Unlock()
+		return rules
+	}
+	// 先占位，避免并发协程重复抓取
+	c.rules[origin] = nil
+	c.mu.Unlock()
+
+	rules = fetchRobotsDisallows(origin)
+
+	c.mu.Lock()
+	c.rules[origin] = rules
+	c.mu.Unlock()
+	return rules
+}
+
+// 抓取并解析robots.txt中User-agent: *组的Disallow前缀
+// 抓取失败时视为无限制 (robots.txt不存在是常态)
+func fetchRobotsDisallows(origin string) []string {
+	resp, err := robotsFetchClient.Get(origin + "/robots.txt")
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil
+	}
+
+	var disallows []string
+	inStarGroup := false
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			inStarGroup = value == "*"
+		case "disallow":
+			if inStarGroup && value != "" {
+				disallows = append(disallows, value)
+			}
+		}
+	}
+
+	if len(disallows) > 0 {
+		fmt.Printf("robots.txt: %s 禁止 %d 个路径前缀\n", origin, len(disallows))
+	}
+	return disallows
+}
+
+// 编译配置中的正则过滤规则
+func compileURLFilters(allow, deny []string) error {
+	for _, p := range allow {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return fmt.Errorf("允许规则 %q 格式错误: %v", p, err)
+		}
+		URLAllowPatterns = append(URLAllowPatterns, re)
+	}
+	for _, p := range deny {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return fmt.Errorf("拒绝规则 %q 格式错误: %v", p, err)
+		}
+		URLDenyPatterns = append(URLDenyPatterns, re)
+	}
+	return nil
+}
+
+// 过滤是否处于启用状态
+func urlFilteringActive() bool {
+	return len(URLAllowPatterns) > 0 || len(URLDenyPatterns) > 0 || EnableRobotsCompliance
+}
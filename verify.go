@@ -0,0 +1,54 @@
+package main
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"hash"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// ===================================================================================
+// --- 下载内容校验 (Content Verification) ---
+// 针对静态文件目标，校验实际读取字节数与Content-Length一致，检测被截断的传输
+// ===================================================================================
+
+var EnableContentVerification = false
+
+// 根据响应头决定是否需要边读边算MD5
+func newContentHasher(resp *http.Response) hash.Hash {
+	if !EnableContentVerification {
+		return nil
+	}
+	if resp.Header.Get("Content-MD5") == "" {
+		return nil
+	}
+	return md5.New()
+}
+
+// 校验响应完整性: 字节数对比Content-Length，摘要对比Content-MD5
+// 注意: 响应被透明解压时Content-Length对应的是压缩后大小，此时跳过长度校验
+func verifyContent(resp *http.Response, bytesRead int64, hasher hash.Hash, stats *Stats) {
+	if !EnableContentVerification {
+		return
+	}
+
+	if resp.ContentLength >= 0 && !resp.Uncompressed && bytesRead != resp.ContentLength {
+		atomic.AddInt64(&stats.TruncatedTransfers, 1)
+		recordError(stats, "传输被截断")
+		return
+	}
+
+	if hasher != nil {
+		sum := hasher.Sum(nil)
+		expected := strings.TrimSpace(resp.Header.Get("Content-MD5"))
+		// Content-MD5 标准为base64，部分服务器返回hex
+		if expected != base64.StdEncoding.EncodeToString(sum) &&
+			!strings.EqualFold(expected, hex.EncodeToString(sum)) {
+			atomic.AddInt64(&stats.ChecksumMismatches, 1)
+			recordError(stats, "内容摘要不匹配")
+		}
+	}
+}
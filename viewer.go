@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+)
+
+// ===================================================================================
+// --- 报告查看器子命令 (Embedded Results Viewer) ---
+// `view report.json` 在本地起一个HTTP服务渲染任意一份已保存的详细报告
+// (得分拆解、性能指标、错误分布、分阶段表格)，历史JSON不依赖外部工具也能看
+// ===================================================================================
+
+// 查看器默认监听地址
+const viewerDefaultAddr = ":8090"
+
+// 查看器入口: 校验报告文件可解析后启动HTTP服务
+func runReportViewer(path string, addr string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("无法读取报告文件: %v", err)
+	}
+
+	var report map[string]interface{}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return fmt.Errorf("报告文件不是有效的JSON: %v", err)
+	}
+
+	if addr == "" {
+		addr = viewerDefaultAddr
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(viewerHTML))
+	})
+	mux.HandleFunc("/data", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write(data)
+	})
+
+	log.Printf("报告查看器: http://%s/ (报告: %s)", displayAddr(addr), path)
+	return http.ListenAndServe(addr, mux)
+}
+
+const viewerHTML = `<!DOCTYPE html>
+<html lang="zh">
+<head>
+<meta charset="utf-8">
+<title>负载测试报告查看器</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", sans-serif; background: #1e1e2e; color: #cdd6f4; margin: 2em; }
+h1 { font-size: 1.3em; }
+h2 { font-size: 1.05em; margin-top: 1.8em; color: #89b4fa; }
+.cards { display: flex; gap: 1em; flex-wrap: wrap; }
+.card { background: #313244; border-radius: 8px; padding: 1em 1.5em; min-width: 10em; }
+.card .label { font-size: .8em; color: #a6adc8; }
+.card .value { font-size: 1.6em; margin-top: .2em; }
+table { border-collapse: collapse; margin-top: .5em; }
+td, th { padding: .3em 1em; border-bottom: 1px solid #45475a; text-align: left; font-size: .9em; }
+.bar-row { display: flex; align-items: center; gap: .6em; margin: .25em 0; }
+.bar-label { width: 9em; font-size: .85em; color: #a6adc8; }
+.bar-track { background: #313244; border-radius: 4px; width: 22em; height: 1em; }
+.bar-fill { background: #a6e3a1; border-radius: 4px; height: 100%; }
+.bar-value { font-size: .85em; }
+details { margin-top: 2em; }
+pre { background: #181825; padding: 1em; border-radius: 8px; overflow: auto; font-size: .8em; }
+</style>
+</head>
+<body>
+<h1>负载测试报告查看器</h1>
+<div id="content">加载中...</div>
+<script>
+function fmt(v) {
+  if (typeof v === "number") return Number.isInteger(v) ? v : v.toFixed(2);
+  return v;
+}
+function card(label, value) {
+  return '<div class="card"><div class="label">' + label + '</div><div class="value">' + value + '</div></div>';
+}
+function table(obj) {
+  const rows = Object.entries(obj)
+    .filter(([, v]) => typeof v !== "object" || v === null)
+    .map(([k, v]) => "<tr><td>" + k + "</td><td>" + fmt(v) + "</td></tr>").join("");
+  return rows ? "<table>" + rows + "</table>" : "";
+}
+function scoreBar(label, value, max) {
+  const pct = max > 0 ? Math.min(value / max * 100, 100) : 0;
+  return '<div class="bar-row"><div class="bar-label">' + label + '</div>' +
+    '<div class="bar-track"><div class="bar-fill" style="width:' + pct + '%"></div></div>' +
+    '<div class="bar-value">' + fmt(value) + ' / ' + max + '</div></div>';
+}
+fetch("/data").then(r => r.json()).then(rep => {
+  let html = "";
+  const perf = rep.performance_results || {};
+  html += '<div class="cards">'
+    + card("总请求", perf.total_requests ?? "-")
+    + card("QPS", fmt(perf.requests_per_second ?? "-"))
+    + card("成功率", fmt(perf.success_rate_percent ?? "-") + "%")
+    + card("错误率", fmt(perf.error_rate_percent ?? "-") + "%")
+    + "</div>";
+
+  const score = rep.score_card;
+  if (score) {
+    html += "<h2>得分拆解 (总分 " + fmt(score.total_score) + " / 100，等级 " + (score.grade || "-") + ")</h2>";
+    html += scoreBar("QPS", score.qps_score, 20)
+      + scoreBar("成功率", score.success_rate_score, 10)
+      + scoreBar("响应时间", score.response_time_score, 10)
+      + scoreBar("错误处理", score.error_rate_score, 15)
+      + scoreBar("超时控制", score.timeout_score, 15)
+      + scoreBar("协议支持", score.protocol_score, 20)
+      + scoreBar("资源效率", score.resource_score, 10);
+  }
+
+  const sections = [
+    ["test_info", "测试信息"],
+    ["response_time_analysis", "响应时间"],
+    ["protocol_stats", "协议统计"],
+    ["error_analysis", "错误分布"],
+    ["latency_sketches", "延迟草图"],
+    ["phase_timing_analysis", "阶段耗时"],
+    ["memory_analysis", "内存"],
+    ["connection_analysis", "连接复用"],
+  ];
+  for (const [key, title] of sections) {
+    const sec = rep[key];
+    if (!sec || typeof sec !== "object") continue;
+    const t = table(sec);
+    if (t) html += "<h2>" + title + "</h2>" + t;
+  }
+
+  const stages = rep.stage_results;
+  if (Array.isArray(stages) && stages.length) {
+    html += "<h2>分阶段统计</h2><table><tr><th>阶段</th><th>协程</th><th>QPS</th><th>错误率</th><th>P50</th><th>P95</th><th>P99</th></tr>";
+    for (const s of stages) {
+      html += "<tr><td>" + s.name + "</td><td>" + s.workers + "</td><td>" + fmt(s.qps) +
+        "</td><td>" + fmt(s.error_rate) + "%</td><td>" + fmt(s.p50_ms) + "</td><td>" +
+        fmt(s.p95_ms) + "</td><td>" + fmt(s.p99_ms) + "</td></tr>";
+    }
+    html += "</table>";
+  }
+
+  const spikes = rep.spike_results;
+  if (Array.isArray(spikes) && spikes.length) {
+    html += "<h2>尖峰恢复</h2><table><tr><th>#</th><th>基线P95</th><th>峰值P95</th><th>峰值错误率</th><th>恢复耗时</th></tr>";
+    for (const s of spikes) {
+      html += "<tr><td>" + s.index + "</td><td>" + fmt(s.baseline_p95_ms) + "ms</td><td>" +
+        fmt(s.peak_p95_ms) + "ms</td><td>" + fmt(s.peak_error_rate) + "%</td><td>" +
+        (s.recovered ? fmt(s.recovery_s) + "s" : "未恢复") + "</td></tr>";
+    }
+    html += "</table>";
+  }
+
+  const findings = rep.analysis_findings;
+  if (Array.isArray(findings) && findings.length) {
+    html += "<h2>自动分析发现</h2><table>";
+    for (const f of findings) {
+      html += "<tr><td>[" + f.severity + "]</td><td>" + f.title + "</td><td>" + f.detail + "</td></tr>";
+    }
+    html += "</table>";
+  }
+
+  html += "<details><summary>完整JSON</summary><pre>" +
+    JSON.stringify(rep, null, 2).replace(/</g, "&lt;") + "</pre></details>";
+  document.getElementById("content").innerHTML = html;
+}).catch(err => {
+  document.getElementById("content").textContent = "加载报告失败: " + err;
+});
+</script>
+</body>
+</html>
+`
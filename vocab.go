@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// ===================================================================================
+// --- 自定义URL词汇表 (Custom URL Vocabularies) ---
+// 允许从文件加载路径池和查询参数字典，使随机URL匹配目标应用的实际路由
+// ===================================================================================
+
+var (
+	// 路径词汇文件: 每行一个路径，#开头为注释，为空则使用内置路径池
+	PathVocabularyFile = ""
+	// 参数词汇文件: JSON格式 {"参数名": ["候选值", ...]}，为空则使用内置参数池
+	ParamVocabularyFile = ""
+
+	customPaths     []string
+	customParams    map[string][]string
+	customParamKeys []string
+)
+
+// 从配置文件加载自定义路径和参数词汇表
+func loadVocabularies() error {
+	if PathVocabularyFile != "" {
+		paths, err := loadPathVocabulary(PathVocabularyFile)
+		if err != nil {
+			return err
+		}
+		customPaths = paths
+		log.Printf("成功加载 %d 个自定义路径", len(customPaths))
+	}
+
+	if ParamVocabularyFile != "" {
+		params, err := loadParamVocabulary(ParamVocabularyFile)
+		if err != nil {
+			return err
+		}
+		customParams = params
+		customParamKeys = customParamKeys[:0]
+		for k := range customParams {
+			customParamKeys = append(customParamKeys, k)
+		}
+		log.Printf("成功加载 %d 个自定义参数", len(customParams))
+	}
+
+	return nil
+}
+
+func loadPathVocabulary(fileName string) ([]string, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("无法打开路径词汇文件: %v", err)
+	}
+	defer file.Close()
+
+	var paths []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		paths = append(paths, strings.TrimPrefix(line, "/"))
+	}
+
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("路径词汇文件 %s 中没有有效路径", fileName)
+	}
+	return paths, scanner.Err()
+}
+
+func loadParamVocabulary(fileName string) (map[string][]string, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("无法打开参数词汇文件: %v", err)
+	}
+
+	params := make(map[string][]string)
+	if err := json.Unmarshal(data, &params); err != nil {
+		return nil, fmt.Errorf("参数词汇文件格式错误: %v", err)
+	}
+
+	for key, values := range params {
+		if len(values) == 0 {
+			return nil, fmt.Errorf("参数 %s 没有候选值", key)
+		}
+	}
+
+	if len(params) == 0 {
+		return nil, fmt.Errorf("参数词汇文件 %s 中没有有效参数", fileName)
+	}
+	return params, nil
+}
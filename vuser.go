@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// ===================================================================================
+// --- 虚拟用户会话 (Per-VU Sessions) ---
+// GlobalCookies是全局共享的单一会话，压会话型站点时服务端只看到一个巨型用户。
+// 启用后每个工作协程持有独立的虚拟用户: 专属cookiejar和固定的User-Agent，
+// 服务端看到N个互不串号的用户会话
+// ===================================================================================
+
+// 启用每协程独立会话 (覆盖GlobalCookies的共享Cookie行为)
+var EnableUserSessions bool
+
+// 一个虚拟用户: 独立cookiejar + 整个生命周期固定的头部状态
+type VirtualUser struct {
+	id  int
+	jar *cookiejar.Jar
+
+	// 会话期间不变的粘性头部 (真实用户的UA不会每个请求都变)
+	userAgent string
+
+	// 登录流程提取的令牌，非空时自动附加到后续请求
+	token string
+
+	requests      int64
+	cookieUpdates int64
+}
+
+func newVirtualUser(id int) *VirtualUser {
+	jar, _ := cookiejar.New(nil)
+	return &VirtualUser{
+		id:        id,
+		jar:       jar,
+		userAgent: generateRandomUserAgent(),
+	}
+}
+
+// 发送前套用本用户的会话状态: 固定UA + 专属令牌 + 本用户jar中匹配该URL的Cookie
+func (vu *VirtualUser) applyTo(req *http.Request) {
+	atomic.AddInt64(&vu.requests, 1)
+	req.Header.Set("User-Agent", vu.userAgent)
+	if vu.token != "" {
+		req.Header.Set(LoginTokenHeader, LoginTokenPrefix+vu.token)
+	}
+
+	cookies := vu.jar.Cookies(req.URL)
+	if len(cookies) == 0 {
+		req.Header.Del("Cookie")
+		return
+	}
+	var parts []string
+	for _, c := range cookies {
+		parts = append(parts, fmt.Sprintf("%s=%s", c.Name, c.Value))
+	}
+	req.Header.Set("Cookie", strings.Join(parts, "; "))
+}
+
+// 收到响应后把Set-Cookie存回本用户的jar
+func (vu *VirtualUser) capture(resp *http.Response) {
+	cookies := resp.Cookies()
+	if len(cookies) == 0 {
+		return
+	}
+	vu.jar.SetCookies(resp.Request.URL, cookies)
+	atomic.AddInt64(&vu.cookieUpdates, 1)
+}
+
+// --- 每用户一次性登录流程 ---
+// 压测会话型API时每个虚拟用户先走一次登录端点换取自己的令牌，
+// 主循环里所有请求自动携带；与共享凭证管理(auth.go)互斥使用
+
+var (
+	// 登录端点，为空不启用登录流程
+	LoginURL    = ""
+	LoginMethod = "POST"
+	// 登录请求体模板，${user}/${pass}替换为本用户的凭证
+	LoginBody = `{"username":"${user}","password":"${pass}"}`
+	// 凭证文件: 每行 user:pass，各虚拟用户按编号轮转取用
+	LoginCredentialsFile = ""
+	// 从登录响应提取令牌的JSON点分路径
+	LoginTokenPath = "token"
+	// 令牌附加方式
+	LoginTokenHeader = "Authorization"
+	LoginTokenPrefix = "Bearer "
+)
+
+// 从凭证文件加载的 user/pass 对
+var loginCredentials [][2]string
+
+var loginAttempts int64
+var loginFailures int64
+
+func loginConfigured() bool {
+	return LoginURL != ""
+}
+
+// 加载凭证文件 (未配置文件时所有用户用空凭证，模板可写死测试账号)
+func loadLoginCredentials() error {
+	if LoginCredentialsFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(LoginCredentialsFile)
+	if err != nil {
+		return fmt.Errorf("无法读取凭证文件 %s: %v", LoginCredentialsFile, err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		user, pass, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		loginCredentials = append(loginCredentials, [2]string{user, pass})
+	}
+	if len(loginCredentials) == 0 {
+		return fmt.Errorf("凭证文件 %s 中没有有效的 user:pass 行", LoginCredentialsFile)
+	}
+	fmt.Printf("已加载 %d 组登录凭证\n", len(loginCredentials))
+	return nil
+}
+
+// 进入主循环前执行一次登录并提取令牌；失败时记录并继续 (用户以无令牌状态压测)
+func (vu *VirtualUser) login(httpClient *http.Client) {
+	if !loginConfigured() {
+		return
+	}
+	atomic.AddInt64(&loginAttempts, 1)
+
+	vars := map[string]string{}
+	if len(loginCredentials) > 0 {
+		cred := loginCredentials[vu.id%len(loginCredentials)]
+		vars["user"], vars["pass"] = cred[0], cred[1]
+	}
+
+	req, err := http.NewRequest(LoginMethod, LoginURL, strings.NewReader(expandScenarioVars(LoginBody, vars)))
+	if err != nil {
+		atomic.AddInt64(&loginFailures, 1)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", vu.userAgent)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		atomic.AddInt64(&loginFailures, 1)
+		return
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		atomic.AddInt64(&loginFailures, 1)
+		return
+	}
+
+	// 登录种下的会话Cookie也进本用户的jar
+	vu.jar.SetCookies(req.URL, resp.Cookies())
+
+	token := jsonPathString(body, LoginTokenPath)
+	if token == "" {
+		atomic.AddInt64(&loginFailures, 1)
+		return
+	}
+	vu.token = token
+}
+
+// 打印登录流程统计
+func printLoginReport() {
+	attempts := atomic.LoadInt64(&loginAttempts)
+	if attempts == 0 {
+		return
+	}
+
+	fmt.Printf("\n=== 虚拟用户登录 ===\n")
+	fmt.Printf("登录: %d 次 | 失败: %d 次\n", attempts, atomic.LoadInt64(&loginFailures))
+}
+
+// 虚拟用户经context随请求下行，避免改动整条派发链的签名
+type vuCtxKey struct{}
+
+func withVirtualUser(ctx context.Context, vu *VirtualUser) context.Context {
+	return context.WithValue(ctx, vuCtxKey{}, vu)
+}
+
+func virtualUserFrom(ctx context.Context) *VirtualUser {
+	vu, _ := ctx.Value(vuCtxKey{}).(*VirtualUser)
+	return vu
+}